@@ -0,0 +1,91 @@
+// Command analytics-rollup rebuilds the daily attendance rollups from presensi, after a restore,
+// a bulk import or after API instances ran with ANALYTICS_ROLLUPS=false.
+//
+// Usage:
+//
+//	analytics-rollup [-from YYYY-MM-DD] [-to YYYY-MM-DD]
+//
+// Dates are calendar days in ANALYTICS_TIMEZONE, -to is inclusive. Without them every day is rebuilt
+// and the rollups are marked current, so the next API start reads them without rebuilding.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/mongodb"
+	"github.com/okinn/service-presensi/internal/infrastructure"
+)
+
+func main() {
+	from := flag.String("from", "", "first day to rebuild, YYYY-MM-DD")
+	to := flag.String("to", "", "last day to rebuild, YYYY-MM-DD")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() > 0 {
+		usage()
+	}
+
+	_ = godotenv.Load()
+	cfg := infrastructure.LoadConfig()
+
+	location, err := time.LoadLocation(cfg.AnalyticsTimezone)
+	if err != nil {
+		fail(fmt.Errorf("invalid ANALYTICS_TIMEZONE: %w", err))
+	}
+
+	var start, end time.Time
+	if *from != "" {
+		if start, err = time.ParseInLocation("2006-01-02", *from, location); err != nil {
+			fail(fmt.Errorf("invalid -from: %w", err))
+		}
+	}
+	if *to != "" {
+		if end, err = time.ParseInLocation("2006-01-02", *to, location); err != nil {
+			fail(fmt.Errorf("invalid -to: %w", err))
+		}
+		end = end.AddDate(0, 0, 1)
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		fail(fmt.Errorf("-from must not be after -to"))
+	}
+
+	mongoClient, err := infrastructure.ConnectMongo(cfg.MongoURI)
+	if err != nil {
+		fail(fmt.Errorf("failed to connect to MongoDB: %w", err))
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	rollupRepo := mongodb.NewAttendanceRollupRepository(mongoClient.Database(cfg.Database), location)
+
+	began := time.Now()
+	written, err := rollupRepo.Rebuild(context.Background(), start, end)
+	if err != nil {
+		fail(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(map[string]interface{}{
+		"from":     *from,
+		"to":       *to,
+		"timezone": location.String(),
+		"rollups":  written,
+		"duration": time.Since(began).String(),
+	})
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: analytics-rollup [-from YYYY-MM-DD] [-to YYYY-MM-DD]")
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
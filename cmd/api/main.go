@@ -2,18 +2,30 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 	httpAdapter "github.com/okinn/service-presensi/internal/adapter/inbound/http"
 	"github.com/okinn/service-presensi/internal/adapter/inbound/http/middleware"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/audit"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/cache"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/filesystem"
 	"github.com/okinn/service-presensi/internal/adapter/outbound/mongodb"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/oidc"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/rollup"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/smtp"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/syslog"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/webhook"
 	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
 	"github.com/okinn/service-presensi/internal/domain/service"
 	"github.com/okinn/service-presensi/internal/infrastructure"
 	"github.com/okinn/service-presensi/pkg/jwt"
@@ -49,12 +61,70 @@ func main() {
 	// Initialize JWT Manager
 	jwtManager := jwt.NewJWTManager(cfg.JWTSecret, time.Duration(cfg.JWTExpireMinutes)*time.Minute)
 
+	// Audit repository, nil when audit logging is disabled
+	var auditRepo repository.AuditLogRepository
+	var auditChainService *service.AuditChainService
+	var auditRetentionService *service.AuditRetentionService
+	var auditSinkRepo *audit.SinkRepository
+	var auditQueue *audit.QueuedRepository
+	if cfg.AuditEnabled {
+		auditRepo = mongodb.NewAuditLogRepository(db)
+
+		// Forward stored entries to external sinks (SIEM)
+		sinks, err := newAuditSinks(cfg)
+		if err != nil {
+			logger.Error("Failed to configure audit sinks", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		if len(sinks) > 0 {
+			auditSinkRepo = audit.NewSinkRepository(auditRepo, sinks, cfg.AuditSinkBufferSize, logger)
+			auditRepo = auditSinkRepo
+			logger.Info("Audit sinks enabled", slog.Int("sinks", len(sinks)))
+		}
+
+		// Write entries in batches from a bounded queue, spilling to disk while MongoDB is unavailable
+		auditQueue = audit.NewQueuedRepository(auditRepo, audit.QueueConfig{
+			Capacity:      cfg.AuditQueueSize,
+			Workers:       cfg.AuditQueueWorkers,
+			BatchSize:     cfg.AuditBatchSize,
+			FlushInterval: time.Duration(cfg.AuditFlushIntervalMs) * time.Millisecond,
+			MaxAttempts:   cfg.AuditWriteMaxAttempts,
+			SpillPath:     cfg.AuditSpillFile,
+		}, logger)
+		auditRepo = auditQueue
+
+		auditChainService = service.NewAuditChainService(auditRepo, mongodb.NewAuditCheckpointRepository(db), cfg.AuditSigningKey)
+		logger.Info("Audit logging enabled")
+
+		if cfg.AuditRetentionIntervalHours > 0 {
+			retentionPolicy, err := entity.ParseAuditRetentionPolicy(cfg.AuditRetentionDays, cfg.AuditRetentionByType)
+			if err != nil {
+				logger.Error("Invalid audit retention policy", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			archiveStorage, err := filesystem.NewArchiveStorage(cfg.AuditArchiveDir)
+			if err != nil {
+				logger.Error("Failed to open audit archive directory", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			auditRetentionService = service.NewAuditRetentionService(auditRepo, archiveStorage, retentionPolicy)
+			logger.Info("Audit retention enabled", slog.Int("retention_days", cfg.AuditRetentionDays), slog.String("archive_dir", cfg.AuditArchiveDir))
+		}
+	}
+
 	// Initialize layers (Dependency Injection)
 	// Outbound adapter: MongoDB repository implements domain port
 	presensiRepo := mongodb.NewPresensiRepository(db)
 	userRepo := mongodb.NewUserRepository(db)
 	locationRepo := mongodb.NewAllowedLocationRepository(db)
 
+	// Audit decorators record before/after state and field diffs of every change
+	if auditRepo != nil {
+		presensiRepo = audit.NewPresensiRepository(presensiRepo, auditRepo)
+		userRepo = audit.NewUserRepository(userRepo, auditRepo)
+		locationRepo = audit.NewLocationRepository(locationRepo, auditRepo)
+	}
+
 	// Domain service: Location service for geofencing
 	var locationService *service.LocationService
 	if cfg.GeofenceEnabled {
@@ -65,30 +135,217 @@ func main() {
 	}
 
 	// Analytics repository
-	analyticsRepo := mongodb.NewAnalyticsRepository(db)
+	analyticsLocation, err := time.LoadLocation(cfg.AnalyticsTimezone)
+	if err != nil {
+		logger.Error("Invalid analytics timezone", slog.String("timezone", cfg.AnalyticsTimezone), slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Daily rollups are refreshed on every presensi change, but only read once a full rebuild has
+	// succeeded. Until then analytics reads presensi directly.
+	rollupRepo := mongodb.NewAttendanceRollupRepository(db, analyticsLocation)
+	readRollups := false
+	if cfg.AnalyticsRollups {
+		presensiRepo = rollup.NewPresensiRepository(presensiRepo, rollupRepo, logger)
+		readRollups = prepareRollups(context.Background(), rollupRepo, logger)
+	} else if err := rollupRepo.MarkStale(context.Background()); err != nil {
+		// Presensi may change without the rollups, the next start with rollups enabled must rebuild them
+		logger.Error("Failed to mark attendance rollups stale", slog.String("error", err.Error()))
+	}
+	analyticsRepo := mongodb.NewAnalyticsRepository(db, analyticsLocation, readRollups)
+
+	// Analytics cache, entries that read the day of a changed presensi are dropped
+	var analyticsCache repository.AnalyticsCache
+	switch cfg.AnalyticsCache {
+	case "":
+	case "memory":
+		analyticsCache = cache.NewMemoryAnalyticsCache(cfg.AnalyticsCacheSize)
+	case "redis":
+		redisCache, err := cache.NewRedisAnalyticsCache(cfg.AnalyticsCacheRedisURL)
+		if err != nil {
+			logger.Error("Invalid analytics cache redis URL", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		defer redisCache.Close()
+		analyticsCache = redisCache
+	default:
+		logger.Error("Invalid analytics cache, use memory, redis or empty", slog.String("analytics_cache", cfg.AnalyticsCache))
+		os.Exit(1)
+	}
+	if analyticsCache != nil {
+		presensiRepo = cache.NewPresensiRepository(presensiRepo, analyticsCache, logger)
+		logger.Info("Analytics cache enabled", slog.String("backend", cfg.AnalyticsCache), slog.Int("ttl_seconds", cfg.AnalyticsCacheTTLSeconds))
+	}
+	workStart, err := entity.ParseClock(cfg.WorkStartTime)
+	if err != nil {
+		logger.Error("Invalid work start time", slog.String("work_start_time", cfg.WorkStartTime), slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	workDays, err := entity.ParseWeekdays(strings.Split(cfg.WorkDays, ","))
+	if err != nil {
+		logger.Error("Invalid work days", slog.String("work_days", cfg.WorkDays), slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	holidays, err := entity.ParseHolidays(strings.Split(cfg.Holidays, ","))
+	if err != nil {
+		logger.Error("Invalid holidays", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Domain service: Per-account login lockout
+	var lockoutService *service.LoginLockoutService
+	if cfg.LoginLockoutEnabled {
+		lockoutService = service.NewLoginLockoutService(
+			mongodb.NewLoginAttemptRepository(db),
+			auditRepo,
+			entity.LockoutPolicy{
+				MaxAttempts:  cfg.LoginLockoutMaxAttempts,
+				BaseDuration: time.Duration(cfg.LoginLockoutBaseMinutes) * time.Minute,
+				MaxDuration:  time.Duration(cfg.LoginLockoutMaxMinutes) * time.Minute,
+				ResetAfter:   time.Duration(cfg.LoginLockoutResetMinutes) * time.Minute,
+			},
+		)
+		logger.Info("Login lockout enabled", slog.Int("max_attempts", cfg.LoginLockoutMaxAttempts))
+	}
+
+	// Password policy
+	passwordPolicy := entity.PasswordPolicy{
+		MinLength:            cfg.PasswordMinLength,
+		RequireUpper:         cfg.PasswordRequireUpper,
+		RequireLower:         cfg.PasswordRequireLower,
+		RequireDigit:         cfg.PasswordRequireDigit,
+		RequireSymbol:        cfg.PasswordRequireSymbol,
+		DisallowPersonalInfo: cfg.PasswordDisallowPersonalInfo,
+		HistorySize:          cfg.PasswordHistorySize,
+	}
+	if cfg.BreachedPasswordsFile != "" {
+		breachedChecker, err := filesystem.NewBreachedPasswordChecker(cfg.BreachedPasswordsFile)
+		if err != nil {
+			logger.Error("Failed to load breached passwords file", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		defer breachedChecker.Close()
+		passwordPolicy.BreachedChecker = breachedChecker
+		logger.Info("Breached password check enabled", slog.String("file", cfg.BreachedPasswordsFile))
+	}
+
+	// Domain service: departments, teams and reporting hierarchy
+	departmentRepo := mongodb.NewDepartmentRepository(db)
+	teamRepo := mongodb.NewTeamRepository(db)
+	orgService := service.NewOrganizationService(departmentRepo, teamRepo, userRepo)
 
 	// Application layer: Use case depends on domain port (not adapter)
-	presensiUseCase := usecase.NewPresensiUseCase(presensiRepo, locationService)
-	authUseCase := usecase.NewAuthUseCase(userRepo, jwtManager)
-	analyticsUseCase := usecase.NewAnalyticsUseCase(analyticsRepo)
+	roleUseCase := usecase.NewRoleUseCase(mongodb.NewRoleRepository(db), userRepo)
+	presensiUseCase := usecase.NewPresensiUseCase(presensiRepo, userRepo, locationService, orgService, roleUseCase)
+	mfaConfig := usecase.MFAConfig{
+		Issuer:            cfg.MFAIssuer,
+		RequiredForAdmin:  cfg.MFARequiredForAdmin,
+		ChallengeDuration: time.Duration(cfg.MFAChallengeMinutes) * time.Minute,
+	}
+	authUseCase := usecase.NewAuthUseCase(userRepo, jwtManager, mongodb.NewRefreshTokenRepository(db), passwordPolicy, lockoutService, auditRepo, mfaConfig)
+	analyticsUseCase := usecase.NewAnalyticsUseCase(analyticsRepo, userRepo, orgService, roleUseCase, usecase.AnalyticsConfig{
+		Location: analyticsLocation,
+		Schedule: entity.WorkSchedule{
+			StartTime: workStart,
+			WorkHours: time.Duration(cfg.WorkHoursPerDay * float64(time.Hour)),
+			WorkDays:  workDays,
+			Holidays:  holidays,
+		},
+	})
+	if analyticsCache != nil {
+		analyticsUseCase = usecase.NewCachedAnalyticsUseCase(analyticsUseCase, analyticsCache, usecase.AnalyticsCacheConfig{
+			Location: analyticsLocation,
+			TTL:      time.Duration(cfg.AnalyticsCacheTTLSeconds) * time.Second,
+			PastTTL:  time.Duration(cfg.AnalyticsCachePastTTLSeconds) * time.Second,
+		})
+	}
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(mongodb.NewAPIKeyRepository(db))
+	orgUseCase := usecase.NewOrganizationUseCase(departmentRepo, teamRepo, userRepo, orgService)
+
+	// Invitation emails for imported users (outbound adapter), disabled without SMTP host
+	var invitationSender repository.InvitationSender
+	if cfg.SMTPHost != "" {
+		invitationSender = smtp.NewMailer(smtp.Config{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			AppName:  cfg.MFAIssuer,
+		})
+		logger.Info("Invitation emails enabled", slog.String("smtp_host", cfg.SMTPHost))
+	}
+	importUseCase := usecase.NewUserImportUseCase(userRepo, roleUseCase, jwtManager, passwordPolicy, invitationSender, usecase.InvitationConfig{
+		AcceptURL: cfg.InvitationURL,
+		Duration:  time.Duration(cfg.InvitationExpireHours) * time.Hour,
+	})
+
+	// Single sign-on: OpenID Connect provider (outbound adapter)
+	var oidcHandler *httpAdapter.OIDCHandler
+	if cfg.OIDCEnabled {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		identityProvider, err := oidc.NewProvider(ctx, oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       strings.Fields(cfg.OIDCScopes),
+			GroupsClaim:  cfg.OIDCGroupsClaim,
+		})
+		cancel()
+		if err != nil {
+			logger.Error("Failed to initialize OIDC provider", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+
+		oidcConfig := usecase.OIDCConfig{
+			GroupRoles:    parseGroupRoles(cfg.OIDCGroupRoles),
+			DefaultRole:   entity.RoleEmployee,
+			AutoProvision: cfg.OIDCAutoProvision,
+			SyncRole:      cfg.OIDCSyncRole,
+			StateDuration: 10 * time.Minute,
+			MFA:           mfaConfig,
+		}
+		oidcUseCase := usecase.NewOIDCUseCase(identityProvider, userRepo, jwtManager, auditRepo, oidcConfig)
+		oidcHandler = httpAdapter.NewOIDCHandler(oidcUseCase, strings.HasPrefix(cfg.OIDCRedirectURL, "https://"), oidcConfig.StateDuration)
+		logger.Info("OIDC single sign-on enabled", slog.String("issuer", cfg.OIDCIssuerURL))
+	}
 
 	// Inbound adapter: HTTP handler depends on use case
 	presensiHandler := httpAdapter.NewPresensiHandler(presensiUseCase)
 	authHandler := httpAdapter.NewAuthHandler(authUseCase)
 	locationHandler := httpAdapter.NewLocationHandler(locationRepo)
 	analyticsHandler := httpAdapter.NewAnalyticsHandler(analyticsUseCase)
+	apiKeyHandler := httpAdapter.NewAPIKeyHandler(apiKeyUseCase)
+	roleHandler := httpAdapter.NewRoleHandler(roleUseCase)
+	orgHandler := httpAdapter.NewOrganizationHandler(orgUseCase)
+	importHandler := httpAdapter.NewUserImportHandler(importUseCase)
+
+	var auditHandler *httpAdapter.AuditHandler
+	var auditMiddleware *middleware.AuditMiddleware
+	if auditRepo != nil {
+		auditHandler = httpAdapter.NewAuditHandler(auditRepo, auditChainService)
+		auditMiddleware = middleware.NewAuditMiddleware(auditRepo)
+	}
 
 	// Middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, apiKeyUseCase, roleUseCase)
 	loginRateLimiter := middleware.NewLoginRateLimiter()
 
 	// Setup router (inbound adapter)
 	router := httpAdapter.NewRouter(httpAdapter.RouterConfig{
 		PresensiHandler:  presensiHandler,
 		AuthHandler:      authHandler,
+		AuditHandler:     auditHandler,
 		LocationHandler:  locationHandler,
 		AnalyticsHandler: analyticsHandler,
+		APIKeyHandler:    apiKeyHandler,
+		OIDCHandler:      oidcHandler,
+		RoleHandler:      roleHandler,
+		OrgHandler:       orgHandler,
+		ImportHandler:    importHandler,
 		AuthMiddleware:   authMiddleware,
+		AuditMiddleware:  auditMiddleware,
 		Logger:           logger,
 		LoginRateLimiter: loginRateLimiter,
 	})
@@ -111,12 +368,27 @@ func main() {
 		}
 	}()
 
+	// Background audit jobs, stopped on shutdown
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Periodic signed checkpoints of the audit hash chain
+	if auditChainService != nil && cfg.AuditCheckpointMinutes > 0 {
+		go runAuditCheckpoints(backgroundCtx, auditChainService, time.Duration(cfg.AuditCheckpointMinutes)*time.Minute, logger)
+	}
+
+	// Periodic archival of audit entries past their retention period
+	if auditRetentionService != nil {
+		go runAuditRetention(backgroundCtx, auditRetentionService, time.Duration(cfg.AuditRetentionIntervalHours)*time.Hour, logger)
+	}
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Info("Shutting down server...")
+	stopBackground()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -126,5 +398,142 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Write the queued audit entries, then deliver them to external sinks
+	if auditQueue != nil {
+		auditQueue.Close(ctx)
+	}
+	if auditSinkRepo != nil {
+		auditSinkRepo.Close(ctx)
+	}
+
+	// Anchor the entries written while shutting down
+	if auditChainService != nil && cfg.AuditCheckpointMinutes > 0 {
+		createAuditCheckpoint(ctx, auditChainService, logger)
+	}
+
 	logger.Info("Server exited")
 }
+
+// newAuditSinks creates the configured external audit sinks
+func newAuditSinks(cfg *infrastructure.Config) ([]repository.AuditSink, error) {
+	var sinks []repository.AuditSink
+
+	if cfg.AuditSyslogAddr != "" {
+		sink, err := syslog.NewSink(cfg.AuditSyslogAddr, "service-presensi")
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.AuditSinkFile != "" {
+		sink, err := filesystem.NewAuditFileSink(cfg.AuditSinkFile)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.AuditWebhookURL != "" {
+		sinks = append(sinks, webhook.NewSink(webhook.Config{
+			URL:         cfg.AuditWebhookURL,
+			Secret:      cfg.AuditWebhookSecret,
+			MaxAttempts: cfg.AuditWebhookMaxAttempts,
+		}))
+	}
+
+	return sinks, nil
+}
+
+// runAuditCheckpoints signs the head of the audit hash chain every interval until ctx is cancelled
+func runAuditCheckpoints(ctx context.Context, chainService *service.AuditChainService, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			createAuditCheckpoint(ctx, chainService, logger)
+		}
+	}
+}
+
+func createAuditCheckpoint(ctx context.Context, chainService *service.AuditChainService, logger *slog.Logger) {
+	checkpoint, err := chainService.Checkpoint(ctx)
+	if err != nil {
+		logger.Error("Failed to create audit checkpoint", slog.String("error", err.Error()))
+		return
+	}
+	if checkpoint != nil {
+		logger.Info("Audit checkpoint created", slog.Int64("sequence", checkpoint.Sequence))
+	}
+}
+
+// runAuditRetention archives expired audit entries at startup and then every interval until ctx is cancelled
+func runAuditRetention(ctx context.Context, retentionService *service.AuditRetentionService, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := retentionService.Archive(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Failed to archive audit logs", slog.String("error", err.Error()))
+		}
+		if result != nil && result.Archived > 0 {
+			logger.Info("Audit logs archived", slog.Int64("entries", result.Archived), slog.Int("archives", len(result.Archives)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// prepareRollups rebuilds the rollups when they are not current (never fully built, built in another
+// time zone, or stale because the server ran with ANALYTICS_ROLLUPS=false) and reports whether
+// analytics may read them
+func prepareRollups(ctx context.Context, rollupRepo repository.AttendanceRollupRepository, logger *slog.Logger) bool {
+	current, err := rollupRepo.IsCurrent(ctx)
+	if err != nil {
+		logger.Error("Failed to check attendance rollups, reading presensi instead", slog.String("error", err.Error()))
+		return false
+	}
+	if current {
+		return true
+	}
+
+	logger.Info("Building attendance rollups")
+	written, err := rollupRepo.Rebuild(ctx, time.Time{}, time.Time{})
+	if errors.Is(err, repository.ErrRollupRebuildRunning) {
+		// Another instance is building them; this one reads presensi until its next start
+		logger.Info("Attendance rollups are being built by another instance, reading presensi instead")
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to build attendance rollups, reading presensi instead", slog.String("error", err.Error()))
+		return false
+	}
+	logger.Info("Attendance rollups built", slog.Int64("rollups", written))
+	return true
+}
+
+// parseGroupRoles parses "group=role,group=role" into an ordered mapping, invalid roles are skipped
+func parseGroupRoles(value string) []usecase.OIDCGroupRole {
+	var mappings []usecase.OIDCGroupRole
+	for _, pair := range strings.Split(value, ",") {
+		group, role, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || group == "" {
+			continue
+		}
+
+		userRole := entity.UserRole(strings.TrimSpace(role))
+		if !userRole.IsValid() {
+			slog.Warn("Ignoring OIDC group mapping with unknown role", slog.String("group", group), slog.String("role", role))
+			continue
+		}
+		mappings = append(mappings, usecase.OIDCGroupRole{Group: strings.TrimSpace(group), Role: userRole})
+	}
+	return mappings
+}
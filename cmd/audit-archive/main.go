@@ -0,0 +1,83 @@
+// Command audit-archive archives audit entries past their retention period and re-imports
+// archives for investigations.
+//
+// Usage:
+//
+//	audit-archive run            archive expired entries now
+//	audit-archive list           list archive files
+//	audit-archive import NAME    restore the entries of an archive
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/filesystem"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/mongodb"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/service"
+	"github.com/okinn/service-presensi/internal/infrastructure"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] == "import" && len(os.Args) < 3) {
+		usage()
+	}
+
+	_ = godotenv.Load()
+	cfg := infrastructure.LoadConfig()
+
+	policy, err := entity.ParseAuditRetentionPolicy(cfg.AuditRetentionDays, cfg.AuditRetentionByType)
+	if err != nil {
+		fail(err)
+	}
+	storage, err := filesystem.NewArchiveStorage(cfg.AuditArchiveDir)
+	if err != nil {
+		fail(err)
+	}
+
+	mongoClient, err := infrastructure.ConnectMongo(cfg.MongoURI)
+	if err != nil {
+		fail(fmt.Errorf("failed to connect to MongoDB: %w", err))
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	retentionService := service.NewAuditRetentionService(
+		mongodb.NewAuditLogRepository(mongoClient.Database(cfg.Database)),
+		storage,
+		policy,
+	)
+
+	ctx := context.Background()
+	var result interface{}
+	switch os.Args[1] {
+	case "run":
+		result, err = retentionService.Archive(ctx)
+	case "list":
+		result, err = retentionService.ListArchives(ctx)
+	case "import":
+		result, err = retentionService.Import(ctx, os.Args[2])
+	default:
+		usage()
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: audit-archive run | list | import NAME")
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
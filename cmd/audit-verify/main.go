@@ -0,0 +1,70 @@
+// Command audit-verify walks the audit hash chain and reports tampered, unlinked or missing entries.
+//
+// Usage:
+//
+//	audit-verify [-from N] [-checkpoint]
+//
+// The exit code is 0 when the chain is intact, 1 when issues were found and 2 on errors.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/okinn/service-presensi/internal/adapter/outbound/mongodb"
+	"github.com/okinn/service-presensi/internal/domain/service"
+	"github.com/okinn/service-presensi/internal/infrastructure"
+)
+
+func main() {
+	from := flag.Int64("from", 1, "sequence number to start verifying from")
+	checkpoint := flag.Bool("checkpoint", false, "sign a checkpoint of the chain head after a successful verification")
+	flag.Parse()
+
+	_ = godotenv.Load()
+	cfg := infrastructure.LoadConfig()
+
+	mongoClient, err := infrastructure.ConnectMongo(cfg.MongoURI)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to MongoDB:", err)
+		os.Exit(2)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	db := mongoClient.Database(cfg.Database)
+	chainService := service.NewAuditChainService(
+		mongodb.NewAuditLogRepository(db),
+		mongodb.NewAuditCheckpointRepository(db),
+		cfg.AuditSigningKey,
+	)
+
+	ctx := context.Background()
+	report, err := chainService.Verify(ctx, *from)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "verification failed:", err)
+		os.Exit(2)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if !report.Valid {
+		os.Exit(1)
+	}
+
+	if *checkpoint {
+		cp, err := chainService.Checkpoint(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to create checkpoint:", err)
+			os.Exit(2)
+		}
+		if cp != nil {
+			fmt.Fprintf(os.Stderr, "checkpoint created at sequence %d\n", cp.Sequence)
+		}
+	}
+}
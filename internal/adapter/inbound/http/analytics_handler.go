@@ -2,8 +2,11 @@ package http
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/okinn/service-presensi/internal/adapter/inbound/http/middleware"
 	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
 )
 
 type AnalyticsHandler struct {
@@ -15,23 +18,23 @@ func NewAnalyticsHandler(uc usecase.AnalyticsUseCase) *AnalyticsHandler {
 }
 
 // GetSummary returns overall attendance summary
-// GET /api/analytics/summary?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// GET /api/analytics/summary?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&department_id=&team_id=
 func (h *AnalyticsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	startDate := query.Get("start_date")
 	endDate := query.Get("end_date")
 
-	summary, err := h.useCase.GetSummary(r.Context(), startDate, endDate)
+	summary, err := h.useCase.GetSummary(r.Context(), actorFromRequest(r), startDate, endDate, orgUnitFromRequest(r))
 	if err != nil {
-		Error(w, http.StatusInternalServerError, err.Error())
+		writeAnalyticsError(w, err)
 		return
 	}
 
-	Success(w, http.StatusOK, "Berhasil mengambil summary", summary)
+	SuccessWithETag(w, r, "Berhasil mengambil summary", summary)
 }
 
 // GetDailySummary returns attendance summary for a specific date
-// GET /api/analytics/daily?date=YYYY-MM-DD
+// GET /api/analytics/daily?date=YYYY-MM-DD&department_id=&team_id=
 func (h *AnalyticsHandler) GetDailySummary(w http.ResponseWriter, r *http.Request) {
 	date := r.URL.Query().Get("date")
 	if date == "" {
@@ -39,17 +42,17 @@ func (h *AnalyticsHandler) GetDailySummary(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	summary, err := h.useCase.GetDailySummary(r.Context(), date)
+	summary, err := h.useCase.GetDailySummary(r.Context(), actorFromRequest(r), date, orgUnitFromRequest(r))
 	if err != nil {
-		Error(w, http.StatusInternalServerError, err.Error())
+		writeAnalyticsError(w, err)
 		return
 	}
 
-	Success(w, http.StatusOK, "Berhasil mengambil summary harian", summary)
+	SuccessWithETag(w, r, "Berhasil mengambil summary harian", summary)
 }
 
 // GetMonthlySummary returns attendance summary for a month
-// GET /api/analytics/monthly?month=YYYY-MM
+// GET /api/analytics/monthly?month=YYYY-MM&department_id=&team_id=
 func (h *AnalyticsHandler) GetMonthlySummary(w http.ResponseWriter, r *http.Request) {
 	month := r.URL.Query().Get("month")
 	if month == "" {
@@ -57,13 +60,13 @@ func (h *AnalyticsHandler) GetMonthlySummary(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	summary, err := h.useCase.GetMonthlySummary(r.Context(), month)
+	summary, err := h.useCase.GetMonthlySummary(r.Context(), actorFromRequest(r), month, orgUnitFromRequest(r))
 	if err != nil {
-		Error(w, http.StatusInternalServerError, err.Error())
+		writeAnalyticsError(w, err)
 		return
 	}
 
-	Success(w, http.StatusOK, "Berhasil mengambil summary bulanan", summary)
+	SuccessWithETag(w, r, "Berhasil mengambil summary bulanan", summary)
 }
 
 // GetUserSummary returns attendance summary for a specific user
@@ -79,27 +82,126 @@ func (h *AnalyticsHandler) GetUserSummary(w http.ResponseWriter, r *http.Request
 	startDate := query.Get("start_date")
 	endDate := query.Get("end_date")
 
-	summary, err := h.useCase.GetUserSummary(r.Context(), userID, startDate, endDate)
+	summary, err := h.useCase.GetUserSummary(r.Context(), actorFromRequest(r), userID, startDate, endDate)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, err.Error())
+		writeAnalyticsError(w, err)
 		return
 	}
 
-	Success(w, http.StatusOK, "Berhasil mengambil summary user", summary)
+	SuccessWithETag(w, r, "Berhasil mengambil summary user", summary)
 }
 
 // GetStatusBreakdown returns count per status
-// GET /api/analytics/status-breakdown?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// GET /api/analytics/status-breakdown?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&department_id=&team_id=
 func (h *AnalyticsHandler) GetStatusBreakdown(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	startDate := query.Get("start_date")
 	endDate := query.Get("end_date")
 
-	breakdown, err := h.useCase.GetStatusBreakdown(r.Context(), startDate, endDate)
+	breakdown, err := h.useCase.GetStatusBreakdown(r.Context(), actorFromRequest(r), startDate, endDate, orgUnitFromRequest(r))
 	if err != nil {
-		Error(w, http.StatusInternalServerError, err.Error())
+		writeAnalyticsError(w, err)
 		return
 	}
 
-	Success(w, http.StatusOK, "Berhasil mengambil status breakdown", breakdown)
+	SuccessWithETag(w, r, "Berhasil mengambil status breakdown", breakdown)
+}
+
+// GetPeriodSummary returns the summary of a week, month, quarter, year or date range with a time series
+// GET /api/analytics/period?period=week|month|quarter|year|range (default month)&value=&start_date=&end_date=&interval=day|week|month&compare=true&department_id=&team_id=
+func (h *AnalyticsHandler) GetPeriodSummary(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	input := usecase.PeriodInput{
+		PeriodSelection: periodSelectionFromRequest(r),
+		Interval:        query.Get("interval"),
+		Compare:         query.Get("compare") == "true",
+	}
+
+	summary, err := h.useCase.GetPeriodSummary(r.Context(), actorFromRequest(r), input, orgUnitFromRequest(r))
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+
+	SuccessWithETag(w, r, "Berhasil mengambil summary periode", summary)
+}
+
+// GetPunctuality returns late minutes, the check-in time distribution, on-time streaks and the most late and most punctual users
+// GET /api/analytics/punctuality?period=&value=&start_date=&end_date=&top=5&department_id=&team_id=
+func (h *AnalyticsHandler) GetPunctuality(w http.ResponseWriter, r *http.Request) {
+	input := usecase.PunctualityInput{PeriodSelection: periodSelectionFromRequest(r)}
+	if top := r.URL.Query().Get("top"); top != "" {
+		parsed, err := strconv.Atoi(top)
+		if err != nil || parsed < 1 {
+			Error(w, http.StatusBadRequest, "Parameter 'top' harus angka positif")
+			return
+		}
+		input.Top = parsed
+	}
+
+	report, err := h.useCase.GetPunctuality(r.Context(), actorFromRequest(r), input, orgUnitFromRequest(r))
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+
+	SuccessWithETag(w, r, "Berhasil mengambil analytics ketepatan waktu", report)
+}
+
+// GetWorkingHours returns hours worked, overtime and missing check-outs per user, department and bucket of the period
+// GET /api/analytics/hours?period=&value=&start_date=&end_date=&interval=day|week|month&department_id=&team_id=
+func (h *AnalyticsHandler) GetWorkingHours(w http.ResponseWriter, r *http.Request) {
+	input := usecase.WorkingHoursInput{
+		PeriodSelection: periodSelectionFromRequest(r),
+		Interval:        r.URL.Query().Get("interval"),
+	}
+
+	report, err := h.useCase.GetWorkingHours(r.Context(), actorFromRequest(r), input, orgUnitFromRequest(r))
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+
+	SuccessWithETag(w, r, "Berhasil mengambil analytics jam kerja", report)
+}
+
+// periodSelectionFromRequest reads the period, value, start_date and end_date query parameters
+func periodSelectionFromRequest(r *http.Request) usecase.PeriodSelection {
+	query := r.URL.Query()
+	return usecase.PeriodSelection{
+		Period:    query.Get("period"),
+		Value:     query.Get("value"),
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+	}
+}
+
+// orgUnitFromRequest reads the department_id, team_id and manager_id query parameters
+func orgUnitFromRequest(r *http.Request) usecase.OrgUnit {
+	query := r.URL.Query()
+	return usecase.OrgUnit{
+		DepartmentID: query.Get("department_id"),
+		TeamID:       query.Get("team_id"),
+		ManagerID:    query.Get("manager_id"),
+	}
+}
+
+// actorFromRequest returns the caller as set by the auth middleware from the JWT claims or API key
+func actorFromRequest(r *http.Request) usecase.Actor {
+	return usecase.Actor{
+		UserID:   middleware.GetUserID(r.Context()),
+		Role:     middleware.GetRole(r.Context()),
+		APIKeyID: middleware.GetAPIKeyID(r.Context()),
+	}
+}
+
+func writeAnalyticsError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrAccessDenied:
+		Error(w, http.StatusForbidden, err.Error())
+	case entity.ErrInvalidPeriod, entity.ErrInvalidInterval:
+		Error(w, http.StatusBadRequest, err.Error())
+	default:
+		Error(w, http.StatusInternalServerError, err.Error())
+	}
 }
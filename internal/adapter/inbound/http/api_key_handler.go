@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/adapter/inbound/http/middleware"
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/pkg/validator"
+)
+
+// APIKeyHandler handles HTTP requests for API key management
+type APIKeyHandler struct {
+	useCase usecase.APIKeyUseCase
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(uc usecase.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{useCase: uc}
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=2,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,api_key_scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Create issues a new API key, the plaintext key is only returned in this response
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		if validationErrs, ok := err.(validator.ValidationErrors); ok {
+			ValidationError(w, validationErrs)
+			return
+		}
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	output, err := h.useCase.Create(r.Context(), usecase.CreateAPIKeyInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: middleware.GetUserID(r.Context()),
+	})
+	if err != nil {
+		switch err {
+		case entity.ErrInvalidAPIKeyName, entity.ErrInvalidAPIKeyScope, entity.ErrInvalidExpiry:
+			Error(w, http.StatusBadRequest, err.Error())
+		default:
+			Error(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	Success(w, http.StatusCreated, "API key berhasil dibuat, simpan key ini karena tidak akan ditampilkan lagi", output)
+}
+
+// GetAll returns all API keys without their secrets
+func (h *APIKeyHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.useCase.GetAll(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(w, http.StatusOK, "Berhasil", outputs)
+}
+
+// Revoke revokes an API key
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		Error(w, http.StatusBadRequest, "ID tidak valid")
+		return
+	}
+
+	if err := h.useCase.Revoke(r.Context(), id); err != nil {
+		switch err {
+		case usecase.ErrAPIKeyNotFound:
+			Error(w, http.StatusNotFound, err.Error())
+		case entity.ErrAPIKeyRevoked:
+			Error(w, http.StatusConflict, err.Error())
+		default:
+			Error(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	Success(w, http.StatusOK, "API key berhasil dicabut", nil)
+}
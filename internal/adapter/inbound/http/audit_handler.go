@@ -9,54 +9,81 @@
 package http
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/okinn/service-presensi/internal/domain/entity"
 	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/internal/domain/service"
 )
 
 // AuditHandler handles HTTP requests for audit logs
 type AuditHandler struct {
-	auditRepo repository.AuditLogRepository
+	auditRepo    repository.AuditLogRepository
+	chainService *service.AuditChainService
 }
 
 // NewAuditHandler creates a new audit handler
-func NewAuditHandler(auditRepo repository.AuditLogRepository) *AuditHandler {
+func NewAuditHandler(auditRepo repository.AuditLogRepository, chainService *service.AuditChainService) *AuditHandler {
 	return &AuditHandler{
-		auditRepo: auditRepo,
+		auditRepo:    auditRepo,
+		chainService: chainService,
 	}
 }
 
-// GetAll returns all audit logs with filtering and pagination
+// GetAll returns all audit logs with filtering, sorting and pagination. Page pagination is used
+// by default; with a "cursor" parameter (empty for the first page) the response holds a
+// next_cursor instead of totals, which stays fast and stable deep into large result sets.
+// GET /api/audit?q=&status_min=&status_max=&min_duration_ms=&max_duration_ms=&request_id=&sort=&order=&cursor=
 func (h *AuditHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	query := r.URL.Query()
 
-	// Parse query parameters
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
 	if limit < 1 || limit > 100 {
 		limit = 20
 	}
 
-	// Build filter
-	filter := repository.AuditLogFilter{
-		EntityType: r.URL.Query().Get("entity_type"),
-		EntityID:   r.URL.Query().Get("entity_id"),
-		UserID:     r.URL.Query().Get("user_id"),
-		IPAddress:  r.URL.Query().Get("ip_address"),
+	sort, err := auditSortFromQuery(query)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := auditFilterFromQuery(query)
+
+	if query.Has("cursor") {
+		result, err := h.auditRepo.GetAllByCursor(ctx, filter, sort, query.Get("cursor"), limit)
+		if err != nil {
+			if errors.Is(err, repository.ErrInvalidAuditCursor) {
+				Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			Error(w, http.StatusInternalServerError, "Gagal mengambil audit logs")
+			return
+		}
+
+		Success(w, http.StatusOK, "Berhasil mengambil audit logs", map[string]interface{}{
+			"data":        result.Items,
+			"limit":       limit,
+			"next_cursor": result.NextCursor,
+		})
+		return
 	}
 
-	if action := r.URL.Query().Get("action"); action != "" {
-		filter.Action = entity.AuditAction(action)
+	// Parse query parameters
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
 	}
 
 	// Get audit logs
-	auditLogs, total, err := h.auditRepo.GetAll(ctx, filter, page, limit)
+	auditLogs, total, err := h.auditRepo.GetAll(ctx, filter, sort, page, limit)
 	if err != nil {
 		Error(w, http.StatusInternalServerError, "Gagal mengambil audit logs")
 		return
@@ -148,3 +175,180 @@ func (h *AuditHandler) GetByUser(w http.ResponseWriter, r *http.Request) {
 
 	Success(w, http.StatusOK, "Berhasil mengambil audit logs", response)
 }
+
+// Verify walks the audit hash chain and reports tampered, unlinked or missing entries.
+// The optional "from" query parameter starts the walk at that sequence number.
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+
+	report, err := h.chainService.Verify(r.Context(), from)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "Gagal memverifikasi audit logs")
+		return
+	}
+
+	Success(w, http.StatusOK, "Verifikasi audit logs selesai", report)
+}
+
+// exportFlushEvery is the number of rows written between flushes of a streamed export
+const exportFlushEvery = 500
+
+// Export streams the audit logs matching the filter as CSV (default) or NDJSON, oldest first.
+// Entries are written while they are read from the database, so any range can be exported.
+// GET /api/audit/export?format=csv|ndjson&entity_type=&entity_id=&user_id=&action=&ip_address=&start_date=&end_date=
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		Error(w, http.StatusBadRequest, "Format harus csv atau ndjson")
+		return
+	}
+
+	filename := "audit-logs-" + time.Now().Format("20060102-150405") + "." + format
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	flush := func() error {
+		// Export besar bisa melebihi WriteTimeout server, perpanjang selama data masih mengalir
+		controller.SetWriteDeadline(time.Now().Add(time.Minute))
+		return controller.Flush()
+	}
+	flush()
+
+	var write func(*entity.AuditLog) error
+	var csvWriter *csv.Writer
+	if format == "csv" {
+		csvWriter = csv.NewWriter(w)
+		csvWriter.Write(auditExportColumns)
+		write = func(a *entity.AuditLog) error {
+			return csvWriter.Write(auditExportRow(a))
+		}
+	} else {
+		encoder := json.NewEncoder(w)
+		write = func(a *entity.AuditLog) error {
+			return encoder.Encode(a)
+		}
+	}
+
+	rows := 0
+	err := h.auditRepo.WalkUnarchived(r.Context(), auditFilterFromQuery(r.URL.Query()), func(a *entity.AuditLog) error {
+		if err := write(a); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if csvWriter != nil {
+				csvWriter.Flush()
+			}
+			return flush()
+		}
+		return nil
+	})
+	if csvWriter != nil {
+		csvWriter.Flush()
+	}
+	if err != nil {
+		// Status sudah terkirim, export yang terpotong hanya bisa ditandai dengan menutup koneksi
+		panic(http.ErrAbortHandler)
+	}
+}
+
+var auditExportColumns = []string{
+	"id", "sequence", "created_at", "entity_type", "entity_id", "action",
+	"user_id", "user_name", "user_role", "ip_address", "user_agent", "request_id",
+	"status_code", "error_msg", "duration_ms", "old_value", "new_value", "changes", "hash",
+}
+
+func auditExportRow(a *entity.AuditLog) []string {
+	return []string{
+		a.ID,
+		strconv.FormatInt(a.Sequence, 10),
+		a.CreatedAt.Format(time.RFC3339Nano),
+		csvText(a.EntityType),
+		csvText(a.EntityID),
+		string(a.Action),
+		csvText(a.UserID),
+		csvText(a.UserName),
+		csvText(a.UserRole),
+		csvText(a.IPAddress),
+		csvText(a.UserAgent),
+		csvText(a.RequestID),
+		strconv.Itoa(a.StatusCode),
+		csvText(a.ErrorMsg),
+		strconv.FormatInt(a.Duration, 10),
+		csvText(a.OldValue),
+		csvText(a.NewValue),
+		csvText(a.Changes),
+		a.Hash,
+	}
+}
+
+// csvText prevents spreadsheet applications from evaluating client-supplied text as a formula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// auditFilterFromQuery builds the audit log filter shared by the list and export endpoints.
+// Dates are YYYY-MM-DD, end_date includes the whole day.
+func auditFilterFromQuery(query url.Values) repository.AuditLogFilter {
+	filter := repository.AuditLogFilter{
+		EntityType: query.Get("entity_type"),
+		EntityID:   query.Get("entity_id"),
+		UserID:     query.Get("user_id"),
+		IPAddress:  query.Get("ip_address"),
+		Action:     entity.AuditAction(query.Get("action")),
+		RequestID:  query.Get("request_id"),
+		Search:     strings.TrimSpace(query.Get("q")),
+	}
+
+	filter.StatusCodeMin, _ = strconv.Atoi(query.Get("status_min"))
+	filter.StatusCodeMax, _ = strconv.Atoi(query.Get("status_max"))
+	filter.MinDuration, _ = strconv.ParseInt(query.Get("min_duration_ms"), 10, 64)
+	filter.MaxDuration, _ = strconv.ParseInt(query.Get("max_duration_ms"), 10, 64)
+
+	if startDate := query.Get("start_date"); startDate != "" {
+		if t, err := time.Parse("2006-01-02", startDate); err == nil {
+			filter.StartDate = t
+		}
+	}
+	if endDate := query.Get("end_date"); endDate != "" {
+		if t, err := time.Parse("2006-01-02", endDate); err == nil {
+			filter.EndDate = t.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+
+	return filter
+}
+
+// auditSortFromQuery reads "sort" (created_at, duration_ms or status_code) and "order" (asc or desc, default desc)
+func auditSortFromQuery(query url.Values) (repository.AuditLogSort, error) {
+	sort := repository.AuditLogSort{Field: repository.AuditLogSortCreatedAt}
+
+	if field := query.Get("sort"); field != "" {
+		sort.Field = repository.AuditLogSortField(field)
+		if !sort.Field.IsValid() {
+			return sort, errors.New("sort harus created_at, duration_ms atau status_code")
+		}
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		sort.Ascending = true
+	default:
+		return sort, errors.New("order harus asc atau desc")
+	}
+
+	return sort, nil
+}
@@ -2,10 +2,13 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/okinn/service-presensi/internal/adapter/inbound/http/middleware"
 	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
 	"github.com/okinn/service-presensi/pkg/validator"
 )
 
@@ -19,9 +22,8 @@ func NewAuthHandler(uc usecase.AuthUseCase) *AuthHandler {
 
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required"`
 	Nama     string `json:"nama" validate:"required,min=2,max=100"`
-	Role     string `json:"role" validate:"omitempty,role"`
 }
 
 type LoginRequest struct {
@@ -29,6 +31,24 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest is optional, without a body only the logout is recorded
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type UnlockAccountRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -49,7 +69,6 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Email:    req.Email,
 		Password: req.Password,
 		Nama:     req.Nama,
-		Role:     req.Role,
 	}
 
 	output, err := h.useCase.Register(r.Context(), input)
@@ -63,6 +82,11 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if output.MFARequired {
+		Success(w, http.StatusCreated, "Registrasi berhasil, daftarkan MFA untuk login", output)
+		return
+	}
+
 	Success(w, http.StatusCreated, "Registrasi berhasil", output)
 }
 
@@ -83,8 +107,10 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	input := usecase.LoginInput{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:     req.Email,
+		Password:  req.Password,
+		IPAddress: middleware.ClientIP(r),
+		UserAgent: r.UserAgent(),
 	}
 
 	output, err := h.useCase.Login(r.Context(), input)
@@ -92,6 +118,8 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		switch err {
 		case usecase.ErrInvalidCredentials:
 			Error(w, http.StatusUnauthorized, err.Error())
+		case entity.ErrAccountLocked:
+			Error(w, http.StatusTooManyRequests, err.Error())
 		case usecase.ErrUserNotActive:
 			Error(w, http.StatusForbidden, err.Error())
 		default:
@@ -100,9 +128,81 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if output.MFARequired {
+		Success(w, http.StatusOK, "Verifikasi MFA diperlukan", output)
+		return
+	}
+
 	Success(w, http.StatusOK, "Login berhasil", output)
 }
 
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		if validationErrs, ok := err.(validator.ValidationErrors); ok {
+			ValidationError(w, validationErrs)
+			return
+		}
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input := usecase.RefreshInput{
+		RefreshToken: req.RefreshToken,
+		IPAddress:    middleware.ClientIP(r),
+		UserAgent:    r.UserAgent(),
+	}
+
+	output, err := h.useCase.Refresh(r.Context(), input)
+	if err != nil {
+		switch err {
+		case usecase.ErrInvalidRefreshToken:
+			Error(w, http.StatusUnauthorized, err.Error())
+		case usecase.ErrUserNotActive:
+			Error(w, http.StatusForbidden, err.Error())
+		default:
+			Error(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	Success(w, http.StatusOK, "Token berhasil diperbarui", output)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		Error(w, http.StatusUnauthorized, "User ID tidak ditemukan")
+		return
+	}
+
+	var req LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err := h.useCase.Logout(r.Context(), usecase.LogoutInput{
+		RefreshToken: req.RefreshToken,
+		UserID:       userID,
+		Email:        middleware.GetEmail(r.Context()),
+		Role:         middleware.GetRole(r.Context()),
+		IPAddress:    middleware.ClientIP(r),
+		UserAgent:    r.UserAgent(),
+	})
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(w, http.StatusOK, "Logout berhasil", nil)
+}
+
 func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	if userID == "" {
@@ -118,3 +218,80 @@ func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 
 	Success(w, http.StatusOK, "Berhasil", output)
 }
+
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		Error(w, http.StatusUnauthorized, "User ID tidak ditemukan")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		if validationErrs, ok := err.(validator.ValidationErrors); ok {
+			ValidationError(w, validationErrs)
+			return
+		}
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input := usecase.ChangePasswordInput{
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	}
+
+	if err := h.useCase.ChangePassword(r.Context(), userID, input); err != nil {
+		switch err {
+		case usecase.ErrUserNotFound:
+			Error(w, http.StatusNotFound, err.Error())
+		case usecase.ErrInvalidCredentials:
+			Error(w, http.StatusUnauthorized, err.Error())
+		default:
+			Error(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	Success(w, http.StatusOK, "Password berhasil diubah", nil)
+}
+
+func (h *AuthHandler) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	var req UnlockAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		if validationErrs, ok := err.(validator.ValidationErrors); ok {
+			ValidationError(w, validationErrs)
+			return
+		}
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input := usecase.UnlockAccountInput{
+		Email:     req.Email,
+		ActorID:   middleware.GetUserID(r.Context()),
+		ActorRole: middleware.GetRole(r.Context()),
+		IPAddress: middleware.ClientIP(r),
+	}
+
+	if err := h.useCase.UnlockAccount(r.Context(), input); err != nil {
+		if err == usecase.ErrLockoutDisabled {
+			Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(w, http.StatusOK, "Akun berhasil dibuka", nil)
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/pkg/jwt"
+)
+
+// registerUserRepository menyimpan user hasil registrasi di memory
+type registerUserRepository struct {
+	repository.UserRepository
+	users []*entity.User
+}
+
+func (r *registerUserRepository) Create(ctx context.Context, user *entity.User) error {
+	user.ID = "user-1"
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *registerUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return nil, errors.New("user tidak ditemukan")
+}
+
+func TestRegisterIgnoresRole(t *testing.T) {
+	for _, role := range []string{"", "admin", "manager", "custom-role"} {
+		t.Run(role, func(t *testing.T) {
+			users := &registerUserRepository{}
+			jwtManager := jwt.NewJWTManager("secret", time.Hour)
+			handler := NewAuthHandler(usecase.NewAuthUseCase(users, jwtManager, nil, entity.PasswordPolicy{}, nil, nil, usecase.MFAConfig{}))
+
+			body := `{"email":"budi@example.com","password":"rahasia123","nama":"Budi","role":"` + role + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			handler.Register(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+			}
+			if len(users.users) != 1 || users.users[0].Role != entity.RoleEmployee {
+				t.Fatalf("registered users = %+v, want one employee", users.users)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/okinn/service-presensi/internal/adapter/inbound/http/middleware"
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/pkg/validator"
+)
+
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type MFAChallengeEnrollRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// VerifyMFAChallenge menukar token challenge + kode MFA dengan access token
+// POST /api/auth/mfa/challenge
+func (h *AuthHandler) VerifyMFAChallenge(w http.ResponseWriter, r *http.Request) {
+	var req MFAChallengeRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.VerifyMFAChallenge(r.Context(), usecase.MFAChallengeInput{
+		MFAToken:  req.MFAToken,
+		Code:      req.Code,
+		IPAddress: middleware.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Login berhasil", output)
+}
+
+// EnrollMFAWithChallenge mendaftarkan MFA saat login untuk user yang wajib MFA tapi belum mendaftar
+// POST /api/auth/mfa/challenge/enroll
+func (h *AuthHandler) EnrollMFAWithChallenge(w http.ResponseWriter, r *http.Request) {
+	var req MFAChallengeEnrollRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.EnrollMFAWithChallenge(r.Context(), req.MFAToken)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Scan secret dengan authenticator app lalu verifikasi kode", output)
+}
+
+// EnrollMFA membuat secret TOTP baru untuk user yang sedang login
+// POST /api/auth/mfa/enroll
+func (h *AuthHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	output, err := h.useCase.EnrollMFA(r.Context(), middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Scan secret dengan authenticator app lalu aktifkan dengan kode", output)
+}
+
+// ActivateMFA mengaktifkan MFA dengan kode pertama dari authenticator app
+// POST /api/auth/mfa/activate
+func (h *AuthHandler) ActivateMFA(w http.ResponseWriter, r *http.Request) {
+	var req MFACodeRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.ActivateMFA(r.Context(), middleware.GetUserID(r.Context()), req.Code)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "MFA berhasil diaktifkan, simpan recovery codes di tempat aman", output)
+}
+
+// DisableMFA menonaktifkan MFA
+// POST /api/auth/mfa/disable
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	var req DisableMFARequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	err := h.useCase.DisableMFA(r.Context(), middleware.GetUserID(r.Context()), usecase.DisableMFAInput{
+		Password: req.Password,
+		Code:     req.Code,
+	})
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "MFA berhasil dinonaktifkan", nil)
+}
+
+// RegenerateRecoveryCodes membuat recovery codes baru, kode lama tidak berlaku lagi
+// POST /api/auth/mfa/recovery-codes
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var req MFACodeRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.RegenerateRecoveryCodes(r.Context(), middleware.GetUserID(r.Context()), req.Code)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Recovery codes berhasil dibuat ulang", output)
+}
+
+func writeMFAError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrInvalidMFAChallenge, usecase.ErrInvalidCredentials, entity.ErrInvalidMFACode:
+		Error(w, http.StatusUnauthorized, err.Error())
+	case usecase.ErrUserNotActive, usecase.ErrMFARequired:
+		Error(w, http.StatusForbidden, err.Error())
+	case usecase.ErrUserNotFound:
+		Error(w, http.StatusNotFound, err.Error())
+	case entity.ErrAccountLocked:
+		Error(w, http.StatusTooManyRequests, err.Error())
+	case entity.ErrMFAAlreadyEnabled, entity.ErrMFANotEnabled, entity.ErrMFANotEnrolled:
+		Error(w, http.StatusConflict, err.Error())
+	default:
+		Error(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// decodeAndValidate membaca JSON body dan menjalankan validasi, menulis response error jika gagal
+func decodeAndValidate(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		Error(w, http.StatusBadRequest, "Invalid request body")
+		return false
+	}
+
+	if err := validator.Validate(req); err != nil {
+		if validationErrs, ok := err.(validator.ValidationErrors); ok {
+			ValidationError(w, validationErrs)
+			return false
+		}
+		Error(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	return true
+}
@@ -31,6 +31,17 @@ const (
 	ContextKeyRequestBody AuditContextKey = "request_body"
 )
 
+// setAuditActor records the authenticated caller on the request's audit actor, if audit is active.
+// The audit middleware wraps the router, so the values AuthMiddleware adds to its own copy of
+// the request context are not visible there.
+func setAuditActor(ctx context.Context, userID, userName, role string) {
+	if actor := entity.AuditActorFromContext(ctx); actor != nil {
+		actor.UserID = userID
+		actor.UserName = userName
+		actor.UserRole = role
+	}
+}
+
 // AuditMiddleware creates middleware that logs all API requests for audit trail
 type AuditMiddleware struct {
 	auditRepo repository.AuditLogRepository
@@ -43,7 +54,7 @@ func NewAuditMiddleware(auditRepo repository.AuditLogRepository) *AuditMiddlewar
 	}
 }
 
-// auditResponseWriter wraps http.ResponseWriter to capture status code and response
+// auditResponseWriter wraps http.ResponseWriter to capture status code and error response
 type auditResponseWriter struct {
 	http.ResponseWriter
 	statusCode   int
@@ -63,11 +74,19 @@ func (w *auditResponseWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Write keeps only error responses, which are small, so streamed responses are not buffered
 func (w *auditResponseWriter) Write(data []byte) (int, error) {
-	w.responseBody.Write(data)
+	if w.statusCode >= 400 {
+		w.responseBody.Write(data)
+	}
 	return w.ResponseWriter.Write(data)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush a streamed response
+func (w *auditResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Audit returns the audit middleware handler
 func (m *AuditMiddleware) Audit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -90,6 +109,12 @@ func (m *AuditMiddleware) Audit(next http.Handler) http.Handler {
 		// Add request ID and body to context
 		ctx := context.WithValue(r.Context(), ContextKeyRequestID, requestID)
 		ctx = context.WithValue(ctx, ContextKeyRequestBody, string(requestBody))
+		actor := &entity.AuditActor{
+			IPAddress: ClientIP(r),
+			UserAgent: r.UserAgent(),
+			RequestID: requestID,
+		}
+		ctx = entity.ContextWithAuditActor(ctx, actor)
 		r = r.WithContext(ctx)
 
 		// Wrap response writer
@@ -104,8 +129,13 @@ func (m *AuditMiddleware) Audit(next http.Handler) http.Handler {
 		// Calculate duration
 		duration := time.Since(startTime).Milliseconds()
 
-		// Create audit log asynchronously
-		go m.createAuditLog(r, requestBody, wrappedWriter, requestID, duration)
+		// Audited repositories already recorded the change with its before and after state
+		if actor.Recorded() {
+			return
+		}
+
+		// The audit repository only queues the entry, the request is not delayed by the write
+		m.createAuditLog(r, requestBody, wrappedWriter, requestID, duration)
 	})
 }
 
@@ -135,7 +165,7 @@ func (m *AuditMiddleware) createAuditLog(
 		userID,
 		userName,
 		userRole,
-		getClientIP(r),
+		ClientIP(r),
 	)
 
 	// Set request info
@@ -155,10 +185,10 @@ func (m *AuditMiddleware) createAuditLog(
 		auditLog.SetError(extractErrorFromResponse(w.responseBody.Bytes()))
 	}
 
-	// Save to database
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 5*time.Second)
 	defer cancel()
 
+	// Entries that cannot be queued are spilled or logged by the repository
 	m.auditRepo.Create(ctx, auditLog)
 }
 
@@ -224,20 +254,21 @@ func determineAction(method string) entity.AuditAction {
 }
 
 func getUserFromContext(ctx context.Context) (userID, userName, userRole string) {
-	// These should be set by auth middleware
-	if id := ctx.Value("user_id"); id != nil {
-		userID = id.(string)
-	}
-	if name := ctx.Value("user_name"); name != nil {
-		userName = name.(string)
+	// Set by auth middleware when audit runs inside it
+	userID, userName, userRole = GetUserID(ctx), GetEmail(ctx), GetRole(ctx)
+	if userID != "" {
+		return
 	}
-	if role := ctx.Value("user_role"); role != nil {
-		userRole = role.(string)
+
+	// Audit wraps the router: auth middleware reports the caller through the actor
+	if actor := entity.AuditActorFromContext(ctx); actor != nil {
+		return actor.UserID, actor.UserName, actor.UserRole
 	}
 	return
 }
 
-func getClientIP(r *http.Request) string {
+// ClientIP returns the client IP address, honouring proxy headers
+func ClientIP(r *http.Request) string {
 	// Check X-Forwarded-For header (for proxies)
 	xff := r.Header.Get("X-Forwarded-For")
 	if xff != "" {
@@ -259,28 +290,53 @@ func getClientIP(r *http.Request) string {
 	return ip
 }
 
+// sensitiveKeyParts are matched against every key of the body, at any depth, so
+// old_password, refresh_token, mfa_token, client_secret and recovery_codes are redacted too
+var sensitiveKeyParts = []string{"password", "token", "secret", "code", "key", "credit_card"}
+
 func sanitizeRequestBody(body []byte) string {
 	if len(body) == 0 {
 		return ""
 	}
 
 	// Parse JSON
-	var data map[string]interface{}
+	var data interface{}
 	if err := json.Unmarshal(body, &data); err != nil {
 		return "[non-json body]"
 	}
 
-	// Remove sensitive fields
-	sensitiveFields := []string{"password", "token", "secret", "api_key", "credit_card"}
-	for _, field := range sensitiveFields {
-		if _, exists := data[field]; exists {
-			data[field] = "[REDACTED]"
+	// Re-serialize without sensitive fields
+	sanitized, _ := json.Marshal(redactSensitive(data))
+	return string(sanitized)
+}
+
+// redactSensitive replaces the values of sensitive keys in nested objects and arrays
+func redactSensitive(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveKey(key) {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			v[key] = redactSensitive(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactSensitive(item)
 		}
 	}
+	return value
+}
 
-	// Re-serialize
-	sanitized, _ := json.Marshal(data)
-	return string(sanitized)
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
 }
 
 func extractErrorFromResponse(body []byte) string {
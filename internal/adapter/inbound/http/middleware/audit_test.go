@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// capturedAuditLogs menyimpan entri yang dibuat audit middleware
+type capturedAuditLogs struct {
+	repository.AuditLogRepository
+	logs []*entity.AuditLog
+}
+
+func (c *capturedAuditLogs) Create(ctx context.Context, auditLog *entity.AuditLog) error {
+	c.logs = append(c.logs, auditLog)
+	return nil
+}
+
+func TestAuditRedactsCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    map[string]interface{}
+		secrets []string
+	}{
+		{
+			name:    "password change",
+			method:  http.MethodPut,
+			path:    "/api/auth/password",
+			body:    map[string]interface{}{"old_password": "Old-Secret-1", "new_password": "New-Secret-2"},
+			secrets: []string{"Old-Secret-1", "New-Secret-2"},
+		},
+		{
+			name:    "refresh",
+			method:  http.MethodPost,
+			path:    "/api/auth/refresh",
+			body:    map[string]interface{}{"refresh_token": "refresh.jwt.value"},
+			secrets: []string{"refresh.jwt.value"},
+		},
+		{
+			name:    "MFA challenge",
+			method:  http.MethodPost,
+			path:    "/api/auth/mfa/challenge",
+			body:    map[string]interface{}{"mfa_token": "challenge.jwt.value", "code": "123456"},
+			secrets: []string{"challenge.jwt.value", "123456"},
+		},
+		{
+			name:    "MFA disable",
+			method:  http.MethodPost,
+			path:    "/api/auth/mfa/disable",
+			body:    map[string]interface{}{"password": "Current-Secret-3", "code": "ABCD-EFGH"},
+			secrets: []string{"Current-Secret-3", "ABCD-EFGH"},
+		},
+		{
+			name:   "nested values",
+			method: http.MethodPost,
+			path:   "/api/users/import",
+			body: map[string]interface{}{
+				"users":          []interface{}{map[string]interface{}{"email": "budi@example.com", "Password": "Nested-Secret-4"}},
+				"recovery_codes": []interface{}{"RC-1111", "RC-2222"},
+				"settings":       map[string]interface{}{"client_secret": "Client-Secret-5", "api_key": "pk_live_6"},
+			},
+			secrets: []string{"Nested-Secret-4", "RC-1111", "RC-2222", "Client-Secret-5", "pk_live_6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &capturedAuditLogs{}
+			handler := NewAuditMiddleware(repo).Audit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"request tidak valid"}`))
+			}))
+
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("marshal body: %v", err)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(string(body)))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(repo.logs) != 1 {
+				t.Fatalf("audit logs = %d, want 1", len(repo.logs))
+			}
+			stored, err := json.Marshal(repo.logs[0])
+			if err != nil {
+				t.Fatalf("marshal audit log: %v", err)
+			}
+			for _, secret := range tt.secrets {
+				if strings.Contains(string(stored), secret) {
+					t.Errorf("audit log contains %q: %s", secret, stored)
+				}
+			}
+			if !strings.Contains(repo.logs[0].NewValue, "[REDACTED]") {
+				t.Errorf("new value = %s, want redacted fields", repo.logs[0].NewValue)
+			}
+		})
+	}
+}
+
+func TestSanitizeRequestBodyKeepsOtherFields(t *testing.T) {
+	got := sanitizeRequestBody([]byte(`{"nama":"Budi","status":"hadir","items":[{"keterangan":"rapat"}]}`))
+	want := `{"items":[{"keterangan":"rapat"}],"nama":"Budi","status":"hadir"}`
+	if got != want {
+		t.Errorf("sanitizeRequestBody = %s, want %s", got, want)
+	}
+	if got := sanitizeRequestBody([]byte("nama=Budi")); got != "[non-json body]" {
+		t.Errorf("sanitizeRequestBody(form) = %s, want [non-json body]", got)
+	}
+}
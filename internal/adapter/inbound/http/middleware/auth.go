@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/okinn/service-presensi/internal/domain/entity"
 	"github.com/okinn/service-presensi/pkg/httputil"
 	"github.com/okinn/service-presensi/pkg/jwt"
 )
@@ -12,18 +13,40 @@ import (
 type contextKey string
 
 const (
-	UserIDKey contextKey = "user_id"
-	EmailKey  contextKey = "email"
-	RoleKey   contextKey = "role"
+	UserIDKey   contextKey = "user_id"
+	EmailKey    contextKey = "email"
+	RoleKey     contextKey = "role"
+	APIKeyIDKey contextKey = "api_key_id"
 )
 
+// APIKeyHeader adalah header untuk autentikasi machine-to-machine
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator memvalidasi API key plaintext
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*entity.APIKey, error)
+}
+
+// PermissionChecker mengecek apakah role (bawaan atau custom) memiliki permission
+// dan mengembalikan role user yang tersimpan
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role string, permission entity.Permission) (bool, error)
+	UserRole(ctx context.Context, userID string) (string, error)
+}
+
 type AuthMiddleware struct {
-	jwtManager *jwt.JWTManager
+	jwtManager  *jwt.JWTManager
+	apiKeys     APIKeyAuthenticator
+	permissions PermissionChecker
 }
 
-func NewAuthMiddleware(jwtManager *jwt.JWTManager) *AuthMiddleware {
+// NewAuthMiddleware creates the auth middleware, apiKeys may be nil to disable API key auth.
+// Without permissions the role is taken from the JWT and RequirePermission must not be used.
+func NewAuthMiddleware(jwtManager *jwt.JWTManager, apiKeys APIKeyAuthenticator, permissions PermissionChecker) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtManager: jwtManager,
+		jwtManager:  jwtManager,
+		apiKeys:     apiKeys,
+		permissions: permissions,
 	}
 }
 
@@ -47,14 +70,60 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
+		// Hanya access token milik user yang boleh dipakai
+		if claims.TokenType != jwt.AccessToken || claims.UserID == "" {
+			httputil.Error(w, http.StatusUnauthorized, jwt.ErrInvalidToken.Error())
+			return
+		}
+
+		// Role di JWT bisa sudah diganti, role yang tersimpan dipakai untuk permission dan scope data
+		role := claims.Role
+		if m.permissions != nil {
+			role, err = m.permissions.UserRole(r.Context(), claims.UserID)
+			if err != nil {
+				httputil.Error(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+		}
+
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, EmailKey, claims.Email)
-		ctx = context.WithValue(ctx, RoleKey, claims.Role)
+		ctx = context.WithValue(ctx, RoleKey, role)
+		setAuditActor(ctx, claims.UserID, claims.Email, role)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// AuthenticateWithScope menerima Bearer token atau API key di header X-API-Key.
+// API key hanya diterima jika memiliki scope yang diminta, route tanpa scope tetap hanya menerima JWT.
+func (m *AuthMiddleware) AuthenticateWithScope(scope entity.APIKeyScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawKey := r.Header.Get(APIKeyHeader)
+			if rawKey == "" || m.apiKeys == nil {
+				m.Authenticate(next).ServeHTTP(w, r)
+				return
+			}
+
+			apiKey, err := m.apiKeys.Authenticate(r.Context(), rawKey)
+			if err != nil {
+				httputil.Error(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			if !apiKey.HasScope(scope) {
+				httputil.Error(w, http.StatusForbidden, "API key tidak memiliki scope "+string(scope))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), APIKeyIDKey, apiKey.ID)
+			setAuditActor(ctx, "", "api_key:"+apiKey.ID, "")
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func (m *AuthMiddleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -76,6 +145,33 @@ func (m *AuthMiddleware) RequireRole(roles ...string) func(http.Handler) http.Ha
 	}
 }
 
+// RequirePermission hanya meneruskan request jika role user memiliki permission.
+// Role dibaca dari user yang tersimpan (lihat Authenticate), permission-nya di-resolve saat request
+// sehingga perubahan role user maupun custom role berlaku tanpa menunggu token kadaluarsa.
+func (m *AuthMiddleware) RequirePermission(permission entity.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userRole, ok := r.Context().Value(RoleKey).(string)
+			if !ok {
+				httputil.Error(w, http.StatusForbidden, "Role tidak ditemukan")
+				return
+			}
+
+			allowed, err := m.permissions.HasPermission(r.Context(), userRole, permission)
+			if err != nil {
+				httputil.Error(w, http.StatusInternalServerError, "Gagal memeriksa permission")
+				return
+			}
+			if !allowed {
+				httputil.Error(w, http.StatusForbidden, "Akses ditolak")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // Helper functions untuk mendapatkan data dari context
 func GetUserID(ctx context.Context) string {
 	if userID, ok := ctx.Value(UserIDKey).(string); ok {
@@ -97,3 +193,10 @@ func GetRole(ctx context.Context) string {
 	}
 	return ""
 }
+
+func GetAPIKeyID(ctx context.Context) string {
+	if apiKeyID, ok := ctx.Value(APIKeyIDKey).(string); ok {
+		return apiKeyID
+	}
+	return ""
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/pkg/jwt"
+)
+
+func TestAuthenticate(t *testing.T) {
+	const secret = "secret"
+	manager := jwt.NewJWTManager(secret, time.Hour)
+
+	// signed membuat token dengan secret yang sama tetapi klaim bebas
+	signed := func(claims jwt.Claims) string {
+		claims.ExpiresAt = gojwt.NewNumericDate(time.Now().Add(time.Hour))
+		token, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, &claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return token
+	}
+	generated := func(token string, err error) string {
+		if err != nil {
+			t.Fatalf("generate token: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"access token", generated(manager.GenerateToken("user-1", "budi@example.com", "employee")), http.StatusOK},
+		{"refresh token", generated(manager.GenerateRefreshToken("user-1", "budi@example.com", "employee")), http.StatusUnauthorized},
+		{"MFA challenge token", generated(manager.GenerateMFAChallengeToken("user-1", "budi@example.com", "employee", time.Minute)), http.StatusUnauthorized},
+		{"invitation token", generated(manager.GenerateInvitationToken("user-1", "budi@example.com", time.Hour)), http.StatusUnauthorized},
+		{"OIDC state token", generated(manager.GenerateOIDCStateToken("state", "nonce", "verifier", time.Minute)), http.StatusUnauthorized},
+		{"token without type", signed(jwt.Claims{UserID: "user-1", Role: "admin"}), http.StatusUnauthorized},
+		{"access token without user", signed(jwt.Claims{Role: "admin", TokenType: jwt.AccessToken}), http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userID string
+			handler := NewAuthMiddleware(manager, nil, nil).Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				userID = GetUserID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/presensi", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && userID != "user-1" {
+				t.Errorf("user ID = %q, want user-1", userID)
+			}
+		})
+	}
+}
+
+// storedRoles adalah PermissionChecker dengan role user yang tersimpan
+type storedRoles map[string]string
+
+func (s storedRoles) HasPermission(ctx context.Context, role string, permission entity.Permission) (bool, error) {
+	builtIn, ok := entity.BuiltInRole(role)
+	return ok && builtIn.HasPermission(permission), nil
+}
+
+func (s storedRoles) UserRole(ctx context.Context, userID string) (string, error) {
+	role, ok := s[userID]
+	if !ok {
+		return "", errors.New("user tidak ditemukan")
+	}
+	return role, nil
+}
+
+func TestRequirePermissionUsesStoredRole(t *testing.T) {
+	manager := jwt.NewJWTManager("secret", time.Hour)
+	auth := NewAuthMiddleware(manager, nil, storedRoles{"admin-1": "admin", "demoted-1": "employee"})
+	handler := auth.Authenticate(auth.RequirePermission(entity.PermissionUserManage)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+	))
+
+	tests := []struct {
+		name   string
+		userID string
+		want   int
+	}{
+		{"admin", "admin-1", http.StatusOK},
+		{"admin token of a demoted user", "demoted-1", http.StatusForbidden},
+		{"admin token of a deleted user", "deleted-1", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := manager.GenerateToken(tt.userID, "budi@example.com", "admin")
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/users/user-2/role", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+// keyAuthenticator menerima API key yang terdaftar dengan scope-nya
+type keyAuthenticator map[string]*entity.APIKey
+
+func (k keyAuthenticator) Authenticate(ctx context.Context, rawKey string) (*entity.APIKey, error) {
+	apiKey, ok := k[rawKey]
+	if !ok {
+		return nil, entity.ErrInvalidAPIKey
+	}
+	return apiKey, nil
+}
+
+func TestAuthenticateWithScope(t *testing.T) {
+	manager := jwt.NewJWTManager("secret", time.Hour)
+	keys := keyAuthenticator{
+		"psk_reader_secret":    {ID: "key-1", Scopes: []entity.APIKeyScope{entity.ScopePresensiRead}},
+		"psk_analytics_secret": {ID: "key-2", Scopes: []entity.APIKeyScope{entity.ScopeAnalyticsRead}},
+	}
+	token, err := manager.GenerateToken("user-1", "budi@example.com", "employee")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		bearer    string
+		want      int
+		wantKeyID string
+	}{
+		{"key with scope", "psk_reader_secret", "", http.StatusOK, "key-1"},
+		{"key without scope", "psk_analytics_secret", "", http.StatusForbidden, ""},
+		{"unknown key", "psk_unknown_secret", "", http.StatusUnauthorized, ""},
+		{"unknown key with a valid token", "psk_unknown_secret", token, http.StatusUnauthorized, ""},
+		{"bearer token", "", token, http.StatusOK, ""},
+		{"no credentials", "", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keyID, userID string
+			handler := NewAuthMiddleware(manager, keys, nil).AuthenticateWithScope(entity.ScopePresensiRead)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					keyID = GetAPIKeyID(r.Context())
+					userID = GetUserID(r.Context())
+				}),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/presensi", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if keyID != tt.wantKeyID {
+				t.Errorf("API key ID = %q, want %q", keyID, tt.wantKeyID)
+			}
+			// Request dengan API key tidak pernah mendapat identitas user
+			if tt.apiKey != "" && userID != "" {
+				t.Errorf("user ID = %q for an API key request", userID)
+			}
+		})
+	}
+}
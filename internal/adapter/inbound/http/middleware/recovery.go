@@ -13,6 +13,11 @@ func Recovery(logger *slog.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
+					// Handlers abort a response that is already streaming; the server closes the connection
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
+
 					logger.Error("Panic recovered",
 						slog.Any("error", err),
 						slog.String("method", r.Method),
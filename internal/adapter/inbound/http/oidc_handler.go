@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+const (
+	oidcFlowCookie     = "oidc_flow"
+	oidcFlowCookiePath = "/api/auth/oidc"
+)
+
+// OIDCHandler handles single sign-on login through an OpenID Connect provider
+type OIDCHandler struct {
+	useCase      usecase.OIDCUseCase
+	secureCookie bool
+	flowDuration time.Duration
+}
+
+// NewOIDCHandler creates a new OIDC handler. secureCookie should be true when served over HTTPS.
+func NewOIDCHandler(uc usecase.OIDCUseCase, secureCookie bool, flowDuration time.Duration) *OIDCHandler {
+	return &OIDCHandler{
+		useCase:      uc,
+		secureCookie: secureCookie,
+		flowDuration: flowDuration,
+	}
+}
+
+// Login redirects the browser to the identity provider. State, nonce and PKCE verifier
+// are kept in a signed HttpOnly cookie that is only sent back to the callback.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	start, err := h.useCase.BeginLogin(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "Gagal memulai login SSO")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    start.StateToken,
+		Path:     oidcFlowCookiePath,
+		MaxAge:   int(h.flowDuration.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		// Lax agar cookie ikut terkirim pada redirect top-level dari identity provider
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, start.AuthURL, http.StatusFound)
+}
+
+// Callback exchanges the authorization code and returns the service's own token
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	// Cookie flow hanya boleh dipakai sekali
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    "",
+		Path:     oidcFlowCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		Error(w, http.StatusUnauthorized, "Login SSO ditolak: "+providerErr)
+		return
+	}
+
+	cookie, err := r.Cookie(oidcFlowCookie)
+	if err != nil {
+		Error(w, http.StatusBadRequest, usecase.ErrInvalidOIDCState.Error())
+		return
+	}
+
+	output, err := h.useCase.CompleteLogin(r.Context(), usecase.OIDCCallbackInput{
+		Code:       query.Get("code"),
+		State:      query.Get("state"),
+		StateToken: cookie.Value,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidOIDCState):
+			Error(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, usecase.ErrUserNotActive):
+			Error(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, usecase.ErrSSOUserNotAllowed),
+			errors.Is(err, usecase.ErrInvalidOIDCNonce),
+			errors.Is(err, entity.ErrEmailNotVerified),
+			errors.Is(err, entity.ErrInvalidEmail):
+			Error(w, http.StatusUnauthorized, err.Error())
+		default:
+			Error(w, http.StatusUnauthorized, "Login SSO gagal")
+		}
+		return
+	}
+
+	if output.MFARequired {
+		Success(w, http.StatusOK, "Verifikasi MFA diperlukan", output)
+		return
+	}
+
+	Success(w, http.StatusOK, "Login SSO berhasil", output)
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// OrganizationHandler handles HTTP requests for departments, teams and reporting lines
+type OrganizationHandler struct {
+	useCase usecase.OrganizationUseCase
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(uc usecase.OrganizationUseCase) *OrganizationHandler {
+	return &OrganizationHandler{useCase: uc}
+}
+
+type DepartmentRequest struct {
+	Name      string `json:"name" validate:"required,min=2,max=100"`
+	ParentID  string `json:"parent_id"`
+	ManagerID string `json:"manager_id"`
+}
+
+type TeamRequest struct {
+	Name         string `json:"name" validate:"required,min=2,max=100"`
+	DepartmentID string `json:"department_id" validate:"required"`
+	ManagerID    string `json:"manager_id"`
+}
+
+type AssignOrganizationRequest struct {
+	DepartmentID string `json:"department_id"`
+	TeamID       string `json:"team_id"`
+	ManagerID    string `json:"manager_id"`
+}
+
+// GetDepartments returns all departments
+func (h *OrganizationHandler) GetDepartments(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.useCase.GetDepartments(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(w, http.StatusOK, "Berhasil", outputs)
+}
+
+// CreateDepartment creates a department, optionally under a parent department
+func (h *OrganizationHandler) CreateDepartment(w http.ResponseWriter, r *http.Request) {
+	var req DepartmentRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.CreateDepartment(r.Context(), usecase.DepartmentInput{
+		Name:      req.Name,
+		ParentID:  req.ParentID,
+		ManagerID: req.ManagerID,
+	})
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	Success(w, http.StatusCreated, "Department berhasil dibuat", output)
+}
+
+// UpdateDepartment renames, moves or changes the manager of a department
+func (h *OrganizationHandler) UpdateDepartment(w http.ResponseWriter, r *http.Request) {
+	var req DepartmentRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.UpdateDepartment(r.Context(), r.PathValue("id"), usecase.DepartmentInput{
+		Name:      req.Name,
+		ParentID:  req.ParentID,
+		ManagerID: req.ManagerID,
+	})
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Department berhasil diupdate", output)
+}
+
+// DeleteDepartment removes an empty department
+func (h *OrganizationHandler) DeleteDepartment(w http.ResponseWriter, r *http.Request) {
+	if err := h.useCase.DeleteDepartment(r.Context(), r.PathValue("id")); err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Department berhasil dihapus", nil)
+}
+
+// GetTeams returns all teams
+func (h *OrganizationHandler) GetTeams(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.useCase.GetTeams(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(w, http.StatusOK, "Berhasil", outputs)
+}
+
+// CreateTeam creates a team within a department
+func (h *OrganizationHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	var req TeamRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.CreateTeam(r.Context(), usecase.TeamInput{
+		Name:         req.Name,
+		DepartmentID: req.DepartmentID,
+		ManagerID:    req.ManagerID,
+	})
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	Success(w, http.StatusCreated, "Team berhasil dibuat", output)
+}
+
+// UpdateTeam renames, moves or changes the manager of a team
+func (h *OrganizationHandler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	var req TeamRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.UpdateTeam(r.Context(), r.PathValue("id"), usecase.TeamInput{
+		Name:         req.Name,
+		DepartmentID: req.DepartmentID,
+		ManagerID:    req.ManagerID,
+	})
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Team berhasil diupdate", output)
+}
+
+// DeleteTeam removes an empty team
+func (h *OrganizationHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	if err := h.useCase.DeleteTeam(r.Context(), r.PathValue("id")); err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Team berhasil dihapus", nil)
+}
+
+// AssignUser places a user in a department and team and sets their manager
+func (h *OrganizationHandler) AssignUser(w http.ResponseWriter, r *http.Request) {
+	var req AssignOrganizationRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.AssignUser(r.Context(), r.PathValue("id"), usecase.AssignOrganizationInput{
+		DepartmentID: req.DepartmentID,
+		TeamID:       req.TeamID,
+		ManagerID:    req.ManagerID,
+	})
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Organisasi user berhasil diubah", output)
+}
+
+// GetTree returns the department hierarchy with teams and members
+func (h *OrganizationHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.useCase.GetTree(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(w, http.StatusOK, "Berhasil", tree)
+}
+
+func writeOrganizationError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrDepartmentNotFound, usecase.ErrTeamNotFound, usecase.ErrManagerNotFound, usecase.ErrUserNotFound:
+		Error(w, http.StatusNotFound, err.Error())
+	case usecase.ErrDepartmentNotEmpty, usecase.ErrTeamNotEmpty:
+		Error(w, http.StatusConflict, err.Error())
+	case entity.ErrInvalidDepartmentName, entity.ErrInvalidTeamName, entity.ErrTeamDepartmentRequired,
+		entity.ErrTeamDepartmentMismatch, entity.ErrDepartmentCycle, entity.ErrSelfManager, entity.ErrManagerCycle:
+		Error(w, http.StatusBadRequest, err.Error())
+	default:
+		Error(w, http.StatusInternalServerError, err.Error())
+	}
+}
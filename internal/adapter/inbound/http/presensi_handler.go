@@ -21,8 +21,7 @@ func NewPresensiHandler(uc usecase.PresensiUseCase) *PresensiHandler {
 }
 
 type CreatePresensiRequest struct {
-	UserID     string  `json:"user_id" validate:"required"`
-	Nama       string  `json:"nama" validate:"required,min=2,max=100"`
+	UserID     string  `json:"user_id"`
 	Status     string  `json:"status" validate:"required,status_presensi"`
 	Keterangan string  `json:"keterangan" validate:"max=500"`
 	Latitude   float64 `json:"latitude" validate:"omitempty,gte=-90,lte=90"`
@@ -53,7 +52,6 @@ func (h *PresensiHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	input := usecase.CreatePresensiInput{
 		UserID:     req.UserID,
-		Nama:       req.Nama,
 		Status:     req.Status,
 		Keterangan: req.Keterangan,
 		Latitude:   req.Latitude,
@@ -61,9 +59,16 @@ func (h *PresensiHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Alamat:     req.Alamat,
 	}
 
-	output, err := h.useCase.Create(r.Context(), input)
+	output, err := h.useCase.Create(r.Context(), actorFromRequest(r), input)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case usecase.ErrAccessDenied:
+			Error(w, http.StatusForbidden, err.Error())
+		case usecase.ErrUserNotFound:
+			Error(w, http.StatusNotFound, err.Error())
+		default:
+			Error(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -77,9 +82,13 @@ func (h *PresensiHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	output, err := h.useCase.GetByID(r.Context(), id)
+	output, err := h.useCase.GetByID(r.Context(), actorFromRequest(r), id)
 	if err != nil {
-		Error(w, http.StatusNotFound, err.Error())
+		if err == usecase.ErrPresensiNotFound {
+			Error(w, http.StatusNotFound, err.Error())
+			return
+		}
+		Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -99,9 +108,13 @@ func (h *PresensiHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 		limit = 10
 	}
 
+	unit := orgUnitFromRequest(r)
 	filter := repository.PresensiFilter{
-		UserID: query.Get("user_id"),
-		Status: valueobject.StatusPresensi(query.Get("status")),
+		UserID:       query.Get("user_id"),
+		Status:       valueobject.StatusPresensi(query.Get("status")),
+		DepartmentID: unit.DepartmentID,
+		TeamID:       unit.TeamID,
+		ManagerID:    unit.ManagerID,
 	}
 
 	if startDate := query.Get("start_date"); startDate != "" {
@@ -116,8 +129,12 @@ func (h *PresensiHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	outputs, total, err := h.useCase.GetAll(r.Context(), filter, page, limit)
+	outputs, total, err := h.useCase.GetAll(r.Context(), actorFromRequest(r), filter, page, limit)
 	if err != nil {
+		if err == usecase.ErrAccessDenied {
+			Error(w, http.StatusForbidden, err.Error())
+			return
+		}
 		Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -159,7 +176,7 @@ func (h *PresensiHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Keterangan: req.Keterangan,
 	}
 
-	output, err := h.useCase.Update(r.Context(), id, input)
+	output, err := h.useCase.Update(r.Context(), actorFromRequest(r), id, input)
 	if err != nil {
 		if err == usecase.ErrPresensiNotFound {
 			Error(w, http.StatusNotFound, err.Error())
@@ -179,7 +196,7 @@ func (h *PresensiHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.useCase.Delete(r.Context(), id)
+	err := h.useCase.Delete(r.Context(), actorFromRequest(r), id)
 	if err != nil {
 		if err == usecase.ErrPresensiNotFound {
 			Error(w, http.StatusNotFound, err.Error())
@@ -199,7 +216,7 @@ func (h *PresensiHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.useCase.CheckIn(r.Context(), id)
+	err := h.useCase.CheckIn(r.Context(), actorFromRequest(r), id)
 	if err != nil {
 		if err == usecase.ErrPresensiNotFound {
 			Error(w, http.StatusNotFound, err.Error())
@@ -219,7 +236,7 @@ func (h *PresensiHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.useCase.CheckOut(r.Context(), id)
+	err := h.useCase.CheckOut(r.Context(), actorFromRequest(r), id)
 	if err != nil {
 		if err == usecase.ErrPresensiNotFound {
 			Error(w, http.StatusNotFound, err.Error())
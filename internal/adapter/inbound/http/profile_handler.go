@@ -0,0 +1,94 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/okinn/service-presensi/internal/adapter/inbound/http/middleware"
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// UpdateProfileRequest berisi field yang boleh diubah user sendiri, field yang tidak dikirim tidak diubah
+type UpdateProfileRequest struct {
+	Nama     *string `json:"nama" validate:"omitempty,min=2,max=100"`
+	Phone    *string `json:"phone" validate:"omitempty,phone"`
+	PhotoRef *string `json:"photo_ref" validate:"omitempty,max=500"`
+}
+
+// UpdateEmployeeRequest berisi semua field profil termasuk data kepegawaian
+type UpdateEmployeeRequest struct {
+	UpdateProfileRequest
+	EmployeeNumber *string   `json:"employee_number" validate:"omitempty,employee_number"`
+	JobTitle       *string   `json:"job_title" validate:"omitempty,max=100"`
+	HireDate       *string   `json:"hire_date" validate:"omitempty,date"`
+	EmploymentType *string   `json:"employment_type" validate:"omitempty,employment_type"`
+	WorkDays       *[]string `json:"work_days"` // "mon".."sun", empty follows the company schedule
+}
+
+// UpdateProfile mengubah profil milik user yang sedang login
+// PUT /api/auth/profile
+func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		Error(w, http.StatusUnauthorized, "User ID tidak ditemukan")
+		return
+	}
+
+	var req UpdateProfileRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.UpdateProfile(r.Context(), userID, toUpdateProfileInput(req))
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Profil berhasil diupdate", output)
+}
+
+// UpdateEmployee mengubah profil dan data kepegawaian user lain
+// PUT /api/users/{id}/profile
+func (h *AuthHandler) UpdateEmployee(w http.ResponseWriter, r *http.Request) {
+	var req UpdateEmployeeRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.UpdateEmployee(r.Context(), r.PathValue("id"), usecase.UpdateEmployeeInput{
+		UpdateProfileInput: toUpdateProfileInput(req.UpdateProfileRequest),
+		EmployeeNumber:     req.EmployeeNumber,
+		JobTitle:           req.JobTitle,
+		HireDate:           req.HireDate,
+		EmploymentType:     req.EmploymentType,
+		WorkDays:           req.WorkDays,
+	})
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Profil karyawan berhasil diupdate", output)
+}
+
+func toUpdateProfileInput(req UpdateProfileRequest) usecase.UpdateProfileInput {
+	return usecase.UpdateProfileInput{
+		Nama:     req.Nama,
+		Phone:    req.Phone,
+		PhotoRef: req.PhotoRef,
+	}
+}
+
+func writeProfileError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrUserNotFound:
+		Error(w, http.StatusNotFound, err.Error())
+	case usecase.ErrEmployeeNumberExists:
+		Error(w, http.StatusConflict, err.Error())
+	case usecase.ErrInvalidHireDate, entity.ErrInvalidName, entity.ErrInvalidEmploymentType, entity.ErrInvalidWeekday:
+		Error(w, http.StatusBadRequest, err.Error())
+	default:
+		Error(w, http.StatusInternalServerError, err.Error())
+	}
+}
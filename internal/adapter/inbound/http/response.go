@@ -19,6 +19,10 @@ func Success(w http.ResponseWriter, status int, message string, data interface{}
 	httputil.Success(w, status, message, data)
 }
 
+func SuccessWithETag(w http.ResponseWriter, r *http.Request, message string, data interface{}) {
+	httputil.SuccessWithETag(w, r, message, data)
+}
+
 func SuccessWithMeta(w http.ResponseWriter, status int, message string, data interface{}, meta *Meta) {
 	httputil.SuccessWithMeta(w, status, message, data, meta)
 }
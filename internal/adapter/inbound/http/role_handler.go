@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// RoleHandler handles HTTP requests for roles, permissions and role assignment
+type RoleHandler struct {
+	useCase usecase.RoleUseCase
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(uc usecase.RoleUseCase) *RoleHandler {
+	return &RoleHandler{useCase: uc}
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description" validate:"max=200"`
+	Permissions []string `json:"permissions" validate:"dive,permission"`
+	DataScope   string   `json:"data_scope" validate:"data_scope"`
+}
+
+type UpdateRoleRequest struct {
+	Description string   `json:"description" validate:"max=200"`
+	Permissions []string `json:"permissions" validate:"dive,permission"`
+	DataScope   string   `json:"data_scope" validate:"data_scope"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// GetAll returns built-in and custom roles
+func (h *RoleHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	outputs, err := h.useCase.GetAll(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	Success(w, http.StatusOK, "Berhasil", outputs)
+}
+
+// GetPermissions returns every permission that can be granted to a role
+func (h *RoleHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	Success(w, http.StatusOK, "Berhasil", h.useCase.GetPermissions())
+}
+
+// Create defines a custom role
+func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRoleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.Create(r.Context(), usecase.RoleInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+		DataScope:   req.DataScope,
+	})
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	Success(w, http.StatusCreated, "Role berhasil dibuat", output)
+}
+
+// Update replaces the permissions of a custom role
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRoleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.Update(r.Context(), r.PathValue("name"), usecase.RoleInput{
+		Description: req.Description,
+		Permissions: req.Permissions,
+		DataScope:   req.DataScope,
+	})
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Role berhasil diupdate", output)
+}
+
+// Delete removes a custom role that is no longer assigned to any user
+func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.useCase.Delete(r.Context(), r.PathValue("name")); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Role berhasil dihapus", nil)
+}
+
+// AssignRole changes the role of a user
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	var req AssignRoleRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.AssignRole(r.Context(), r.PathValue("id"), req.Role)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	Success(w, http.StatusOK, "Role user berhasil diubah", output)
+}
+
+func writeRoleError(w http.ResponseWriter, err error) {
+	switch err {
+	case usecase.ErrRoleNotFound, usecase.ErrUserNotFound:
+		Error(w, http.StatusNotFound, err.Error())
+	case usecase.ErrRoleAlreadyExists, usecase.ErrRoleInUse, entity.ErrBuiltInRole:
+		Error(w, http.StatusConflict, err.Error())
+	case entity.ErrInvalidRoleName, entity.ErrInvalidPermission, entity.ErrInvalidDataScope:
+		Error(w, http.StatusBadRequest, err.Error())
+	default:
+		Error(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/okinn/service-presensi/internal/adapter/inbound/http/middleware"
+	"github.com/okinn/service-presensi/internal/domain/entity"
 )
 
 type RouterConfig struct {
@@ -13,6 +14,11 @@ type RouterConfig struct {
 	AuditHandler     *AuditHandler
 	LocationHandler  *LocationHandler
 	AnalyticsHandler *AnalyticsHandler
+	APIKeyHandler    *APIKeyHandler
+	OIDCHandler      *OIDCHandler
+	RoleHandler      *RoleHandler
+	OrgHandler       *OrganizationHandler
+	ImportHandler    *UserImportHandler
 	AuthMiddleware   *middleware.AuthMiddleware
 	AuditMiddleware  *middleware.AuditMiddleware
 	Logger           *slog.Logger
@@ -34,27 +40,87 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	mux.Handle("POST /api/auth/login", cfg.LoginRateLimiter.Limit(
 		http.HandlerFunc(cfg.AuthHandler.Login),
 	))
+	mux.Handle("POST /api/auth/refresh", cfg.LoginRateLimiter.Limit(
+		http.HandlerFunc(cfg.AuthHandler.Refresh),
+	))
+	mux.Handle("POST /api/auth/logout", cfg.AuthMiddleware.Authenticate(
+		http.HandlerFunc(cfg.AuthHandler.Logout),
+	))
+
+	// Single sign-on (public)
+	if cfg.OIDCHandler != nil {
+		mux.Handle("GET /api/auth/oidc/login", cfg.LoginRateLimiter.Limit(
+			http.HandlerFunc(cfg.OIDCHandler.Login),
+		))
+		mux.Handle("GET /api/auth/oidc/callback", cfg.LoginRateLimiter.Limit(
+			http.HandlerFunc(cfg.OIDCHandler.Callback),
+		))
+	}
 
 	// Profile route (protected)
 	mux.Handle("GET /api/auth/profile", cfg.AuthMiddleware.Authenticate(
 		http.HandlerFunc(cfg.AuthHandler.GetProfile),
 	))
+	mux.Handle("PUT /api/auth/profile", cfg.AuthMiddleware.Authenticate(
+		http.HandlerFunc(cfg.AuthHandler.UpdateProfile),
+	))
+	mux.Handle("PUT /api/auth/password", cfg.AuthMiddleware.Authenticate(
+		http.HandlerFunc(cfg.AuthHandler.ChangePassword),
+	))
+
+	// MFA login step (public, rate limited like login)
+	mux.Handle("POST /api/auth/mfa/challenge", cfg.LoginRateLimiter.Limit(
+		http.HandlerFunc(cfg.AuthHandler.VerifyMFAChallenge),
+	))
+	mux.Handle("POST /api/auth/mfa/challenge/enroll", cfg.LoginRateLimiter.Limit(
+		http.HandlerFunc(cfg.AuthHandler.EnrollMFAWithChallenge),
+	))
+
+	// MFA self-service (protected)
+	mux.Handle("POST /api/auth/mfa/enroll", cfg.AuthMiddleware.Authenticate(
+		http.HandlerFunc(cfg.AuthHandler.EnrollMFA),
+	))
+	mux.Handle("POST /api/auth/mfa/activate", cfg.AuthMiddleware.Authenticate(
+		http.HandlerFunc(cfg.AuthHandler.ActivateMFA),
+	))
+	mux.Handle("POST /api/auth/mfa/disable", cfg.AuthMiddleware.Authenticate(
+		http.HandlerFunc(cfg.AuthHandler.DisableMFA),
+	))
+	mux.Handle("POST /api/auth/mfa/recovery-codes", cfg.AuthMiddleware.Authenticate(
+		http.HandlerFunc(cfg.AuthHandler.RegenerateRecoveryCodes),
+	))
+
+	// Account lockout management (user.manage permission)
+	mux.Handle("POST /api/auth/unlock", cfg.AuthMiddleware.Authenticate(
+		cfg.AuthMiddleware.RequirePermission(entity.PermissionUserManage)(
+			http.HandlerFunc(cfg.AuthHandler.UnlockAccount),
+		),
+	))
+
+	// Employee data such as NIK and job title (user.manage permission)
+	mux.Handle("PUT /api/users/{id}/profile", cfg.AuthMiddleware.Authenticate(
+		cfg.AuthMiddleware.RequirePermission(entity.PermissionUserManage)(
+			http.HandlerFunc(cfg.AuthHandler.UpdateEmployee),
+		),
+	))
 
-	// Presensi routes (protected)
+	// Presensi routes (protected, reads also accept a presensi:read API key)
 	mux.Handle("POST /api/presensi", cfg.AuthMiddleware.Authenticate(
 		http.HandlerFunc(cfg.PresensiHandler.Create),
 	))
-	mux.Handle("GET /api/presensi", cfg.AuthMiddleware.Authenticate(
+	mux.Handle("GET /api/presensi", cfg.AuthMiddleware.AuthenticateWithScope(entity.ScopePresensiRead)(
 		http.HandlerFunc(cfg.PresensiHandler.GetAll),
 	))
-	mux.Handle("GET /api/presensi/{id}", cfg.AuthMiddleware.Authenticate(
+	mux.Handle("GET /api/presensi/{id}", cfg.AuthMiddleware.AuthenticateWithScope(entity.ScopePresensiRead)(
 		http.HandlerFunc(cfg.PresensiHandler.GetByID),
 	))
 	mux.Handle("PUT /api/presensi/{id}", cfg.AuthMiddleware.Authenticate(
-		http.HandlerFunc(cfg.PresensiHandler.Update),
+		cfg.AuthMiddleware.RequirePermission(entity.PermissionPresensiApprove)(
+			http.HandlerFunc(cfg.PresensiHandler.Update),
+		),
 	))
 	mux.Handle("DELETE /api/presensi/{id}", cfg.AuthMiddleware.Authenticate(
-		cfg.AuthMiddleware.RequireRole("admin")(
+		cfg.AuthMiddleware.RequirePermission(entity.PermissionPresensiDelete)(
 			http.HandlerFunc(cfg.PresensiHandler.Delete),
 		),
 	))
@@ -65,73 +131,187 @@ func NewRouter(cfg RouterConfig) http.Handler {
 		http.HandlerFunc(cfg.PresensiHandler.CheckOut),
 	))
 
-	// Audit routes (admin only)
+	// Audit routes (audit.read permission)
 	if cfg.AuditHandler != nil {
 		mux.Handle("GET /api/audit", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAuditRead)(
 				http.HandlerFunc(cfg.AuditHandler.GetAll),
 			),
 		))
+		mux.Handle("GET /api/audit/export", cfg.AuthMiddleware.Authenticate(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAuditRead)(
+				http.HandlerFunc(cfg.AuditHandler.Export),
+			),
+		))
+		mux.Handle("GET /api/audit/verify", cfg.AuthMiddleware.Authenticate(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAuditRead)(
+				http.HandlerFunc(cfg.AuditHandler.Verify),
+			),
+		))
 		mux.Handle("GET /api/audit/{id}", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAuditRead)(
 				http.HandlerFunc(cfg.AuditHandler.GetByID),
 			),
 		))
 		mux.Handle("GET /api/audit/entity", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAuditRead)(
 				http.HandlerFunc(cfg.AuditHandler.GetByEntity),
 			),
 		))
 		mux.Handle("GET /api/audit/user/{user_id}", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAuditRead)(
 				http.HandlerFunc(cfg.AuditHandler.GetByUser),
 			),
 		))
 	}
 
-	// Analytics routes (protected)
+	// Analytics routes (protected, also readable with an analytics:read API key)
 	if cfg.AnalyticsHandler != nil {
-		mux.Handle("GET /api/analytics/summary", cfg.AuthMiddleware.Authenticate(
+		analyticsAuth := cfg.AuthMiddleware.AuthenticateWithScope(entity.ScopeAnalyticsRead)
+
+		mux.Handle("GET /api/analytics/summary", analyticsAuth(
 			http.HandlerFunc(cfg.AnalyticsHandler.GetSummary),
 		))
-		mux.Handle("GET /api/analytics/daily", cfg.AuthMiddleware.Authenticate(
+		mux.Handle("GET /api/analytics/daily", analyticsAuth(
 			http.HandlerFunc(cfg.AnalyticsHandler.GetDailySummary),
 		))
-		mux.Handle("GET /api/analytics/monthly", cfg.AuthMiddleware.Authenticate(
+		mux.Handle("GET /api/analytics/monthly", analyticsAuth(
 			http.HandlerFunc(cfg.AnalyticsHandler.GetMonthlySummary),
 		))
-		mux.Handle("GET /api/analytics/user/{user_id}", cfg.AuthMiddleware.Authenticate(
+		mux.Handle("GET /api/analytics/user/{user_id}", analyticsAuth(
 			http.HandlerFunc(cfg.AnalyticsHandler.GetUserSummary),
 		))
-		mux.Handle("GET /api/analytics/status-breakdown", cfg.AuthMiddleware.Authenticate(
+		mux.Handle("GET /api/analytics/status-breakdown", analyticsAuth(
 			http.HandlerFunc(cfg.AnalyticsHandler.GetStatusBreakdown),
 		))
+		mux.Handle("GET /api/analytics/period", analyticsAuth(
+			http.HandlerFunc(cfg.AnalyticsHandler.GetPeriodSummary),
+		))
+		mux.Handle("GET /api/analytics/punctuality", analyticsAuth(
+			http.HandlerFunc(cfg.AnalyticsHandler.GetPunctuality),
+		))
+		mux.Handle("GET /api/analytics/hours", analyticsAuth(
+			http.HandlerFunc(cfg.AnalyticsHandler.GetWorkingHours),
+		))
+	}
+
+	// API key management (apikey.manage permission)
+	if cfg.APIKeyHandler != nil {
+		mux.Handle("POST /api/api-keys", cfg.AuthMiddleware.Authenticate(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAPIKeyManage)(
+				http.HandlerFunc(cfg.APIKeyHandler.Create),
+			),
+		))
+		mux.Handle("GET /api/api-keys", cfg.AuthMiddleware.Authenticate(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAPIKeyManage)(
+				http.HandlerFunc(cfg.APIKeyHandler.GetAll),
+			),
+		))
+		mux.Handle("DELETE /api/api-keys/{id}", cfg.AuthMiddleware.Authenticate(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionAPIKeyManage)(
+				http.HandlerFunc(cfg.APIKeyHandler.Revoke),
+			),
+		))
+	}
+
+	// Role and permission management
+	if cfg.RoleHandler != nil {
+		roleManage := cfg.AuthMiddleware.RequirePermission(entity.PermissionRoleManage)
+
+		mux.Handle("GET /api/roles", cfg.AuthMiddleware.Authenticate(
+			roleManage(http.HandlerFunc(cfg.RoleHandler.GetAll)),
+		))
+		mux.Handle("GET /api/roles/permissions", cfg.AuthMiddleware.Authenticate(
+			roleManage(http.HandlerFunc(cfg.RoleHandler.GetPermissions)),
+		))
+		mux.Handle("POST /api/roles", cfg.AuthMiddleware.Authenticate(
+			roleManage(http.HandlerFunc(cfg.RoleHandler.Create)),
+		))
+		mux.Handle("PUT /api/roles/{name}", cfg.AuthMiddleware.Authenticate(
+			roleManage(http.HandlerFunc(cfg.RoleHandler.Update)),
+		))
+		mux.Handle("DELETE /api/roles/{name}", cfg.AuthMiddleware.Authenticate(
+			roleManage(http.HandlerFunc(cfg.RoleHandler.Delete)),
+		))
+		mux.Handle("PUT /api/users/{id}/role", cfg.AuthMiddleware.Authenticate(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionUserManage)(
+				http.HandlerFunc(cfg.RoleHandler.AssignRole),
+			),
+		))
+	}
+
+	// Bulk user import (user.manage permission) and invitation acceptance
+	if cfg.ImportHandler != nil {
+		mux.Handle("POST /api/users/import", cfg.AuthMiddleware.Authenticate(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionUserManage)(
+				http.HandlerFunc(cfg.ImportHandler.Import),
+			),
+		))
+		mux.Handle("POST /api/auth/invitation/accept", cfg.LoginRateLimiter.Limit(
+			http.HandlerFunc(cfg.ImportHandler.AcceptInvitation),
+		))
+	}
+
+	// Departments, teams and reporting lines (changes and the member tree need org.manage permission)
+	if cfg.OrgHandler != nil {
+		orgManage := cfg.AuthMiddleware.RequirePermission(entity.PermissionOrgManage)
+
+		mux.Handle("GET /api/departments", cfg.AuthMiddleware.Authenticate(
+			http.HandlerFunc(cfg.OrgHandler.GetDepartments),
+		))
+		mux.Handle("POST /api/departments", cfg.AuthMiddleware.Authenticate(
+			orgManage(http.HandlerFunc(cfg.OrgHandler.CreateDepartment)),
+		))
+		mux.Handle("PUT /api/departments/{id}", cfg.AuthMiddleware.Authenticate(
+			orgManage(http.HandlerFunc(cfg.OrgHandler.UpdateDepartment)),
+		))
+		mux.Handle("DELETE /api/departments/{id}", cfg.AuthMiddleware.Authenticate(
+			orgManage(http.HandlerFunc(cfg.OrgHandler.DeleteDepartment)),
+		))
+		mux.Handle("GET /api/teams", cfg.AuthMiddleware.Authenticate(
+			http.HandlerFunc(cfg.OrgHandler.GetTeams),
+		))
+		mux.Handle("POST /api/teams", cfg.AuthMiddleware.Authenticate(
+			orgManage(http.HandlerFunc(cfg.OrgHandler.CreateTeam)),
+		))
+		mux.Handle("PUT /api/teams/{id}", cfg.AuthMiddleware.Authenticate(
+			orgManage(http.HandlerFunc(cfg.OrgHandler.UpdateTeam)),
+		))
+		mux.Handle("DELETE /api/teams/{id}", cfg.AuthMiddleware.Authenticate(
+			orgManage(http.HandlerFunc(cfg.OrgHandler.DeleteTeam)),
+		))
+		mux.Handle("PUT /api/users/{id}/organization", cfg.AuthMiddleware.Authenticate(
+			orgManage(http.HandlerFunc(cfg.OrgHandler.AssignUser)),
+		))
+		mux.Handle("GET /api/org/tree", cfg.AuthMiddleware.Authenticate(
+			orgManage(http.HandlerFunc(cfg.OrgHandler.GetTree)),
+		))
 	}
 
-	// Location routes (admin only) - Geofencing management
+	// Location routes (location.manage permission) - Geofencing management
 	if cfg.LocationHandler != nil {
 		mux.Handle("POST /api/locations", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionLocationManage)(
 				http.HandlerFunc(cfg.LocationHandler.Create),
 			),
 		))
 		mux.Handle("GET /api/locations", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionLocationManage)(
 				http.HandlerFunc(cfg.LocationHandler.GetAll),
 			),
 		))
 		mux.Handle("GET /api/locations/{id}", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionLocationManage)(
 				http.HandlerFunc(cfg.LocationHandler.GetByID),
 			),
 		))
 		mux.Handle("PUT /api/locations/{id}", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionLocationManage)(
 				http.HandlerFunc(cfg.LocationHandler.Update),
 			),
 		))
 		mux.Handle("DELETE /api/locations/{id}", cfg.AuthMiddleware.Authenticate(
-			cfg.AuthMiddleware.RequireRole("admin")(
+			cfg.AuthMiddleware.RequirePermission(entity.PermissionLocationManage)(
 				http.HandlerFunc(cfg.LocationHandler.Delete),
 			),
 		))
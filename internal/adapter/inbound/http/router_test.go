@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/adapter/inbound/http/middleware"
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/pkg/jwt"
+)
+
+// builtInRoles adalah PermissionChecker dengan role bawaan, role user diambil dari map
+type builtInRoles map[string]string
+
+func (r builtInRoles) HasPermission(ctx context.Context, role string, permission entity.Permission) (bool, error) {
+	builtIn, ok := entity.BuiltInRole(role)
+	return ok && builtIn.HasPermission(permission), nil
+}
+
+func (r builtInRoles) UserRole(ctx context.Context, userID string) (string, error) {
+	return r[userID], nil
+}
+
+// emptyOrganization hanya mengembalikan tree kosong
+type emptyOrganization struct {
+	usecase.OrganizationUseCase
+}
+
+func (emptyOrganization) GetTree(ctx context.Context) ([]entity.OrgTreeDepartment, error) {
+	return []entity.OrgTreeDepartment{}, nil
+}
+
+func TestOrgTreeRequiresOrgManage(t *testing.T) {
+	jwtManager := jwt.NewJWTManager("secret", time.Hour)
+	roles := builtInRoles{"admin-1": "admin", "manager-1": "manager", "employee-1": "employee"}
+	router := NewRouter(RouterConfig{
+		AuthHandler:      &AuthHandler{},
+		OrgHandler:       NewOrganizationHandler(emptyOrganization{}),
+		AuthMiddleware:   middleware.NewAuthMiddleware(jwtManager, nil, roles),
+		LoginRateLimiter: middleware.NewLoginRateLimiter(),
+	})
+
+	tests := []struct {
+		userID string
+		want   int
+	}{
+		{"admin-1", http.StatusOK},
+		{"manager-1", http.StatusForbidden},
+		{"employee-1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.userID, func(t *testing.T) {
+			token, err := jwtManager.GenerateToken(tt.userID, tt.userID+"@example.com", roles[tt.userID])
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/org/tree", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
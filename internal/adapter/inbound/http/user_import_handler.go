@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/okinn/service-presensi/internal/application/usecase"
+	"github.com/okinn/service-presensi/pkg/spreadsheet"
+)
+
+// maxImportFileSize membatasi ukuran file CSV/XLSX yang diupload
+const maxImportFileSize = 10 << 20
+
+// UserImportHandler handles bulk user import and invitation acceptance
+type UserImportHandler struct {
+	useCase usecase.UserImportUseCase
+}
+
+// NewUserImportHandler creates a new user import handler
+func NewUserImportHandler(uc usecase.UserImportUseCase) *UserImportHandler {
+	return &UserImportHandler{useCase: uc}
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Import membuat atau mengupdate user dari file CSV/XLSX (multipart field "file")
+// POST /api/users/import?dry_run=true&upsert=true&invite=true
+func (h *UserImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize+1<<20)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		Error(w, http.StatusBadRequest, "File terlalu besar atau request bukan multipart/form-data")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		Error(w, http.StatusBadRequest, "Field 'file' diperlukan")
+		return
+	}
+	defer file.Close()
+
+	// Satu baris tambahan untuk header
+	rows, err := spreadsheet.Read(header.Filename, file, header.Size, usecase.MaxImportRows+1)
+	if err != nil {
+		if err == spreadsheet.ErrTooManyRows {
+			err = usecase.ErrImportTooManyRows
+		}
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun, _ := strconv.ParseBool(query.Get("dry_run"))
+	upsert, _ := strconv.ParseBool(query.Get("upsert"))
+	invite, _ := strconv.ParseBool(query.Get("invite"))
+
+	output, err := h.useCase.Import(r.Context(), usecase.ImportUsersInput{
+		Rows:   rows,
+		DryRun: dryRun,
+		Upsert: upsert,
+		Invite: invite,
+	})
+	if err != nil {
+		switch err {
+		case usecase.ErrInvitationDisabled:
+			Error(w, http.StatusServiceUnavailable, err.Error())
+		default:
+			// Error per baris ada di output, error di sini berasal dari file atau header
+			Error(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	message := "Import selesai"
+	if dryRun {
+		message = "Validasi import selesai, tidak ada data yang disimpan"
+	}
+	Success(w, http.StatusOK, message, output)
+}
+
+// AcceptInvitation menentukan password pertama user yang diundang
+// POST /api/auth/invitation/accept
+func (h *UserImportHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req AcceptInvitationRequest
+	if !decodeAndValidate(w, r, &req) {
+		return
+	}
+
+	output, err := h.useCase.AcceptInvitation(r.Context(), req.Token, req.Password)
+	if err != nil {
+		// Token tidak valid atau password tidak memenuhi policy
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	Success(w, http.StatusOK, "Password berhasil dibuat, silakan login", output)
+}
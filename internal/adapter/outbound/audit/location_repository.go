@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package audit
+
+import (
+	"context"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// LocationRepository records every change to allowed locations in the audit log
+type LocationRepository struct {
+	repository.AllowedLocationRepository
+	recorder recorder
+}
+
+// NewLocationRepository wraps repo with audit logging
+func NewLocationRepository(repo repository.AllowedLocationRepository, auditRepo repository.AuditLogRepository) repository.AllowedLocationRepository {
+	return &LocationRepository{
+		AllowedLocationRepository: repo,
+		recorder:                  recorder{auditRepo: auditRepo, entityType: "locations"},
+	}
+}
+
+func (r *LocationRepository) Create(ctx context.Context, location *entity.AllowedLocation) error {
+	if err := r.AllowedLocationRepository.Create(ctx, location); err != nil {
+		return err
+	}
+	r.recorder.record(ctx, location.ID, entity.AuditActionCreate, nil, locationSnapshot(location), nil)
+	return nil
+}
+
+func (r *LocationRepository) Update(ctx context.Context, location *entity.AllowedLocation) error {
+	before, _ := r.AllowedLocationRepository.GetByID(ctx, location.ID)
+	if err := r.AllowedLocationRepository.Update(ctx, location); err != nil {
+		return err
+	}
+	r.recorder.recordUpdate(ctx, location.ID, locationSnapshot(before), locationSnapshot(location))
+	return nil
+}
+
+func (r *LocationRepository) Delete(ctx context.Context, id string) error {
+	before, _ := r.AllowedLocationRepository.GetByID(ctx, id)
+	if err := r.AllowedLocationRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.recorder.record(ctx, id, entity.AuditActionDelete, locationSnapshot(before), nil, nil)
+	return nil
+}
+
+func locationSnapshot(l *entity.AllowedLocation) snapshot {
+	if l == nil {
+		return nil
+	}
+
+	return snapshot{
+		"name":          l.Name,
+		"latitude":      l.Latitude,
+		"longitude":     l.Longitude,
+		"radius_meters": l.RadiusMeters,
+		"address":       l.Address,
+		"is_active":     l.IsActive,
+		"created_at":    formatTime(&l.CreatedAt),
+		"updated_at":    formatTime(&l.UpdatedAt),
+	}
+}
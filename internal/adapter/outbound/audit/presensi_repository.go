@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package audit
+
+import (
+	"context"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// PresensiRepository records every change to presensi in the audit log
+type PresensiRepository struct {
+	repository.PresensiRepository
+	recorder recorder
+}
+
+// NewPresensiRepository wraps repo with audit logging
+func NewPresensiRepository(repo repository.PresensiRepository, auditRepo repository.AuditLogRepository) repository.PresensiRepository {
+	return &PresensiRepository{
+		PresensiRepository: repo,
+		recorder:           recorder{auditRepo: auditRepo, entityType: "presensi"},
+	}
+}
+
+func (r *PresensiRepository) Create(ctx context.Context, presensi *entity.Presensi) error {
+	if err := r.PresensiRepository.Create(ctx, presensi); err != nil {
+		return err
+	}
+	r.recorder.record(ctx, presensi.ID, entity.AuditActionCreate, nil, presensiSnapshot(presensi), nil)
+	return nil
+}
+
+func (r *PresensiRepository) Update(ctx context.Context, presensi *entity.Presensi) error {
+	before, _ := r.PresensiRepository.GetByID(ctx, presensi.ID)
+	if err := r.PresensiRepository.Update(ctx, presensi); err != nil {
+		return err
+	}
+	r.recorder.recordUpdate(ctx, presensi.ID, presensiSnapshot(before), presensiSnapshot(presensi))
+	return nil
+}
+
+func (r *PresensiRepository) Delete(ctx context.Context, id string) error {
+	before, _ := r.PresensiRepository.GetByID(ctx, id)
+	if err := r.PresensiRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.recorder.record(ctx, id, entity.AuditActionDelete, presensiSnapshot(before), nil, nil)
+	return nil
+}
+
+func presensiSnapshot(p *entity.Presensi) snapshot {
+	if p == nil {
+		return nil
+	}
+
+	s := snapshot{
+		"user_id":    p.UserID,
+		"nama":       p.Nama,
+		"tanggal":    formatTime(&p.Tanggal),
+		"jam_masuk":  formatTime(p.JamMasuk),
+		"jam_keluar": formatTime(p.JamKeluar),
+		"status":     string(p.Status),
+		"keterangan": p.Keterangan,
+		"lokasi":     nil,
+		"created_at": formatTime(&p.CreatedAt),
+		"updated_at": formatTime(&p.UpdatedAt),
+	}
+	if p.Lokasi != nil {
+		s["lokasi"] = map[string]interface{}{
+			"latitude":  p.Lokasi.Latitude,
+			"longitude": p.Lokasi.Longitude,
+			"alamat":    p.Lokasi.Alamat,
+		}
+	}
+	return s
+}
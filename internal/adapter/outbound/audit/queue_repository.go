@@ -0,0 +1,260 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+const queueWriteTimeout = 10 * time.Second // Per write attempt
+
+// ErrAuditQueueFull is returned when an entry can be neither queued nor spilled to disk
+var ErrAuditQueueFull = errors.New("antrian audit log penuh")
+
+// QueueConfig configures QueuedRepository
+type QueueConfig struct {
+	Capacity      int           // Entries waiting to be written
+	Workers       int           // Concurrent batch writers
+	BatchSize     int           // Entries per CreateMany
+	FlushInterval time.Duration // Longest wait for a batch to fill up
+	MaxAttempts   int           // Write attempts before a batch is spilled
+	BaseBackoff   time.Duration // Doubled after every failed attempt
+	SpillPath     string        // NDJSON file for entries that could not be written, empty disables spilling
+	ReplayEvery   time.Duration // How often spilled entries are written again
+}
+
+// QueuedRepository writes audit entries in the background. Create only enqueues the entry;
+// a pool of workers writes batches with CreateMany and retries with exponential backoff.
+// Batches that still fail, and entries that arrive while the queue is full, are appended to
+// a local spill file and written again once the database is reachable.
+type QueuedRepository struct {
+	repository.AuditLogRepository
+	config  QueueConfig
+	spill   *spillFile
+	logger  *slog.Logger
+	entries chan *entity.AuditLog
+	workers sync.WaitGroup
+
+	// abort is closed when Close runs out of time, pending batches are spilled without retrying
+	abort      chan struct{}
+	stopReplay chan struct{}
+
+	// mu guards closed, entries created after Close are written directly
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewQueuedRepository wraps repo and starts the workers and the spill replay. Close must be
+// called on shutdown.
+func NewQueuedRepository(repo repository.AuditLogRepository, config QueueConfig, logger *slog.Logger) *QueuedRepository {
+	if config.Capacity < 1 {
+		config.Capacity = 1
+	}
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 1
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = 500 * time.Millisecond
+	}
+	if config.ReplayEvery <= 0 {
+		config.ReplayEvery = 30 * time.Second
+	}
+
+	r := &QueuedRepository{
+		AuditLogRepository: repo,
+		config:             config,
+		logger:             logger,
+		entries:            make(chan *entity.AuditLog, config.Capacity),
+		abort:              make(chan struct{}),
+		stopReplay:         make(chan struct{}),
+	}
+	if config.SpillPath != "" {
+		r.spill = &spillFile{path: config.SpillPath}
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		r.workers.Add(1)
+		go r.work()
+	}
+	if r.spill != nil {
+		go r.replayLoop()
+	}
+	return r
+}
+
+// Create enqueues the entry. When the queue is full the entry is spilled to disk instead,
+// so a request is never blocked by a slow database.
+func (r *QueuedRepository) Create(ctx context.Context, auditLog *entity.AuditLog) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		return r.AuditLogRepository.Create(ctx, auditLog)
+	}
+
+	select {
+	case r.entries <- auditLog:
+		return nil
+	default:
+	}
+
+	r.logger.Warn("Audit queue full, spilling entry to disk", slog.String("entity_type", auditLog.EntityType))
+	return r.spillBatch([]*entity.AuditLog{auditLog})
+}
+
+func (r *QueuedRepository) work() {
+	defer r.workers.Done()
+
+	for entry := range r.entries {
+		batch := []*entity.AuditLog{entry}
+		timer := time.NewTimer(r.config.FlushInterval)
+
+	collect:
+		for len(batch) < r.config.BatchSize {
+			select {
+			case next, ok := <-r.entries:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, next)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		r.write(batch)
+	}
+}
+
+// write stores the batch, retrying with backoff, and spills it when all attempts fail
+func (r *QueuedRepository) write(batch []*entity.AuditLog) {
+	select {
+	case <-r.abort:
+		r.spillBatch(batch)
+		return
+	default:
+	}
+
+	backoff := r.config.BaseBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), queueWriteTimeout)
+		err := r.AuditLogRepository.CreateMany(ctx, batch)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		if attempt >= r.config.MaxAttempts {
+			r.logger.Error("Failed to write audit entries",
+				slog.Int("entries", len(batch)),
+				slog.Int("attempts", attempt),
+				slog.String("error", err.Error()))
+			r.spillBatch(batch)
+			return
+		}
+
+		select {
+		case <-r.abort:
+			r.spillBatch(batch)
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// spillBatch appends the batch to the spill file, the entries are lost when spilling is disabled or fails
+func (r *QueuedRepository) spillBatch(batch []*entity.AuditLog) error {
+	if r.spill == nil {
+		r.logger.Error("Audit entries dropped, no spill file configured", slog.Int("entries", len(batch)))
+		return ErrAuditQueueFull
+	}
+
+	if err := r.spill.append(batch); err != nil {
+		r.logger.Error("Audit entries dropped, spill file not writable",
+			slog.Int("entries", len(batch)),
+			slog.String("path", r.spill.path),
+			slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (r *QueuedRepository) replayLoop() {
+	ticker := time.NewTicker(r.config.ReplayEvery)
+	defer ticker.Stop()
+
+	for {
+		r.replay()
+
+		select {
+		case <-r.stopReplay:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// replay writes the spilled entries again in batches. Entries that still cannot be written
+// stay in the spill file for the next run.
+func (r *QueuedRepository) replay() {
+	replayed, err := r.spill.drain(r.config.BatchSize, func(batch []*entity.AuditLog) error {
+		ctx, cancel := context.WithTimeout(context.Background(), queueWriteTimeout)
+		defer cancel()
+		return r.AuditLogRepository.CreateMany(ctx, batch)
+	})
+	if replayed > 0 {
+		r.logger.Info("Spilled audit entries written", slog.Int("entries", replayed))
+	}
+	if err != nil {
+		r.logger.Warn("Spilled audit entries not written yet", slog.String("error", err.Error()))
+	}
+}
+
+// Close stops accepting entries and writes the queued entries. Entries that are not written
+// before ctx expires are spilled to disk.
+func (r *QueuedRepository) Close(ctx context.Context) {
+	r.mu.Lock()
+	r.closed = true
+	close(r.entries)
+	r.mu.Unlock()
+
+	close(r.stopReplay)
+
+	done := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		r.logger.Warn("Audit queue did not drain before shutdown, spilling remaining entries")
+		close(r.abort)
+		<-done
+	}
+}
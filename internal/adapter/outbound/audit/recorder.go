@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+// Package audit decorates repositories so that every create, update and delete is written to
+// the audit log together with the entity state before and after the change.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+const (
+	redacted      = "[REDACTED]"
+	changedSecret = "[CHANGED]"
+	timeLayout    = "2006-01-02T15:04:05Z07:00"
+	writeTimeout  = 5 * time.Second
+)
+
+// snapshot is the audited state of an entity, keyed by field name
+type snapshot map[string]interface{}
+
+// change is a single field in the computed diff
+type change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ignoredFields change on every write and would make every diff non-empty
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// diff returns the fields whose value differs between before and after
+func diff(before, after snapshot) map[string]change {
+	changes := make(map[string]change)
+	for field, newValue := range after {
+		if ignoredFields[field] {
+			continue
+		}
+		oldValue := before[field]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = change{Old: oldValue, New: newValue}
+		}
+	}
+	for field, oldValue := range before {
+		if _, ok := after[field]; !ok && !ignoredFields[field] {
+			changes[field] = change{Old: oldValue, New: nil}
+		}
+	}
+	return changes
+}
+
+type recorder struct {
+	auditRepo  repository.AuditLogRepository
+	entityType string
+}
+
+// record writes an audit entry. A nil before or after is stored as an empty value,
+// and changes is only set for updates.
+func (r recorder) record(ctx context.Context, entityID string, action entity.AuditAction, before, after snapshot, changes map[string]change) {
+	actor := entity.AuditActorFromContext(ctx)
+	if actor == nil {
+		actor = &entity.AuditActor{}
+	}
+
+	auditLog := entity.NewAuditLog(r.entityType, entityID, action, actor.UserID, actor.UserName, actor.UserRole, actor.IPAddress)
+	auditLog.RequestID = actor.RequestID
+	auditLog.UserAgent = actor.UserAgent
+	auditLog.SetChanges(encode(before), encode(after), encode(changes))
+
+	// Entri audit tetap ditulis walaupun request dibatalkan setelah perubahan tersimpan
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+
+	// Kegagalan audit tidak boleh menggagalkan perubahan data yang sudah tersimpan
+	if err := r.auditRepo.Create(writeCtx, auditLog); err == nil {
+		actor.MarkRecorded()
+	}
+}
+
+// recordUpdate writes an update entry unless no audited field changed
+func (r recorder) recordUpdate(ctx context.Context, entityID string, before, after snapshot) {
+	changes := diff(before, after)
+	if len(changes) == 0 {
+		return
+	}
+	r.record(ctx, entityID, entity.AuditActionUpdate, before, after, changes)
+}
+
+// encode marshals a snapshot or diff, returning an empty string for a nil or empty map
+func encode(v interface{}) string {
+	if rv := reflect.ValueOf(v); !rv.IsValid() || rv.Len() == 0 {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func formatTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format(timeLayout)
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+const sinkSendTimeout = time.Minute // Covers the retries of a single entry
+
+// SinkRepository forwards every stored audit entry to external sinks. Each sink has its own
+// buffered queue and worker, so a slow or unreachable sink never delays the request that
+// produced the entry. Entries are dropped with a warning when a queue is full.
+type SinkRepository struct {
+	repository.AuditLogRepository
+	queues []*sinkQueue
+	logger *slog.Logger
+	wg     sync.WaitGroup
+
+	// mu guards closed, entries created during shutdown are stored but not forwarded
+	mu     sync.RWMutex
+	closed bool
+}
+
+type sinkQueue struct {
+	sink    repository.AuditSink
+	entries chan *entity.AuditLog
+}
+
+// NewSinkRepository wraps repo and starts one worker per sink. Close must be called on shutdown.
+func NewSinkRepository(repo repository.AuditLogRepository, sinks []repository.AuditSink, bufferSize int, logger *slog.Logger) *SinkRepository {
+	r := &SinkRepository{
+		AuditLogRepository: repo,
+		logger:             logger,
+	}
+
+	for _, sink := range sinks {
+		queue := &sinkQueue{sink: sink, entries: make(chan *entity.AuditLog, bufferSize)}
+		r.queues = append(r.queues, queue)
+		r.wg.Add(1)
+		go r.work(queue)
+	}
+	return r
+}
+
+// Create stores the entry first, so sinks only receive entries with their chain hash
+func (r *SinkRepository) Create(ctx context.Context, auditLog *entity.AuditLog) error {
+	if err := r.AuditLogRepository.Create(ctx, auditLog); err != nil {
+		return err
+	}
+	r.forward(auditLog)
+	return nil
+}
+
+// CreateMany stores the batch first, like Create
+func (r *SinkRepository) CreateMany(ctx context.Context, auditLogs []*entity.AuditLog) error {
+	if err := r.AuditLogRepository.CreateMany(ctx, auditLogs); err != nil {
+		return err
+	}
+	for _, auditLog := range auditLogs {
+		r.forward(auditLog)
+	}
+	return nil
+}
+
+func (r *SinkRepository) forward(auditLog *entity.AuditLog) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	for _, queue := range r.queues {
+		entry := *auditLog
+		select {
+		case queue.entries <- &entry:
+		default:
+			r.logger.Warn("Audit sink queue full, entry dropped",
+				slog.String("sink", queue.sink.Name()),
+				slog.Int64("sequence", auditLog.Sequence))
+		}
+	}
+}
+
+func (r *SinkRepository) work(queue *sinkQueue) {
+	defer r.wg.Done()
+
+	for entry := range queue.entries {
+		ctx, cancel := context.WithTimeout(context.Background(), sinkSendTimeout)
+		if err := queue.sink.Send(ctx, entry); err != nil {
+			r.logger.Error("Failed to send audit entry to sink",
+				slog.String("sink", queue.sink.Name()),
+				slog.Int64("sequence", entry.Sequence),
+				slog.String("error", err.Error()))
+		}
+		cancel()
+	}
+}
+
+// Close stops accepting entries, waits until the queued entries are sent or ctx expires,
+// and closes the sinks
+func (r *SinkRepository) Close(ctx context.Context) {
+	r.mu.Lock()
+	r.closed = true
+	for _, queue := range r.queues {
+		close(queue.entries)
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		r.logger.Warn("Audit sinks did not drain before shutdown")
+	}
+
+	for _, queue := range r.queues {
+		queue.sink.Close()
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// spillFile keeps audit entries that could not be written to the database as NDJSON.
+// Entries keep the ID of a failed write, so an entry that was stored after all is skipped
+// by CreateMany when it is replayed.
+type spillFile struct {
+	path string
+	mu   sync.Mutex
+}
+
+// append writes the batch and syncs the file, so spilled entries survive a crash
+func (s *spillFile) append(batch []*entity.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, auditLog := range batch {
+		if err := encoder.Encode(auditLog); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// drain moves the spill file aside and passes its entries to write in batches. When write
+// fails, the failed batch and the entries after it are spilled again. A file left aside by
+// an interrupted drain is processed first.
+func (s *spillFile) drain(batchSize int, write func([]*entity.AuditLog) error) (int, error) {
+	replayPath := s.path + ".replay"
+
+	s.mu.Lock()
+	if _, err := os.Stat(replayPath); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(s.path, replayPath); err != nil {
+			s.mu.Unlock()
+			if errors.Is(err, os.ErrNotExist) {
+				return 0, nil
+			}
+			return 0, err
+		}
+	}
+	s.mu.Unlock()
+
+	file, err := os.Open(replayPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	replayed := 0
+	batch := make([]*entity.AuditLog, 0, batchSize)
+	var writeErr error
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var auditLog entity.AuditLog
+			// Baris terakhir bisa terpotong jika proses berhenti saat menulis
+			if json.Unmarshal(line, &auditLog) == nil {
+				batch = append(batch, &auditLog)
+			}
+		}
+
+		if len(batch) > 0 && (len(batch) == batchSize || readErr != nil) {
+			if writeErr == nil {
+				if writeErr = write(batch); writeErr == nil {
+					replayed += len(batch)
+				}
+			}
+			if writeErr != nil {
+				if err := s.append(batch); err != nil {
+					return replayed, err
+				}
+			}
+			batch = batch[:0]
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return replayed, readErr
+		}
+	}
+
+	if err := os.Remove(replayPath); err != nil {
+		return replayed, err
+	}
+	return replayed, writeErr
+}
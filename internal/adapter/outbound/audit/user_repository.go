@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package audit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// UserRepository records every change to users in the audit log.
+// Password hashes and MFA secrets are never written; only the fact that they changed.
+type UserRepository struct {
+	repository.UserRepository
+	recorder recorder
+}
+
+// NewUserRepository wraps repo with audit logging
+func NewUserRepository(repo repository.UserRepository, auditRepo repository.AuditLogRepository) repository.UserRepository {
+	return &UserRepository{
+		UserRepository: repo,
+		recorder:       recorder{auditRepo: auditRepo, entityType: "users"},
+	}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepository.Create(ctx, user); err != nil {
+		return err
+	}
+	r.recorder.record(ctx, user.ID, entity.AuditActionCreate, nil, userSnapshot(user), nil)
+	return nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
+	before, _ := r.UserRepository.GetByID(ctx, user.ID)
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	beforeSnapshot, afterSnapshot := userSnapshot(before), userSnapshot(user)
+	if before != nil {
+		// Nilai rahasia tidak dicatat, hanya penanda bahwa nilainya berubah
+		if before.Password != user.Password {
+			beforeSnapshot["password"], afterSnapshot["password"] = redacted, changedSecret
+		}
+		if before.MFASecret != user.MFASecret {
+			beforeSnapshot["mfa_secret"], afterSnapshot["mfa_secret"] = redacted, changedSecret
+		}
+	}
+	r.recorder.recordUpdate(ctx, user.ID, beforeSnapshot, afterSnapshot)
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	before, _ := r.UserRepository.GetByID(ctx, id)
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.recorder.record(ctx, id, entity.AuditActionDelete, userSnapshot(before), nil, nil)
+	return nil
+}
+
+// userSnapshot leaves out password hashes, MFA secrets and the last used TOTP step
+func userSnapshot(u *entity.User) snapshot {
+	if u == nil {
+		return nil
+	}
+
+	return snapshot{
+		"email":              u.Email,
+		"nama":               u.Nama,
+		"role":               string(u.Role),
+		"is_active":          u.IsActive,
+		"invitation_pending": u.InvitationPending,
+		"employee_number":    u.EmployeeNumber,
+		"phone":              u.Phone,
+		"job_title":          u.JobTitle,
+		"hire_date":          formatTime(u.HireDate),
+		"employment_type":    string(u.EmploymentType),
+		"photo_ref":          u.PhotoRef,
+		"work_days":          strings.Join(entity.FormatWeekdays(u.WorkDays), ","),
+		"mfa_enabled":        u.MFAEnabled,
+		"mfa_recovery_codes": len(u.MFARecoveryCodes),
+		"auth_provider":      u.AuthProvider,
+		"external_id":        u.ExternalID,
+		"department_id":      u.DepartmentID,
+		"team_id":            u.TeamID,
+		"manager_id":         u.ManagerID,
+		"created_at":         formatTime(&u.CreatedAt),
+		"updated_at":         formatTime(&u.UpdatedAt),
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// memoryEntry is an element of MemoryAnalyticsCache.order
+type memoryEntry struct {
+	key       string
+	value     []byte
+	start     time.Time
+	end       time.Time
+	expiresAt time.Time
+}
+
+// MemoryAnalyticsCache is an in-process LRU cache, each API instance has its own
+type MemoryAnalyticsCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first
+}
+
+// NewMemoryAnalyticsCache creates a cache that keeps at most size entries
+func NewMemoryAnalyticsCache(size int) *MemoryAnalyticsCache {
+	if size <= 0 {
+		size = 1
+	}
+	return &MemoryAnalyticsCache{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+var _ repository.AnalyticsCache = (*MemoryAnalyticsCache)(nil)
+
+func (c *MemoryAnalyticsCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (c *MemoryAnalyticsCache) Set(ctx context.Context, key string, value []byte, start, end time.Time, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value, start: start, end: end, expiresAt: time.Now().Add(ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *MemoryAnalyticsCache) Invalidate(ctx context.Context, t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.order.Front(); element != nil; {
+		next := element.Next()
+		if entry := element.Value.(*memoryEntry); covers(entry.start, entry.end, t) {
+			c.remove(element)
+		}
+		element = next
+	}
+	return nil
+}
+
+func (c *MemoryAnalyticsCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*memoryEntry).key)
+}
+
+// covers reports whether t is in [start, end), zero bounds are open
+func covers(start, end, t time.Time) bool {
+	return (start.IsZero() || !t.Before(start)) && (end.IsZero() || t.Before(end))
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// PresensiRepository drops the cached analytics that read the day of every changed presensi
+type PresensiRepository struct {
+	repository.PresensiRepository
+	cache  repository.AnalyticsCache
+	logger *slog.Logger
+}
+
+// NewPresensiRepository wraps repo with analytics cache invalidation
+func NewPresensiRepository(repo repository.PresensiRepository, cache repository.AnalyticsCache, logger *slog.Logger) repository.PresensiRepository {
+	return &PresensiRepository{
+		PresensiRepository: repo,
+		cache:              cache,
+		logger:             logger,
+	}
+}
+
+func (r *PresensiRepository) Create(ctx context.Context, presensi *entity.Presensi) error {
+	if err := r.PresensiRepository.Create(ctx, presensi); err != nil {
+		return err
+	}
+	r.invalidate(ctx, presensi.Tanggal)
+	return nil
+}
+
+func (r *PresensiRepository) Update(ctx context.Context, presensi *entity.Presensi) error {
+	before, _ := r.PresensiRepository.GetByID(ctx, presensi.ID)
+	if err := r.PresensiRepository.Update(ctx, presensi); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, presensi.Tanggal)
+	if before != nil && !before.Tanggal.Equal(presensi.Tanggal) {
+		r.invalidate(ctx, before.Tanggal)
+	}
+	return nil
+}
+
+func (r *PresensiRepository) Delete(ctx context.Context, id string) error {
+	before, _ := r.PresensiRepository.GetByID(ctx, id)
+	if err := r.PresensiRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if before != nil {
+		r.invalidate(ctx, before.Tanggal)
+	}
+	return nil
+}
+
+// invalidate never fails the presensi change, entries it misses expire after their TTL
+func (r *PresensiRepository) invalidate(ctx context.Context, t time.Time) {
+	if err := r.cache.Invalidate(ctx, t); err != nil {
+		r.logger.Error("Failed to invalidate analytics cache",
+			slog.String("date", t.Format("2006-01-02")),
+			slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,297 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+const (
+	redisDialTimeout    = 5 * time.Second
+	redisCommandTimeout = 2 * time.Second
+
+	// redisIndexPrefix names the set of cache keys whose range overlaps a UTC day, e.g.
+	// "analytics:index:2024-01-31"; entries with an open bound are listed in redisOpenIndexKey
+	redisIndexPrefix  = "analytics:index:"
+	redisOpenIndexKey = redisIndexPrefix + "open"
+
+	// redisMaxIndexDays is the longest range indexed per day, longer ranges are indexed as open
+	redisMaxIndexDays = 400
+)
+
+// redisSetScript stores ARGV[1] under KEYS[1] for ARGV[2] milliseconds and adds KEYS[1] to the
+// index sets KEYS[2..]. An index set expires no earlier than the entries it lists.
+const redisSetScript = `
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+for i = 2, #KEYS do
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if redis.call('PTTL', KEYS[i]) < tonumber(ARGV[2]) then
+		redis.call('PEXPIRE', KEYS[i], ARGV[2])
+	end
+end
+return #KEYS - 1`
+
+// redisInvalidateScript deletes the entries listed in the index sets KEYS and the sets themselves
+const redisInvalidateScript = `
+local deleted = 0
+for _, index in ipairs(KEYS) do
+	local keys = redis.call('SMEMBERS', index)
+	for i = 1, #keys, 1000 do
+		deleted = deleted + redis.call('DEL', unpack(keys, i, math.min(i + 999, #keys)))
+	end
+	redis.call('DEL', index)
+end
+return deleted`
+
+// redisError is an error reply sent by the server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// RedisAnalyticsCache stores entries in Redis or a server speaking its protocol (Valkey, KeyDB,
+// Dragonfly), shared by every API instance. Commands go over one connection that is opened
+// again after a failed command.
+type RedisAnalyticsCache struct {
+	address  string
+	username string
+	password string
+	database int
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisAnalyticsCache parses an address such as "redis://:password@cache:6379/0"
+func NewRedisAnalyticsCache(address string) (*RedisAnalyticsCache, error) {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" || u.Scheme != "redis" {
+		return nil, fmt.Errorf("alamat redis tidak valid: %q, gunakan redis://[:password@]host:port[/db]", address)
+	}
+
+	c := &RedisAnalyticsCache{address: u.Host}
+	if u.Port() == "" {
+		c.address = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if c.database, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("database redis tidak valid: %q", db)
+		}
+	}
+	return c, nil
+}
+
+var _ repository.AnalyticsCache = (*RedisAnalyticsCache)(nil)
+
+func (c *RedisAnalyticsCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return value, true, nil
+}
+
+func (c *RedisAnalyticsCache) Set(ctx context.Context, key string, value []byte, start, end time.Time, ttl time.Duration) error {
+	ttlMillis := ttl.Milliseconds()
+	if ttlMillis <= 0 {
+		ttlMillis = 1
+	}
+
+	// Entry dan index ditulis atomik, sehingga Invalidate tidak pernah melewatkan entry yang tersimpan
+	keys := append([]string{key}, indexKeys(start, end)...)
+	args := append([]string{redisSetScript, strconv.Itoa(len(keys))}, keys...)
+	args = append(args, string(value), strconv.FormatInt(ttlMillis, 10))
+	_, err := c.do(ctx, "EVAL", args...)
+	return err
+}
+
+// Invalidate only reads the index of t's day and of open ranges, so its cost does not grow with
+// the number of cached entries. Entries of other days that share the index are dropped too.
+func (c *RedisAnalyticsCache) Invalidate(ctx context.Context, t time.Time) error {
+	_, err := c.do(ctx, "EVAL", redisInvalidateScript, "2", dayIndexKey(t), redisOpenIndexKey)
+	return err
+}
+
+// indexKeys returns the index sets of every UTC day overlapping [start, end)
+func indexKeys(start, end time.Time) []string {
+	if start.IsZero() || end.IsZero() || !start.Before(end) || end.Sub(start) > redisMaxIndexDays*24*time.Hour {
+		return []string{redisOpenIndexKey}
+	}
+
+	var keys []string
+	day := start.UTC().Truncate(24 * time.Hour)
+	for ; day.Before(end); day = day.Add(24 * time.Hour) {
+		keys = append(keys, dayIndexKey(day))
+	}
+	return keys
+}
+
+func dayIndexKey(t time.Time) string {
+	return redisIndexPrefix + t.UTC().Format("2006-01-02")
+}
+
+func (c *RedisAnalyticsCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// do sends one command and reads its reply. Error replies are returned as redisError and keep the
+// connection, network and protocol errors close it.
+func (c *RedisAnalyticsCache) do(ctx context.Context, name string, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.roundTrip(ctx, name, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// Koneksi dibuka ulang pada command berikutnya
+		c.conn.Close()
+		c.conn = nil
+	}
+	return reply, err
+}
+
+func (c *RedisAnalyticsCache) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: redisDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	if c.password != "" {
+		args := []string{c.password}
+		if c.username != "" {
+			args = []string{c.username, c.password}
+		}
+		if _, err := c.roundTrip(ctx, "AUTH", args...); err != nil {
+			conn.Close()
+			c.conn = nil
+			return err
+		}
+	}
+	if c.database != 0 {
+		if _, err := c.roundTrip(ctx, "SELECT", strconv.Itoa(c.database)); err != nil {
+			conn.Close()
+			c.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *RedisAnalyticsCache) roundTrip(ctx context.Context, name string, args ...string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisCommandTimeout)
+	}
+	c.conn.SetDeadline(deadline)
+
+	// Command dikirim sebagai array of bulk strings (RESP)
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args)+1)
+	for _, arg := range append([]string{name}, args...) {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, command.String()); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.reader)
+}
+
+// readReply reads a RESP reply: simple strings as string, integers as int64, bulk strings as []byte,
+// arrays as []interface{}, null bulk strings and arrays as nil
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", payload)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array length %q", payload)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			item, err := readReply(reader)
+			var replyErr redisError
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+	}
+}
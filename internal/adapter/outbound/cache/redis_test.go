@@ -0,0 +1,336 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis adalah server RESP lokal: mencatat setiap command dan membalas lewat reply.
+// Jika reply mengembalikan string kosong, koneksi ditutup tanpa balasan.
+type fakeRedis struct {
+	listener net.Listener
+	reply    func(command []string) string
+
+	mu          sync.Mutex
+	commands    [][]string
+	connections int
+}
+
+func newFakeRedis(t *testing.T, reply func(command []string) string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeRedis{listener: listener, reply: reply}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.connections++
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		// Command dari client adalah array of bulk strings, sama dengan format reply
+		value, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		var command []string
+		for _, arg := range value.([]interface{}) {
+			command = append(command, string(arg.([]byte)))
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, command)
+		s.mu.Unlock()
+
+		reply := s.reply(command)
+		if reply == "" {
+			return
+		}
+		conn.Write([]byte(reply))
+	}
+}
+
+func (s *fakeRedis) url(credentials, database string) string {
+	return "redis://" + credentials + s.listener.Addr().String() + database
+}
+
+// names mengembalikan nama command yang diterima secara berurutan
+func (s *fakeRedis) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for _, command := range s.commands {
+		names = append(names, command[0])
+	}
+	return names
+}
+
+func (s *fakeRedis) command(i int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands[i]
+}
+
+func (s *fakeRedis) last() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands[len(s.commands)-1]
+}
+
+func (s *fakeRedis) connectionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr string
+	}{
+		{"simple string", "+OK\r\n", "OK", ""},
+		{"integer", ":42\r\n", int64(42), ""},
+		{"negative integer", ":-2\r\n", int64(-2), ""},
+		{"bulk string", "$5\r\nhello\r\n", []byte("hello"), ""},
+		{"bulk string with CRLF", "$4\r\na\r\nb\r\n", []byte("a\r\nb"), ""},
+		{"empty bulk string", "$0\r\n\r\n", []byte{}, ""},
+		{"null bulk string", "$-1\r\n", nil, ""},
+		{"null array", "*-1\r\n", nil, ""},
+		{"array", "*3\r\n$1\r\na\r\n:1\r\n$-1\r\n", []interface{}{[]byte("a"), int64(1), nil}, ""},
+		{"nested array", "*2\r\n*1\r\n+x\r\n*0\r\n", []interface{}{[]interface{}{"x"}, []interface{}{}}, ""},
+		{"array with error item", "*2\r\n-ERR one\r\n:2\r\n", []interface{}{nil, int64(2)}, ""},
+		{"error", "-WRONGTYPE wrong kind\r\n", nil, "redis: WRONGTYPE wrong kind"},
+		{"unknown type", "!3\r\nabc\r\n", nil, "unknown reply type"},
+		{"missing CR", "+OK\n", nil, "malformed reply"},
+		{"malformed bulk length", "$x\r\n", nil, "malformed bulk length"},
+		{"malformed array length", "*x\r\n", nil, "malformed array length"},
+		{"truncated bulk string", "$5\r\nhel", nil, "EOF"},
+		{"truncated array", "*2\r\n:1\r\n", nil, "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readReply(bufio.NewReader(strings.NewReader(tt.input)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readReply: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readReply = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	var replyErr redisError
+	if _, err := readReply(bufio.NewReader(strings.NewReader("-ERR x\r\n"))); !errors.As(err, &replyErr) {
+		t.Errorf("error reply = %T, want redisError", err)
+	}
+}
+
+func TestRedisAnalyticsCacheCommands(t *testing.T) {
+	ctx := context.Background()
+	values := map[string]string{}
+	server := newFakeRedis(t, func(command []string) string {
+		switch command[0] {
+		case "GET":
+			if value, ok := values[command[1]]; ok {
+				return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+			}
+			return "$-1\r\n"
+		case "EVAL":
+			return ":1\r\n"
+		default:
+			return "+OK\r\n"
+		}
+	})
+
+	c, err := NewRedisAnalyticsCache(server.url("app:secret@", "/2"))
+	if err != nil {
+		t.Fatalf("NewRedisAnalyticsCache: %v", err)
+	}
+	defer c.Close()
+
+	if _, ok, err := c.Get(ctx, "analytics:summary:a"); err != nil || ok {
+		t.Fatalf("Get missing = %v, %v, want a miss", ok, err)
+	}
+	if got := server.names(); !reflect.DeepEqual(got, []string{"AUTH", "SELECT", "GET"}) {
+		t.Fatalf("commands = %v, want AUTH, SELECT, GET", got)
+	}
+	if got := server.command(0); !reflect.DeepEqual(got, []string{"AUTH", "app", "secret"}) {
+		t.Errorf("AUTH = %v", got)
+	}
+	if got := server.command(1); !reflect.DeepEqual(got, []string{"SELECT", "2"}) {
+		t.Errorf("SELECT = %v", got)
+	}
+
+	values["analytics:summary:a"] = `{"total":1}`
+	value, ok, err := c.Get(ctx, "analytics:summary:a")
+	if err != nil || !ok || string(value) != `{"total":1}` {
+		t.Fatalf("Get = %q, %v, %v", value, ok, err)
+	}
+
+	// Set menulis entry dan index per hari UTC dalam satu script
+	start := time.Date(2024, 1, 30, 0, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	if err := c.Set(ctx, "analytics:daily:b", []byte("{}"), start, start.AddDate(0, 0, 2), 90*time.Second); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	want := []string{
+		"EVAL", redisSetScript, "4", "analytics:daily:b",
+		"analytics:index:2024-01-29", "analytics:index:2024-01-30", "analytics:index:2024-01-31",
+		"{}", "90000",
+	}
+	if got := server.last(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Set sent %q, want %q", got, want)
+	}
+
+	// Invalidate hanya membaca index hari itu dan index rentang terbuka
+	if err := c.Invalidate(ctx, time.Date(2024, 1, 31, 3, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+	want = []string{"EVAL", redisInvalidateScript, "2", "analytics:index:2024-01-31", redisOpenIndexKey}
+	if got := server.last(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalidate sent %q, want %q", got, want)
+	}
+
+	if n := server.connectionCount(); n != 1 {
+		t.Errorf("connections = %d, want 1", n)
+	}
+}
+
+func TestRedisAnalyticsCacheReconnects(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	gets := 0
+	server := newFakeRedis(t, func(command []string) string {
+		switch command[0] {
+		case "GET":
+			mu.Lock()
+			defer mu.Unlock()
+			gets++
+			switch gets {
+			case 1:
+				return "" // koneksi putus sebelum balasan
+			case 2:
+				return "-LOADING server is loading\r\n"
+			}
+			return "$2\r\nok\r\n"
+		default:
+			return "+OK\r\n"
+		}
+	})
+
+	c, err := NewRedisAnalyticsCache(server.url(":secret@", ""))
+	if err != nil {
+		t.Fatalf("NewRedisAnalyticsCache: %v", err)
+	}
+	defer c.Close()
+
+	if _, _, err := c.Get(ctx, "key"); err == nil {
+		t.Fatal("Get on a dropped connection succeeded")
+	}
+
+	// Koneksi dibuka ulang dan AUTH dikirim lagi
+	var replyErr redisError
+	if _, _, err := c.Get(ctx, "key"); !errors.As(err, &replyErr) {
+		t.Fatalf("err = %v, want the error reply", err)
+	}
+	if n := server.connectionCount(); n != 2 {
+		t.Fatalf("connections = %d, want 2", n)
+	}
+
+	// Error reply tidak menutup koneksi
+	value, ok, err := c.Get(ctx, "key")
+	if err != nil || !ok || string(value) != "ok" {
+		t.Fatalf("Get = %q, %v, %v", value, ok, err)
+	}
+	if n := server.connectionCount(); n != 2 {
+		t.Errorf("connections = %d, want 2 after an error reply", n)
+	}
+	if got := server.names(); !reflect.DeepEqual(got, []string{"AUTH", "GET", "AUTH", "GET", "GET"}) {
+		t.Errorf("commands = %v", got)
+	}
+}
+
+func TestRedisAnalyticsCacheConnectFailure(t *testing.T) {
+	server := newFakeRedis(t, func(command []string) string {
+		return "-WRONGPASS invalid username-password pair\r\n"
+	})
+
+	c, err := NewRedisAnalyticsCache(server.url(":wrong@", ""))
+	if err != nil {
+		t.Fatalf("NewRedisAnalyticsCache: %v", err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.Get(context.Background(), "key"); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+			t.Fatalf("Get = %v, want WRONGPASS", err)
+		}
+	}
+	if got := server.names(); !reflect.DeepEqual(got, []string{"AUTH", "AUTH"}) {
+		t.Errorf("commands = %v, want AUTH on every attempt", got)
+	}
+}
+
+func TestIndexKeys(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []string
+	}{
+		{"one day", day(1), day(2), []string{"analytics:index:2024-03-01"}},
+		{"partial days", day(1).Add(23 * time.Hour), day(2).Add(time.Hour), []string{"analytics:index:2024-03-01", "analytics:index:2024-03-02"}},
+		{"open start", time.Time{}, day(2), []string{redisOpenIndexKey}},
+		{"open end", day(1), time.Time{}, []string{redisOpenIndexKey}},
+		{"longer than the limit", day(1), day(1).AddDate(2, 0, 0), []string{redisOpenIndexKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := indexKeys(tt.start, tt.end); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("indexKeys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRedisAnalyticsCacheRejectsInvalidURL(t *testing.T) {
+	for _, address := range []string{"localhost:6379", "http://localhost:6379", "redis://", "redis://localhost:6379/x"} {
+		if _, err := NewRedisAnalyticsCache(address); err == nil {
+			t.Errorf("NewRedisAnalyticsCache(%q) succeeded", address)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// ArchiveStorage stores archive files in a local directory. It implements repository.ArchiveStorage,
+// an object storage adapter can replace it without changes to the retention service.
+type ArchiveStorage struct {
+	dir string
+}
+
+// NewArchiveStorage creates the directory if needed
+func NewArchiveStorage(dir string) (*ArchiveStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &ArchiveStorage{dir: dir}, nil
+}
+
+// Put writes to a temporary file first so that a failed write never leaves a partial archive
+func (s *ArchiveStorage) Put(ctx context.Context, name string, r io.Reader) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("arsip %s sudah ada", name)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-"+name+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *ArchiveStorage) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, repository.ErrArchiveNotFound
+	}
+	return file, err
+}
+
+func (s *ArchiveStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// path rejects names that would escape the archive directory
+func (s *ArchiveStorage) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", repository.ErrArchiveNotFound
+	}
+	return filepath.Join(s.dir, name), nil
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// AuditFileSink appends audit entries as NDJSON to a local file, for log shippers that tail files
+type AuditFileSink struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewAuditFileSink opens path for appending, creating it if needed
+func NewAuditFileSink(path string) (*AuditFileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditFileSink{file: file}, nil
+}
+
+func (s *AuditFileSink) Name() string {
+	return "file"
+}
+
+// Send writes the entry as a single line so that concurrent readers never see a partial entry
+func (s *AuditFileSink) Send(ctx context.Context, auditLog *entity.AuditLog) error {
+	line, err := json.Marshal(auditLog)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *AuditFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package filesystem
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// hashPrefixLength is the length of the SHA-1 prefix used to group hashes (same as the HIBP range API)
+const hashPrefixLength = 5
+
+// BreachedPasswordChecker checks passwords against a local file of breached SHA-1 hashes.
+//
+// The file holds one uppercase SHA-1 hash per line, optionally followed by ":count",
+// sorted by hash (the "ordered by hash" HIBP download). Only the byte offset of every
+// hash prefix is kept in memory, a lookup seeks to the prefix range and scans its suffixes.
+type BreachedPasswordChecker struct {
+	file    *os.File
+	offsets map[string]int64
+	mu      sync.Mutex
+}
+
+// NewBreachedPasswordChecker opens the hash file and builds the prefix index
+func NewBreachedPasswordChecker(path string) (*BreachedPasswordChecker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets, err := indexHashPrefixes(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &BreachedPasswordChecker{
+		file:    file,
+		offsets: offsets,
+	}, nil
+}
+
+// IsBreached reports whether the SHA-1 hash of the password is listed in the file
+func (c *BreachedPasswordChecker) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	offset, ok := c.offsets[prefix]
+	if !ok {
+		return false, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.file.Seek(offset, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	scanner := bufio.NewScanner(c.file)
+	for scanner.Scan() {
+		lineHash, _, _ := strings.Cut(scanner.Text(), ":")
+		lineHash = strings.ToUpper(strings.TrimSpace(lineHash))
+		if len(lineHash) != sha1.Size*2 || lineHash[:hashPrefixLength] != prefix {
+			break
+		}
+		if lineHash[hashPrefixLength:] == suffix {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// Close closes the underlying hash file
+func (c *BreachedPasswordChecker) Close() error {
+	return c.file.Close()
+}
+
+func indexHashPrefixes(file *os.File) (map[string]int64, error) {
+	offsets := make(map[string]int64)
+	reader := bufio.NewReader(file)
+
+	var offset int64
+	lastPrefix := ""
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+			hash = strings.ToUpper(hash)
+			if hash != "" {
+				if len(hash) != sha1.Size*2 {
+					return nil, fmt.Errorf("baris %d: hash SHA-1 tidak valid", lineNumber)
+				}
+				prefix := hash[:hashPrefixLength]
+				if prefix < lastPrefix {
+					return nil, fmt.Errorf("baris %d: file hash harus terurut", lineNumber)
+				}
+				if prefix != lastPrefix {
+					offsets[prefix] = offset
+					lastPrefix = prefix
+				}
+			}
+			offset += int64(len(line))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return offsets, nil
+}
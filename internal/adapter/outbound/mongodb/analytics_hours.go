@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// workingHoursSumFields are the fields of workingHoursFields that add up across groups
+var workingHoursSumFields = []string{"days", "completed_days", "missing_check_outs", "total_hours", "overtime_hours", "short_hours"}
+
+// workingHoursResult is a group built with workingHoursFields
+type workingHoursResult struct {
+	Key              string  `bson:"_id"`
+	Name             string  `bson:"name"`
+	Days             int     `bson:"days"`
+	CompletedDays    int     `bson:"completed_days"`
+	MissingCheckOuts int     `bson:"missing_check_outs"`
+	TotalHours       float64 `bson:"total_hours"`
+	OvertimeHours    float64 `bson:"overtime_hours"`
+	ShortHours       float64 `bson:"short_hours"`
+}
+
+func (r workingHoursResult) stats() entity.WorkingHoursStats {
+	stats := entity.WorkingHoursStats{
+		Key:              r.Key,
+		Name:             r.Name,
+		Days:             r.Days,
+		CompletedDays:    r.CompletedDays,
+		MissingCheckOuts: r.MissingCheckOuts,
+		TotalHours:       r.TotalHours,
+		OvertimeHours:    r.OvertimeHours,
+		ShortHours:       r.ShortHours,
+	}
+	stats.Calculate()
+	return stats
+}
+
+// workingHoursStages selects the check-ins of the period and adds hours, the time until
+// check-out or null, and open, set for check-ins of today that are not checked out yet
+func workingHoursStages(period entity.AnalyticsPeriod, filter entity.AnalyticsFilter) mongo.Pipeline {
+	match := periodMatch(period, filter)
+	match["jam_masuk"] = bson.M{"$ne": nil}
+
+	now := time.Now().In(period.Start.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	checkedOut := bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{"$jam_keluar", nil}}, nil}}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{
+			"hours": bson.M{"$cond": bson.A{
+				checkedOut,
+				bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$jam_keluar", "$jam_masuk"}}, 3600000}},
+				nil,
+			}},
+			"open": bson.M{"$and": bson.A{bson.M{"$not": bson.A{checkedOut}}, bson.M{"$gte": bson.A{"$jam_masuk", today}}}},
+		}}},
+	}
+}
+
+// workingHoursFields adds the working hours accumulators to a $group stage over workingHoursStages
+func workingHoursFields(group bson.M, schedule entity.WorkSchedule) bson.M {
+	scheduleHours := schedule.WorkHours.Hours()
+	completed := bson.M{"$ne": bson.A{"$hours", nil}}
+
+	// $subtract dengan hours null menghasilkan null, dan $max mengabaikan null
+	group["days"] = bson.M{"$sum": 1}
+	group["completed_days"] = bson.M{"$sum": bson.M{"$cond": bson.A{completed, 1, 0}}}
+	group["missing_check_outs"] = bson.M{"$sum": bson.M{"$cond": bson.A{
+		bson.M{"$and": bson.A{bson.M{"$not": bson.A{completed}}, bson.M{"$not": bson.A{"$open"}}}}, 1, 0,
+	}}}
+	group["total_hours"] = bson.M{"$sum": "$hours"}
+	group["overtime_hours"] = bson.M{"$sum": bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{"$hours", scheduleHours}}}}}
+	group["short_hours"] = bson.M{"$sum": bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{scheduleHours, "$hours"}}}}}
+	return group
+}
+
+func (r *AnalyticsRepository) aggregateWorkingHours(ctx context.Context, pipeline mongo.Pipeline) ([]workingHoursResult, error) {
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []workingHoursResult
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *AnalyticsRepository) GetWorkingHours(ctx context.Context, period entity.AnalyticsPeriod, schedule entity.WorkSchedule, groupBy entity.AnalyticsGroupBy, filter entity.AnalyticsFilter) ([]entity.WorkingHoursStats, error) {
+	if !groupBy.IsValid() {
+		return nil, entity.ErrInvalidGroupBy
+	}
+
+	pipeline := workingHoursStages(period, filter)
+	pipeline = append(pipeline, bson.D{{Key: "$group", Value: workingHoursFields(bson.M{
+		"_id":  "$user_id",
+		"name": bson.M{"$last": "$nama"},
+	}, schedule)}})
+	pipeline = append(pipeline, memberGroupStages(groupBy, workingHoursSumFields...)...)
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{
+		{Key: "total_hours", Value: -1},
+		{Key: "_id", Value: 1},
+	}}})
+
+	results, err := r.aggregateWorkingHours(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]entity.WorkingHoursStats, 0, len(results))
+	for _, result := range results {
+		stats = append(stats, result.stats())
+	}
+	return stats, nil
+}
+
+func (r *AnalyticsRepository) GetWorkingHoursSeries(ctx context.Context, period entity.AnalyticsPeriod, interval entity.AnalyticsInterval, schedule entity.WorkSchedule, filter entity.AnalyticsFilter) ([]entity.WorkingHoursBucket, error) {
+	format, ok := bucketFormats[interval]
+	if !ok {
+		return nil, entity.ErrInvalidInterval
+	}
+
+	pipeline := workingHoursStages(period, filter)
+	pipeline = append(pipeline, bson.D{{Key: "$group", Value: workingHoursFields(bson.M{
+		"_id": bson.M{"$dateToString": bson.M{
+			"format":   format,
+			"date":     "$tanggal",
+			"timezone": period.Start.Location().String(),
+		}},
+	}, schedule)}})
+
+	results, err := r.aggregateWorkingHours(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	byLabel := make(map[string]workingHoursResult, len(results))
+	for _, result := range results {
+		byLabel[result.Key] = result
+	}
+
+	buckets := period.Buckets(interval)
+	series := make([]entity.WorkingHoursBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		stats := byLabel[bucket.Label].stats()
+		stats.Key = ""
+		series = append(series, entity.WorkingHoursBucket{Label: bucket.Label, Start: bucket.Start, Stats: stats})
+	}
+	return series, nil
+}
@@ -0,0 +1,262 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// bucketFormats are the $dateToString formats that produce entity.BucketLabel
+var bucketFormats = map[entity.AnalyticsInterval]string{
+	entity.IntervalDay:   "%Y-%m-%d",
+	entity.IntervalWeek:  "%G-W%V",
+	entity.IntervalMonth: "%Y-%m",
+}
+
+// statusCounts is the result of a $group stage built with statusCountFields
+type statusCounts struct {
+	Label          string `bson:"_id"`
+	TotalRecords   int    `bson:"total_records"`
+	TotalHadir     int    `bson:"total_hadir"`
+	TotalTerlambat int    `bson:"total_terlambat"`
+	TotalIzin      int    `bson:"total_izin"`
+	TotalSakit     int    `bson:"total_sakit"`
+	TotalAlpha     int    `bson:"total_alpha"`
+}
+
+// statusCountFields adds the per status counters to a $group stage
+func statusCountFields(group bson.M) bson.M {
+	countStatus := func(status string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", status}}, 1, 0}}}
+	}
+
+	group["total_records"] = bson.M{"$sum": 1}
+	group["total_hadir"] = countStatus("hadir")
+	group["total_terlambat"] = countStatus("terlambat")
+	group["total_izin"] = countStatus("izin")
+	group["total_sakit"] = countStatus("sakit")
+	group["total_alpha"] = countStatus("alpha")
+	return group
+}
+
+func (c statusCounts) summary() entity.AttendanceSummary {
+	summary := entity.AttendanceSummary{
+		TotalRecords:   c.TotalRecords,
+		TotalHadir:     c.TotalHadir,
+		TotalTerlambat: c.TotalTerlambat,
+		TotalIzin:      c.TotalIzin,
+		TotalSakit:     c.TotalSakit,
+		TotalAlpha:     c.TotalAlpha,
+	}
+	summary.CalculatePercentage()
+	return summary
+}
+
+func (c *statusCounts) add(other statusCounts) {
+	c.TotalRecords += other.TotalRecords
+	c.TotalHadir += other.TotalHadir
+	c.TotalTerlambat += other.TotalTerlambat
+	c.TotalIzin += other.TotalIzin
+	c.TotalSakit += other.TotalSakit
+	c.TotalAlpha += other.TotalAlpha
+}
+
+// breakdown returns the statuses with at least one record, most frequent first
+func (c statusCounts) breakdown() []entity.StatusBreakdown {
+	breakdown := make([]entity.StatusBreakdown, 0, 5)
+	for _, status := range []entity.StatusBreakdown{
+		{Status: "hadir", Count: c.TotalHadir},
+		{Status: "terlambat", Count: c.TotalTerlambat},
+		{Status: "izin", Count: c.TotalIzin},
+		{Status: "sakit", Count: c.TotalSakit},
+		{Status: "alpha", Count: c.TotalAlpha},
+	} {
+		if status.Count > 0 {
+			breakdown = append(breakdown, status)
+		}
+	}
+	sort.SliceStable(breakdown, func(i, j int) bool { return breakdown[i].Count > breakdown[j].Count })
+	return breakdown
+}
+
+// periodMatch selects the records of the period and the filter's users
+func periodMatch(period entity.AnalyticsPeriod, filter entity.AnalyticsFilter) bson.M {
+	match := bson.M{"tanggal": bson.M{"$gte": period.Start, "$lt": period.End}}
+	if userMatch, ok := userIDMatch(filter.UserID, filter.UserIDs); ok {
+		match["user_id"] = userMatch
+	}
+	return match
+}
+
+// countStatuses counts the statuses of the filter's records in [StartDate, EndDate), grouped by
+// the $dateToString format in the repository's time zone, or in a single "" group without one.
+// Whole days before today come from the daily rollups when they are enabled, the rest from presensi.
+func (r *AnalyticsRepository) countStatuses(ctx context.Context, filter entity.AnalyticsFilter, format string) (map[string]statusCounts, error) {
+	label := interface{}(nil)
+	if format != "" {
+		label = bson.M{"$dateToString": bson.M{"format": format, "date": "$tanggal", "timezone": r.location.String()}}
+	}
+	userMatch, hasUserMatch := userIDMatch(filter.UserID, filter.UserIDs)
+
+	// Rentang presensi mentah: seluruh filter, atau sisa di luar rentang rekap
+	rawRanges := bson.A{}
+	rollupStart, rollupEnd, useRollups := r.rollupRange(filter.StartDate, filter.EndDate)
+	if useRollups {
+		if !rollupStart.IsZero() && rollupStart.After(filter.StartDate) {
+			rawRanges = append(rawRanges, dateRange(filter.StartDate, rollupStart))
+		}
+		if filter.EndDate.IsZero() || rollupEnd.Before(filter.EndDate) {
+			rawRanges = append(rawRanges, dateRange(rollupEnd, filter.EndDate))
+		}
+	} else {
+		rawRanges = append(rawRanges, dateRange(filter.StartDate, filter.EndDate))
+	}
+
+	counts := make(map[string]statusCounts)
+	if len(rawRanges) > 0 {
+		match := bson.M{"$or": rawRanges}
+		if hasUserMatch {
+			match["user_id"] = userMatch
+		}
+		results, err := aggregateStatusCounts(ctx, r.collection, mongo.Pipeline{
+			{{Key: "$match", Value: match}},
+			{{Key: "$group", Value: statusCountFields(bson.M{"_id": label})}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			c := counts[result.Label]
+			c.add(result)
+			counts[result.Label] = c
+		}
+	}
+
+	if useRollups {
+		if format != "" {
+			label = bson.M{"$dateToString": bson.M{"format": format, "date": "$date", "timezone": r.location.String()}}
+		}
+		group := bson.M{"_id": label}
+		for _, field := range rollupCountFields {
+			group[field] = bson.M{"$sum": "$" + field}
+		}
+
+		dates := bson.M{"$lt": rollupEnd}
+		if !rollupStart.IsZero() {
+			dates["$gte"] = rollupStart
+		}
+		match := bson.M{"date": dates}
+		if hasUserMatch {
+			match["user_id"] = userMatch
+		}
+
+		results, err := aggregateStatusCounts(ctx, r.rollups, mongo.Pipeline{
+			{{Key: "$match", Value: match}},
+			{{Key: "$group", Value: group}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			c := counts[result.Label]
+			c.add(result)
+			counts[result.Label] = c
+		}
+	}
+
+	return counts, nil
+}
+
+// rollupRange returns the whole calendar days of [start, end) before today, a zero start is unbounded.
+// ok is false when rollups are disabled or the range holds no such day.
+func (r *AnalyticsRepository) rollupRange(start, end time.Time) (rollupStart, rollupEnd time.Time, ok bool) {
+	if r.rollups == nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	rollupEnd = dayStart(time.Now(), r.location)
+	if !end.IsZero() && end.Before(rollupEnd) {
+		rollupEnd = dayStart(end, r.location)
+	}
+	if !start.IsZero() {
+		rollupStart = dayStart(start, r.location)
+		if rollupStart.Before(start) {
+			rollupStart = rollupStart.AddDate(0, 0, 1)
+		}
+		if !rollupStart.Before(rollupEnd) {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	return rollupStart, rollupEnd, true
+}
+
+// dateRange matches tanggal in [start, end), zero bounds are open
+func dateRange(start, end time.Time) bson.M {
+	dates := bson.M{}
+	if !start.IsZero() {
+		dates["$gte"] = start
+	}
+	if !end.IsZero() {
+		dates["$lt"] = end
+	}
+	if len(dates) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"tanggal": dates}
+}
+
+func aggregateStatusCounts(ctx context.Context, collection *mongo.Collection, pipeline mongo.Pipeline) ([]statusCounts, error) {
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []statusCounts
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *AnalyticsRepository) GetPeriodSummary(ctx context.Context, period entity.AnalyticsPeriod, interval entity.AnalyticsInterval, filter entity.AnalyticsFilter) (*entity.PeriodSummary, error) {
+	format, ok := bucketFormats[interval]
+	if !ok {
+		return nil, entity.ErrInvalidInterval
+	}
+
+	// Bucket dihitung di zona waktu repository, sama dengan zona waktu periode dari use case
+	filter.StartDate = period.Start
+	filter.EndDate = period.End
+	byLabel, err := r.countStatuses(ctx, filter, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bucket tanpa presensi tetap dikembalikan dengan nilai nol
+	summary := &entity.PeriodSummary{
+		Period:   period,
+		Interval: interval,
+		Series:   period.Buckets(interval),
+	}
+	for i := range summary.Series {
+		summary.Series[i].Summary = byLabel[summary.Series[i].Label].summary()
+		summary.Summary.Add(summary.Series[i].Summary)
+	}
+	summary.Summary.CalculatePercentage()
+
+	return summary, nil
+}
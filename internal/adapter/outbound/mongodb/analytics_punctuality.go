@@ -0,0 +1,294 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// memberGroupFields are the users collection fields per-user results are regrouped by
+var memberGroupFields = map[entity.AnalyticsGroupBy]string{
+	entity.GroupByTeam:       "team_id",
+	entity.GroupByDepartment: "department_id",
+}
+
+// punctualityResult is a group of checkInStages documents
+type punctualityResult struct {
+	Key              string  `bson:"_id"`
+	Name             string  `bson:"name"`
+	CheckIns         int     `bson:"check_ins"`
+	Late             int     `bson:"late"`
+	TotalLateMinutes float64 `bson:"total_late_minutes"`
+}
+
+func (r punctualityResult) stats() entity.PunctualityStats {
+	stats := entity.PunctualityStats{
+		Key:              r.Key,
+		Name:             r.Name,
+		CheckIns:         r.CheckIns,
+		Late:             r.Late,
+		TotalLateMinutes: r.TotalLateMinutes,
+	}
+	stats.Calculate()
+	return stats
+}
+
+// checkInStages selects the check-ins of the period and adds minute_of_day, the check-in time
+// in the period's time zone, and late_minutes, the minutes after the schedule's start time
+func checkInStages(period entity.AnalyticsPeriod, schedule entity.WorkSchedule, filter entity.AnalyticsFilter) mongo.Pipeline {
+	match := periodMatch(period, filter)
+	match["jam_masuk"] = bson.M{"$ne": nil}
+
+	startOfDay := bson.M{"$dateTrunc": bson.M{
+		"date":     "$jam_masuk",
+		"unit":     "day",
+		"timezone": period.Start.Location().String(),
+	}}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{
+			"minute_of_day": bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$jam_masuk", startOfDay}}, 60000}},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"late_minutes": bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{"$minute_of_day", schedule.StartTime.Minutes()}}}},
+		}}},
+	}
+}
+
+// memberGroupStages regroups per-user results by the user's team or department and sums the
+// given fields. Users without a team or department end up in the group with an empty key.
+func memberGroupStages(groupBy entity.AnalyticsGroupBy, sumFields ...string) mongo.Pipeline {
+	field, ok := memberGroupFields[groupBy]
+	if !ok {
+		return nil
+	}
+
+	group := bson.M{"_id": bson.M{"$ifNull": bson.A{bson.M{"$first": "$member." + field}, ""}}}
+	for _, sumField := range sumFields {
+		group[sumField] = bson.M{"$sum": "$" + sumField}
+	}
+
+	return append(memberLookupStages("$_id"), bson.D{{Key: "$group", Value: group}})
+}
+
+// memberLookupStages adds member, the users document of the user ID in userID as a one element
+// array, or an empty array when the user does not exist
+func memberLookupStages(userID string) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$addFields", Value: bson.M{
+			"user_oid": bson.M{"$convert": bson.M{"input": userID, "to": "objectId", "onError": nil, "onNull": nil}},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "users",
+			"localField":   "user_oid",
+			"foreignField": "_id",
+			"as":           "member",
+		}}},
+	}
+}
+
+// punctualityPipeline groups the check-ins per user, and then per team or department
+func punctualityPipeline(period entity.AnalyticsPeriod, schedule entity.WorkSchedule, groupBy entity.AnalyticsGroupBy, filter entity.AnalyticsFilter) (mongo.Pipeline, error) {
+	if !groupBy.IsValid() {
+		return nil, entity.ErrInvalidGroupBy
+	}
+
+	pipeline := checkInStages(period, schedule, filter)
+	pipeline = append(pipeline, bson.D{{Key: "$group", Value: bson.M{
+		"_id":                "$user_id",
+		"name":               bson.M{"$last": "$nama"},
+		"check_ins":          bson.M{"$sum": 1},
+		"late":               bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$late_minutes", 0}}, 1, 0}}},
+		"total_late_minutes": bson.M{"$sum": "$late_minutes"},
+	}}})
+	pipeline = append(pipeline, memberGroupStages(groupBy, "check_ins", "late", "total_late_minutes")...)
+
+	return pipeline, nil
+}
+
+func (r *AnalyticsRepository) aggregatePunctuality(ctx context.Context, pipeline mongo.Pipeline) ([]entity.PunctualityStats, error) {
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []punctualityResult
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	stats := make([]entity.PunctualityStats, 0, len(results))
+	for _, result := range results {
+		stats = append(stats, result.stats())
+	}
+	return stats, nil
+}
+
+func (r *AnalyticsRepository) GetPunctuality(ctx context.Context, period entity.AnalyticsPeriod, schedule entity.WorkSchedule, groupBy entity.AnalyticsGroupBy, filter entity.AnalyticsFilter) ([]entity.PunctualityStats, error) {
+	pipeline, err := punctualityPipeline(period, schedule, groupBy, filter)
+	if err != nil {
+		return nil, err
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{
+		{Key: "total_late_minutes", Value: -1},
+		{Key: "_id", Value: 1},
+	}}})
+
+	return r.aggregatePunctuality(ctx, pipeline)
+}
+
+func (r *AnalyticsRepository) GetPunctualityRanking(ctx context.Context, period entity.AnalyticsPeriod, schedule entity.WorkSchedule, ranking entity.PunctualityRanking, limit int, filter entity.AnalyticsFilter) ([]entity.PunctualityStats, error) {
+	pipeline, err := punctualityPipeline(period, schedule, entity.GroupByUser, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var sort bson.D
+	switch ranking {
+	case entity.RankMostLate:
+		sort = bson.D{{Key: "late", Value: -1}, {Key: "total_late_minutes", Value: -1}, {Key: "_id", Value: 1}}
+	case entity.RankMostPunctual:
+		pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: bson.M{
+			"on_time_rate": bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$check_ins", "$late"}}, "$check_ins"}},
+		}}})
+		sort = bson.D{{Key: "on_time_rate", Value: -1}, {Key: "check_ins", Value: -1}, {Key: "total_late_minutes", Value: 1}, {Key: "_id", Value: 1}}
+	default:
+		return nil, fmt.Errorf("unknown punctuality ranking %q", ranking)
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: sort}},
+		bson.D{{Key: "$limit", Value: limit}},
+	)
+
+	return r.aggregatePunctuality(ctx, pipeline)
+}
+
+func (r *AnalyticsRepository) GetArrivalHistogram(ctx context.Context, period entity.AnalyticsPeriod, filter entity.AnalyticsFilter) ([]entity.ArrivalBucket, error) {
+	pipeline := checkInStages(period, entity.WorkSchedule{}, filter)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			// $divide menghasilkan double, dijadikan int agar bisa di-decode ke Minute
+			"_id": bson.M{"$toInt": bson.M{"$multiply": bson.A{
+				bson.M{"$floor": bson.M{"$divide": bson.A{"$minute_of_day", entity.ArrivalBucketMinutes}}},
+				entity.ArrivalBucketMinutes,
+			}}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Minute int `bson:"_id"`
+		Count  int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	// Bucket kosong di antara check-in paling awal dan paling akhir tetap dikembalikan
+	histogram := make([]entity.ArrivalBucket, 0, len(results))
+	for i, result := range results {
+		if i > 0 {
+			for minute := results[i-1].Minute + entity.ArrivalBucketMinutes; minute < result.Minute; minute += entity.ArrivalBucketMinutes {
+				histogram = append(histogram, entity.ArrivalBucket{Time: entity.FormatClock(time.Duration(minute) * time.Minute)})
+			}
+		}
+		histogram = append(histogram, entity.ArrivalBucket{Time: entity.FormatClock(time.Duration(result.Minute) * time.Minute), Count: result.Count})
+	}
+	return histogram, nil
+}
+
+func (r *AnalyticsRepository) GetOnTimeStreaks(ctx context.Context, period entity.AnalyticsPeriod, schedule entity.WorkSchedule, filter entity.AnalyticsFilter) ([]entity.OnTimeStreak, error) {
+	match := periodMatch(period, filter)
+	match["status"] = bson.M{"$in": bson.A{"hadir", "terlambat", "alpha"}}
+
+	startOfDay := bson.M{"$dateTrunc": bson.M{
+		"date":     "$jam_masuk",
+		"unit":     "day",
+		"timezone": period.Start.Location().String(),
+	}}
+	lateAfter := schedule.StartTime.Milliseconds()
+
+	// Setiap hari yang tidak tepat waktu menaikkan misses, sehingga hari-hari tepat waktu
+	// berturut-turut memiliki nilai misses yang sama
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{
+			"missed": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{
+					bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{"$jam_masuk", nil}}, nil}},
+					bson.M{"$lte": bson.A{bson.M{"$subtract": bson.A{"$jam_masuk", startOfDay}}, lateAfter}},
+				}},
+				0, 1,
+			}},
+		}}},
+		{{Key: "$setWindowFields", Value: bson.M{
+			"partitionBy": "$user_id",
+			"sortBy":      bson.M{"tanggal": 1},
+			"output": bson.M{
+				"misses": bson.M{"$sum": "$missed", "window": bson.M{"documents": bson.A{"unbounded", "current"}}},
+			},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"user_id": "$user_id", "misses": "$misses"},
+			"name":   bson.M{"$last": "$nama"},
+			"length": bson.M{"$sum": bson.M{"$subtract": bson.A{1, "$missed"}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.user_id", Value: 1}, {Key: "_id.misses", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$_id.user_id",
+			"name":    bson.M{"$last": "$name"},
+			"current": bson.M{"$last": "$length"},
+			"longest": bson.M{"$max": "$length"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "current", Value: -1}, {Key: "longest", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		UserID  string `bson:"_id"`
+		Name    string `bson:"name"`
+		Current int    `bson:"current"`
+		Longest int    `bson:"longest"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	streaks := make([]entity.OnTimeStreak, 0, len(results))
+	for _, result := range results {
+		streaks = append(streaks, entity.OnTimeStreak{
+			UserID:   result.UserID,
+			UserName: result.Name,
+			Current:  result.Current,
+			Longest:  result.Longest,
+		})
+	}
+	return streaks, nil
+}
@@ -2,6 +2,7 @@ package mongodb
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,163 +14,83 @@ import (
 
 type AnalyticsRepository struct {
 	collection *mongo.Collection
+	rollups    *mongo.Collection // nil when the daily rollups are not read
+	location   *time.Location
 }
 
-func NewAnalyticsRepository(db *mongo.Database) repository.AnalyticsRepository {
-	return &AnalyticsRepository{
+// NewAnalyticsRepository creates the analytics repository, days start at midnight in location.
+// With rollups, status counts for the days before today are read from the daily rollups
+// maintained by AttendanceRollupRepository instead of aggregating every presensi.
+func NewAnalyticsRepository(db *mongo.Database, location *time.Location, rollups bool) repository.AnalyticsRepository {
+	r := &AnalyticsRepository{
 		collection: db.Collection("presensi"),
+		location:   location,
 	}
+	if rollups {
+		r.rollups = db.Collection(rollupCollectionName)
+	}
+	return r
 }
 
 func (r *AnalyticsRepository) GetSummary(ctx context.Context, filter entity.AnalyticsFilter) (*entity.AttendanceSummary, error) {
-	matchStage := bson.M{}
-
-	if filter.UserID != "" {
-		matchStage["user_id"] = filter.UserID
-	}
-	if !filter.StartDate.IsZero() || !filter.EndDate.IsZero() {
-		dateFilter := bson.M{}
-		if !filter.StartDate.IsZero() {
-			dateFilter["$gte"] = filter.StartDate
-		}
-		if !filter.EndDate.IsZero() {
-			dateFilter["$lte"] = filter.EndDate
-		}
-		matchStage["tanggal"] = dateFilter
-	}
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: matchStage}},
-		{{Key: "$group", Value: bson.M{
-			"_id":            nil,
-			"total_records":  bson.M{"$sum": 1},
-			"total_hadir":    bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", "hadir"}}, 1, 0}}},
-			"total_terlambat": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", "terlambat"}}, 1, 0}}},
-			"total_izin":     bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", "izin"}}, 1, 0}}},
-			"total_sakit":    bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", "sakit"}}, 1, 0}}},
-			"total_alpha":    bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", "alpha"}}, 1, 0}}},
-		}}},
-	}
-
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	counts, err := r.countStatuses(ctx, filter, "")
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	var results []bson.M
-	if err := cursor.All(ctx, &results); err != nil {
-		return nil, err
-	}
-
-	summary := &entity.AttendanceSummary{}
-	if len(results) > 0 {
-		result := results[0]
-		summary.TotalRecords = int(result["total_records"].(int32))
-		summary.TotalHadir = int(result["total_hadir"].(int32))
-		summary.TotalTerlambat = int(result["total_terlambat"].(int32))
-		summary.TotalIzin = int(result["total_izin"].(int32))
-		summary.TotalSakit = int(result["total_sakit"].(int32))
-		summary.TotalAlpha = int(result["total_alpha"].(int32))
-		summary.CalculatePercentage()
-	}
-
-	return summary, nil
+	summary := counts[""].summary()
+	return &summary, nil
 }
 
-func (r *AnalyticsRepository) GetDailySummary(ctx context.Context, date string) (*entity.DailySummary, error) {
+func (r *AnalyticsRepository) GetDailySummary(ctx context.Context, date string, filter entity.AnalyticsFilter) (*entity.DailySummary, error) {
 	// Parse date string YYYY-MM-DD
-	parsedDate, err := time.Parse("2006-01-02", date)
+	parsedDate, err := time.ParseInLocation("2006-01-02", date, r.location)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set date range for the entire day
-	startOfDay := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, parsedDate.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	filter := entity.AnalyticsFilter{
-		StartDate: startOfDay,
-		EndDate:   endOfDay,
-	}
-
-	summary, err := r.GetSummary(ctx, filter)
-	if err != nil {
-		return nil, err
-	}
+	filter.StartDate = parsedDate
+	filter.EndDate = parsedDate.AddDate(0, 0, 1)
 
-	breakdown, err := r.GetStatusBreakdown(ctx, filter)
+	counts, err := r.countStatuses(ctx, filter, "")
 	if err != nil {
 		return nil, err
 	}
 
 	return &entity.DailySummary{
 		Date:    parsedDate,
-		Summary: *summary,
-		Details: breakdown,
+		Summary: counts[""].summary(),
+		Details: counts[""].breakdown(),
 	}, nil
 }
 
-func (r *AnalyticsRepository) GetMonthlySummary(ctx context.Context, month string) (*entity.MonthlySummary, error) {
+func (r *AnalyticsRepository) GetMonthlySummary(ctx context.Context, month string, filter entity.AnalyticsFilter) (*entity.MonthlySummary, error) {
 	// Parse month string YYYY-MM
-	parsedMonth, err := time.Parse("2006-01", month)
-	if err != nil {
-		return nil, err
-	}
-
-	// Set date range for the entire month
-	startOfMonth := time.Date(parsedMonth.Year(), parsedMonth.Month(), 1, 0, 0, 0, 0, parsedMonth.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, 0)
-
-	filter := entity.AnalyticsFilter{
-		StartDate: startOfMonth,
-		EndDate:   endOfMonth,
-	}
-
-	summary, err := r.GetSummary(ctx, filter)
+	startOfMonth, err := time.ParseInLocation("2006-01", month, r.location)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get daily stats within the month
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{
-			"tanggal": bson.M{
-				"$gte": startOfMonth,
-				"$lt":  endOfMonth,
-			},
-		}}},
-		{{Key: "$group", Value: bson.M{
-			"_id": bson.M{
-				"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$tanggal"},
-			},
-			"count": bson.M{"$sum": 1},
-		}}},
-		{{Key: "$sort", Value: bson.M{"_id": 1}}},
-	}
+	filter.StartDate = startOfMonth
+	filter.EndDate = startOfMonth.AddDate(0, 1, 0)
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	counts, err := r.countStatuses(ctx, filter, bucketFormats[entity.IntervalDay])
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	var dailyResults []bson.M
-	if err := cursor.All(ctx, &dailyResults); err != nil {
-		return nil, err
-	}
-
-	dailyStats := make([]entity.DailyStats, 0, len(dailyResults))
-	for _, result := range dailyResults {
-		dailyStats = append(dailyStats, entity.DailyStats{
-			Date:  result["_id"].(string),
-			Count: int(result["count"].(int32)),
-		})
+	// Summary bulan adalah jumlah dari semua hari
+	var total statusCounts
+	dailyStats := make([]entity.DailyStats, 0, len(counts))
+	for date, dayCounts := range counts {
+		total.add(dayCounts)
+		dailyStats = append(dailyStats, entity.DailyStats{Date: date, Count: dayCounts.TotalRecords})
 	}
+	sort.Slice(dailyStats, func(i, j int) bool { return dailyStats[i].Date < dailyStats[j].Date })
 
 	return &entity.MonthlySummary{
 		Month:      month,
-		Summary:    *summary,
+		Summary:    total.summary(),
 		DailyStats: dailyStats,
 	}, nil
 }
@@ -219,49 +140,10 @@ func (r *AnalyticsRepository) GetUserSummary(ctx context.Context, userID string,
 }
 
 func (r *AnalyticsRepository) GetStatusBreakdown(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StatusBreakdown, error) {
-	matchStage := bson.M{}
-
-	if filter.UserID != "" {
-		matchStage["user_id"] = filter.UserID
-	}
-	if !filter.StartDate.IsZero() || !filter.EndDate.IsZero() {
-		dateFilter := bson.M{}
-		if !filter.StartDate.IsZero() {
-			dateFilter["$gte"] = filter.StartDate
-		}
-		if !filter.EndDate.IsZero() {
-			dateFilter["$lte"] = filter.EndDate
-		}
-		matchStage["tanggal"] = dateFilter
-	}
-
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: matchStage}},
-		{{Key: "$group", Value: bson.M{
-			"_id":   "$status",
-			"count": bson.M{"$sum": 1},
-		}}},
-		{{Key: "$sort", Value: bson.M{"count": -1}}},
-	}
-
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	counts, err := r.countStatuses(ctx, filter, "")
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
-
-	var results []bson.M
-	if err := cursor.All(ctx, &results); err != nil {
-		return nil, err
-	}
-
-	breakdown := make([]entity.StatusBreakdown, 0, len(results))
-	for _, result := range results {
-		breakdown = append(breakdown, entity.StatusBreakdown{
-			Status: result["_id"].(string),
-			Count:  int(result["count"].(int32)),
-		})
-	}
 
-	return breakdown, nil
+	return counts[""].breakdown(), nil
 }
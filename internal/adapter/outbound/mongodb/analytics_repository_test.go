@@ -0,0 +1,417 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// The tests run against the driver's mock deployment: every aggregation receives the
+// documents queued with AddMockResponses, in order, so the result decoding is exercised
+// with the numeric types MongoDB may return without a running server.
+
+const mockNamespace = "presensi.presensi"
+
+func newMockAnalyticsRepository(mt *mtest.T, rollups bool) *AnalyticsRepository {
+	return NewAnalyticsRepository(mt.DB, time.UTC, rollups).(*AnalyticsRepository)
+}
+
+// cursorResponse returns docs in a single batch
+func cursorResponse(docs ...bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, mockNamespace, mtest.FirstBatch, docs...)
+}
+
+// batchedCursorResponses returns docs over several getMore batches of size
+func batchedCursorResponses(size int, docs []bson.D) []bson.D {
+	var responses []bson.D
+	for start := 0; start < len(docs) || start == 0; start += size {
+		end := min(start+size, len(docs))
+		cursorID := int64(1)
+		if end == len(docs) {
+			cursorID = 0
+		}
+		batch := mtest.NextBatch
+		if start == 0 {
+			batch = mtest.FirstBatch
+		}
+		responses = append(responses, mtest.CreateCursorResponse(cursorID, mockNamespace, batch, docs[start:end]...))
+	}
+	return responses
+}
+
+func TestAnalyticsRepositoryGetSummary(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name string
+		docs []bson.D
+		want entity.AttendanceSummary
+	}{
+		{
+			name: "empty",
+			want: entity.AttendanceSummary{},
+		},
+		{
+			name: "int32 counts",
+			docs: []bson.D{{
+				{Key: "_id", Value: nil},
+				{Key: "total_records", Value: int32(4)},
+				{Key: "total_hadir", Value: int32(2)},
+				{Key: "total_terlambat", Value: int32(1)},
+				{Key: "total_izin", Value: int32(1)},
+				{Key: "total_sakit", Value: int32(0)},
+				{Key: "total_alpha", Value: int32(0)},
+			}},
+			want: entity.AttendanceSummary{TotalRecords: 4, TotalHadir: 2, TotalTerlambat: 1, TotalIzin: 1, PercentageHadir: 75},
+		},
+		{
+			name: "int64 counts beyond int32",
+			docs: []bson.D{{
+				{Key: "_id", Value: nil},
+				{Key: "total_records", Value: int64(math.MaxInt32) * 2},
+				{Key: "total_hadir", Value: int64(math.MaxInt32) * 2},
+				{Key: "total_terlambat", Value: int64(0)},
+				{Key: "total_izin", Value: int64(0)},
+				{Key: "total_sakit", Value: int64(0)},
+				{Key: "total_alpha", Value: int64(0)},
+			}},
+			want: entity.AttendanceSummary{TotalRecords: math.MaxInt32 * 2, TotalHadir: math.MaxInt32 * 2, PercentageHadir: 100},
+		},
+		{
+			name: "mixed int32, int64, double, null and missing fields",
+			docs: []bson.D{{
+				{Key: "_id", Value: nil},
+				{Key: "total_records", Value: int64(10)},
+				{Key: "total_hadir", Value: int32(5)},
+				{Key: "total_terlambat", Value: 3.0},
+				{Key: "total_izin", Value: nil},
+				{Key: "total_alpha", Value: int32(2)},
+			}},
+			want: entity.AttendanceSummary{TotalRecords: 10, TotalHadir: 5, TotalTerlambat: 3, TotalAlpha: 2, PercentageHadir: 80},
+		},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(cursorResponse(tt.docs...))
+
+			got, err := newMockAnalyticsRepository(mt, false).GetSummary(context.Background(), entity.AnalyticsFilter{})
+			if err != nil {
+				t.Fatalf("GetSummary: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("GetSummary = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyticsRepositoryGetSummaryAddsRollupsAndPresensi(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("unbounded range", func(mt *mtest.T) {
+		// Presensi hari ini lebih dulu, lalu rekap hari-hari sebelumnya
+		mt.AddMockResponses(
+			cursorResponse(bson.D{
+				{Key: "_id", Value: nil},
+				{Key: "total_records", Value: int32(2)},
+				{Key: "total_hadir", Value: int32(1)},
+				{Key: "total_sakit", Value: int32(1)},
+			}),
+			cursorResponse(bson.D{
+				{Key: "_id", Value: nil},
+				{Key: "total_records", Value: int64(8)},
+				{Key: "total_hadir", Value: int64(6)},
+				{Key: "total_terlambat", Value: 2.0},
+			}),
+		)
+
+		got, err := newMockAnalyticsRepository(mt, true).GetSummary(context.Background(), entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetSummary: %v", err)
+		}
+		want := entity.AttendanceSummary{TotalRecords: 10, TotalHadir: 7, TotalTerlambat: 2, TotalSakit: 1, PercentageHadir: 90}
+		if *got != want {
+			t.Errorf("GetSummary = %+v, want %+v", *got, want)
+		}
+	})
+
+	mt.Run("past days only read rollups", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse(bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "total_records", Value: int64(3)},
+			{Key: "total_alpha", Value: int64(3)},
+		}))
+
+		filter := entity.AnalyticsFilter{
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		}
+		got, err := newMockAnalyticsRepository(mt, true).GetSummary(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("GetSummary: %v", err)
+		}
+		if got.TotalRecords != 3 || got.TotalAlpha != 3 {
+			t.Errorf("GetSummary = %+v, want 3 alpha records", *got)
+		}
+	})
+}
+
+func TestAnalyticsRepositoryGetDailySummary(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("breakdown sorted by count", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse(bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "total_records", Value: int32(6)},
+			{Key: "total_hadir", Value: int64(1)},
+			{Key: "total_terlambat", Value: 3.0},
+			{Key: "total_izin", Value: int32(2)},
+		}))
+
+		got, err := newMockAnalyticsRepository(mt, false).GetDailySummary(context.Background(), "2024-01-15", entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetDailySummary: %v", err)
+		}
+		want := []entity.StatusBreakdown{{Status: "terlambat", Count: 3}, {Status: "izin", Count: 2}, {Status: "hadir", Count: 1}}
+		if fmt.Sprint(got.Details) != fmt.Sprint(want) {
+			t.Errorf("Details = %v, want %v", got.Details, want)
+		}
+		if !got.Date.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Date = %v, want 2024-01-15", got.Date)
+		}
+	})
+
+	mt.Run("invalid date", func(mt *mtest.T) {
+		if _, err := newMockAnalyticsRepository(mt, false).GetDailySummary(context.Background(), "15-01-2024", entity.AnalyticsFilter{}); err == nil {
+			t.Error("GetDailySummary accepted an invalid date")
+		}
+	})
+}
+
+func TestAnalyticsRepositoryGetMonthlySummary(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("empty", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse())
+
+		got, err := newMockAnalyticsRepository(mt, false).GetMonthlySummary(context.Background(), "2024-02", entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetMonthlySummary: %v", err)
+		}
+		if got.Summary != (entity.AttendanceSummary{}) || len(got.DailyStats) != 0 {
+			t.Errorf("GetMonthlySummary = %+v, want an empty month", *got)
+		}
+	})
+
+	mt.Run("large month over several batches", func(mt *mtest.T) {
+		// Urutan hari sengaja dibalik, hasil harus tetap urut tanggal
+		var docs []bson.D
+		for day := 31; day >= 1; day-- {
+			var count interface{} = int32(day)
+			switch day % 3 {
+			case 1:
+				count = int64(math.MaxInt32) + int64(day)
+			case 2:
+				count = float64(day)
+			}
+			docs = append(docs, bson.D{
+				{Key: "_id", Value: fmt.Sprintf("2024-01-%02d", day)},
+				{Key: "total_records", Value: count},
+				{Key: "total_hadir", Value: count},
+			})
+		}
+		mt.AddMockResponses(batchedCursorResponses(7, docs)...)
+
+		got, err := newMockAnalyticsRepository(mt, false).GetMonthlySummary(context.Background(), "2024-01", entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetMonthlySummary: %v", err)
+		}
+		if len(got.DailyStats) != 31 {
+			t.Fatalf("DailyStats has %d days, want 31", len(got.DailyStats))
+		}
+
+		total := 0
+		for i, stats := range got.DailyStats {
+			if want := fmt.Sprintf("2024-01-%02d", i+1); stats.Date != want {
+				t.Errorf("DailyStats[%d].Date = %s, want %s", i, stats.Date, want)
+			}
+			total += stats.Count
+		}
+		if got.Summary.TotalRecords != total || got.Summary.TotalHadir != total || got.Summary.PercentageHadir != 100 {
+			t.Errorf("Summary = %+v, want %d records all hadir", got.Summary, total)
+		}
+		if got.DailyStats[0].Count != math.MaxInt32+1 {
+			t.Errorf("DailyStats[0].Count = %d, want %d", got.DailyStats[0].Count, math.MaxInt32+1)
+		}
+	})
+
+	mt.Run("fractional double is rejected", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse(bson.D{
+			{Key: "_id", Value: "2024-01-01"},
+			{Key: "total_records", Value: 1.5},
+		}))
+
+		if _, err := newMockAnalyticsRepository(mt, false).GetMonthlySummary(context.Background(), "2024-01", entity.AnalyticsFilter{}); err == nil {
+			t.Error("GetMonthlySummary decoded a fractional count")
+		}
+	})
+}
+
+func TestAnalyticsRepositoryGetUserSummary(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	counts := bson.D{
+		{Key: "_id", Value: nil},
+		{Key: "total_records", Value: int64(2)},
+		{Key: "total_hadir", Value: int32(2)},
+	}
+
+	mt.Run("with name", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			cursorResponse(counts),
+			cursorResponse(counts),
+			cursorResponse(bson.D{{Key: "nama", Value: "Budi"}}),
+		)
+
+		got, err := newMockAnalyticsRepository(mt, false).GetUserSummary(context.Background(), "u1", entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetUserSummary: %v", err)
+		}
+		if got.UserName != "Budi" || got.Summary.TotalHadir != 2 || got.Period != "all-time" {
+			t.Errorf("GetUserSummary = %+v", *got)
+		}
+		if len(got.StatusDetail) != 1 || got.StatusDetail[0] != (entity.StatusBreakdown{Status: "hadir", Count: 2}) {
+			t.Errorf("StatusDetail = %v, want 2 hadir", got.StatusDetail)
+		}
+	})
+
+	mt.Run("without records", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse(), cursorResponse(), cursorResponse())
+
+		got, err := newMockAnalyticsRepository(mt, false).GetUserSummary(context.Background(), "u1", entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetUserSummary: %v", err)
+		}
+		if got.UserName != "" || got.Summary != (entity.AttendanceSummary{}) || len(got.StatusDetail) != 0 {
+			t.Errorf("GetUserSummary = %+v, want an empty summary", *got)
+		}
+	})
+}
+
+func TestAnalyticsRepositoryGetPeriodSummary(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("missing buckets are zero", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse(
+			bson.D{{Key: "_id", Value: "2024-02-05"}, {Key: "total_records", Value: int32(1)}, {Key: "total_hadir", Value: int32(1)}},
+			bson.D{{Key: "_id", Value: "2024-02-07"}, {Key: "total_records", Value: int64(2)}, {Key: "total_alpha", Value: 2.0}},
+		))
+
+		period, err := entity.ParseAnalyticsPeriod(entity.PeriodWeek, "2024-W06", time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := newMockAnalyticsRepository(mt, false).GetPeriodSummary(context.Background(), period, entity.IntervalDay, entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetPeriodSummary: %v", err)
+		}
+		if len(got.Series) != 7 {
+			t.Fatalf("Series has %d buckets, want 7", len(got.Series))
+		}
+		if got.Series[1].Summary.TotalRecords != 0 || got.Series[2].Summary.TotalAlpha != 2 {
+			t.Errorf("Series = %+v", got.Series)
+		}
+		if got.Summary.TotalRecords != 3 || got.Summary.TotalHadir != 1 || got.Summary.TotalAlpha != 2 {
+			t.Errorf("Summary = %+v, want 3 records", got.Summary)
+		}
+	})
+}
+
+func TestAnalyticsRepositoryPunctualityAndHours(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	period, err := entity.ParseAnalyticsPeriod(entity.PeriodMonth, "2024-01", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := entity.WorkSchedule{StartTime: 8 * time.Hour, WorkHours: 8 * time.Hour}
+
+	mt.Run("punctuality with mixed types", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse(
+			bson.D{{Key: "_id", Value: "u1"}, {Key: "name", Value: "A"}, {Key: "check_ins", Value: int32(4)}, {Key: "late", Value: int64(2)}, {Key: "total_late_minutes", Value: int32(30)}},
+			bson.D{{Key: "_id", Value: "u2"}, {Key: "check_ins", Value: 3.0}, {Key: "total_late_minutes", Value: 0.5}},
+		))
+
+		got, err := newMockAnalyticsRepository(mt, false).GetPunctuality(context.Background(), period, schedule, entity.GroupByUser, entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetPunctuality: %v", err)
+		}
+		if len(got) != 2 || got[0].AverageLateMinutes != 15 || got[0].OnTimeRate != 50 || got[1].OnTime != 3 || got[1].Late != 0 {
+			t.Errorf("GetPunctuality = %+v", got)
+		}
+	})
+
+	mt.Run("arrival histogram fills gaps", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse(
+			bson.D{{Key: "_id", Value: int32(450)}, {Key: "count", Value: int64(2)}},
+			bson.D{{Key: "_id", Value: 495.0}, {Key: "count", Value: int32(1)}},
+		))
+
+		got, err := newMockAnalyticsRepository(mt, false).GetArrivalHistogram(context.Background(), period, entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetArrivalHistogram: %v", err)
+		}
+		want := []entity.ArrivalBucket{{Time: "07:30", Count: 2}, {Time: "07:45"}, {Time: "08:00"}, {Time: "08:15", Count: 1}}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("GetArrivalHistogram = %v, want %v", got, want)
+		}
+	})
+
+	mt.Run("streaks", func(mt *mtest.T) {
+		mt.AddMockResponses(cursorResponse(
+			bson.D{{Key: "_id", Value: "u1"}, {Key: "name", Value: "A"}, {Key: "current", Value: int64(3)}, {Key: "longest", Value: int32(5)}},
+			bson.D{{Key: "_id", Value: "u2"}},
+		))
+
+		got, err := newMockAnalyticsRepository(mt, false).GetOnTimeStreaks(context.Background(), period, schedule, entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetOnTimeStreaks: %v", err)
+		}
+		want := []entity.OnTimeStreak{{UserID: "u1", UserName: "A", Current: 3, Longest: 5}, {UserID: "u2"}}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("GetOnTimeStreaks = %v, want %v", got, want)
+		}
+	})
+
+	mt.Run("working hours for many users", func(mt *mtest.T) {
+		docs := make([]bson.D, 0, 5000)
+		for i := 0; i < 5000; i++ {
+			docs = append(docs, bson.D{
+				{Key: "_id", Value: fmt.Sprintf("u%d", i)},
+				{Key: "days", Value: int32(20)},
+				{Key: "completed_days", Value: int64(20)},
+				{Key: "total_hours", Value: int32(160)},
+				{Key: "overtime_hours", Value: 2.5},
+			})
+		}
+		mt.AddMockResponses(batchedCursorResponses(1000, docs)...)
+
+		got, err := newMockAnalyticsRepository(mt, false).GetWorkingHours(context.Background(), period, schedule, entity.GroupByUser, entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("GetWorkingHours: %v", err)
+		}
+		if len(got) != 5000 {
+			t.Fatalf("GetWorkingHours returned %d users, want 5000", len(got))
+		}
+		if last := got[4999]; last.Key != "u4999" || last.AverageHours != 8 || last.OvertimeHours != 2.5 || last.MissingCheckOuts != 0 {
+			t.Errorf("GetWorkingHours[4999] = %+v", last)
+		}
+	})
+}
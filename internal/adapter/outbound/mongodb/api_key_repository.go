@@ -0,0 +1,179 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// apiKeyDocument adalah representasi MongoDB document untuk API key
+type apiKeyDocument struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	Name       string             `bson:"name"`
+	Prefix     string             `bson:"prefix"`
+	KeyHash    string             `bson:"key_hash"`
+	Scopes     []string           `bson:"scopes"`
+	CreatedBy  string             `bson:"created_by"`
+	ExpiresAt  *time.Time         `bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time         `bson:"revoked_at,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
+
+// APIKeyRepository implements repository.APIKeyRepository
+type APIKeyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *mongo.Database) repository.APIKeyRepository {
+	collection := db.Collection("api_keys")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "prefix", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return &APIKeyRepository{
+		collection: collection,
+	}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, apiKey *entity.APIKey) error {
+	doc := toAPIKeyDocument(apiKey)
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return err
+	}
+
+	apiKey.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc apiKeyDocument
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAPIKeyEntity(&doc), nil
+}
+
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	var doc apiKeyDocument
+	err := r.collection.FindOne(ctx, bson.M{"prefix": prefix}).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAPIKeyEntity(&doc), nil
+}
+
+func (r *APIKeyRepository) GetAll(ctx context.Context) ([]entity.APIKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []apiKeyDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	apiKeys := make([]entity.APIKey, len(docs))
+	for i, doc := range docs {
+		apiKeys[i] = *toAPIKeyEntity(&doc)
+	}
+
+	return apiKeys, nil
+}
+
+func (r *APIKeyRepository) Update(ctx context.Context, apiKey *entity.APIKey) error {
+	objectID, err := primitive.ObjectIDFromHex(apiKey.ID)
+	if err != nil {
+		return err
+	}
+
+	doc := toAPIKeyDocument(apiKey)
+	doc.ID = objectID
+
+	_, err = r.collection.ReplaceOne(ctx, bson.M{"_id": objectID}, doc)
+	return err
+}
+
+func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{
+		"$set": bson.M{"last_used_at": at},
+	})
+	return err
+}
+
+func toAPIKeyDocument(k *entity.APIKey) *apiKeyDocument {
+	scopes := make([]string, len(k.Scopes))
+	for i, scope := range k.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return &apiKeyDocument{
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		KeyHash:    k.KeyHash,
+		Scopes:     scopes,
+		CreatedBy:  k.CreatedBy,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+func toAPIKeyEntity(doc *apiKeyDocument) *entity.APIKey {
+	scopes := make([]entity.APIKeyScope, len(doc.Scopes))
+	for i, scope := range doc.Scopes {
+		scopes[i] = entity.APIKeyScope(scope)
+	}
+
+	return &entity.APIKey{
+		ID:         doc.ID.Hex(),
+		Name:       doc.Name,
+		Prefix:     doc.Prefix,
+		KeyHash:    doc.KeyHash,
+		Scopes:     scopes,
+		CreatedBy:  doc.CreatedBy,
+		ExpiresAt:  doc.ExpiresAt,
+		LastUsedAt: doc.LastUsedAt,
+		RevokedAt:  doc.RevokedAt,
+		CreatedAt:  doc.CreatedAt,
+	}
+}
@@ -0,0 +1,303 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+const rollupCollectionName = "presensi_daily_rollups"
+
+// rollupStateID is the document in presensi_rollup_state that records the last full rebuild
+const rollupStateID = "presensi_daily_rollups"
+
+// rollupLockID is the document in presensi_rollup_state held by the instance running a rebuild
+const rollupLockID = "presensi_daily_rollups_rebuild"
+
+// rollupLockDuration bounds how long a rebuild that crashed keeps the lock
+const rollupLockDuration = time.Hour
+
+// rollupCountFields are the status counters stored in every rollup document
+var rollupCountFields = []string{"total_records", "total_hadir", "total_terlambat", "total_izin", "total_sakit", "total_alpha"}
+
+// AttendanceRollupRepository implements repository.AttendanceRollupRepository. Every document
+// holds the status counts of one user on one calendar day in the analytics time zone, with the
+// department the user belonged to when the rollup was written.
+type AttendanceRollupRepository struct {
+	collection *mongo.Collection
+	state      *mongo.Collection
+	presensi   *mongo.Collection
+	users      *mongo.Collection
+	location   *time.Location
+}
+
+// NewAttendanceRollupRepository creates the rollup repository, days start at midnight in location
+func NewAttendanceRollupRepository(db *mongo.Database, location *time.Location) *AttendanceRollupRepository {
+	collection := db.Collection(rollupCollectionName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Key rollup, juga dipakai $merge saat rebuild
+	collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "date", Value: 1},
+			{Key: "user_id", Value: 1},
+			{Key: "department_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return &AttendanceRollupRepository{
+		collection: collection,
+		state:      db.Collection("presensi_rollup_state"),
+		presensi:   db.Collection("presensi"),
+		users:      db.Collection("users"),
+		location:   location,
+	}
+}
+
+var _ repository.AttendanceRollupRepository = (*AttendanceRollupRepository)(nil)
+
+func (r *AttendanceRollupRepository) Refresh(ctx context.Context, userID string, day time.Time) error {
+	start := dayStart(day, r.location)
+	end := start.AddDate(0, 0, 1)
+
+	cursor, err := r.presensi.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"user_id": userID,
+			"tanggal": bson.M{"$gte": start, "$lt": end},
+		}}},
+		{{Key: "$group", Value: statusCountFields(bson.M{"_id": nil})}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var results []statusCounts
+	if err := cursor.All(ctx, &results); err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		_, err := r.collection.DeleteMany(ctx, bson.M{"date": start, "user_id": userID})
+		return err
+	}
+
+	departmentID, err := r.departmentOf(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Rekap dengan department lama dihapus agar hari ini tidak terhitung dua kali
+	if _, err := r.collection.DeleteMany(ctx, bson.M{
+		"date":          start,
+		"user_id":       userID,
+		"department_id": bson.M{"$ne": departmentID},
+	}); err != nil {
+		return err
+	}
+
+	counts := results[0]
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"date": start, "user_id": userID, "department_id": departmentID},
+		bson.M{"$set": bson.M{
+			"total_records":   counts.TotalRecords,
+			"total_hadir":     counts.TotalHadir,
+			"total_terlambat": counts.TotalTerlambat,
+			"total_izin":      counts.TotalIzin,
+			"total_sakit":     counts.TotalSakit,
+			"total_alpha":     counts.TotalAlpha,
+			"updated_at":      time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *AttendanceRollupRepository) Rebuild(ctx context.Context, start, end time.Time) (int64, error) {
+	dates := bson.M{}
+	if !start.IsZero() {
+		start = dayStart(start, r.location)
+		dates["$gte"] = start
+	}
+	if !end.IsZero() {
+		end = dayStart(end, r.location)
+		dates["$lt"] = end
+	}
+
+	match := bson.M{}
+	if len(dates) > 0 {
+		match["tanggal"] = dates
+	}
+	rollupMatch := bson.M{}
+	if len(dates) > 0 {
+		rollupMatch["date"] = dates
+	}
+
+	// Satu rebuild pada satu waktu di semua instance
+	rebuildID := primitive.NewObjectID()
+	started := time.Now()
+	if err := r.lock(ctx, rebuildID, started); err != nil {
+		return 0, err
+	}
+	defer r.unlock(context.WithoutCancel(ctx), rebuildID)
+
+	group := statusCountFields(bson.M{"_id": bson.M{
+		"date": bson.M{"$dateTrunc": bson.M{
+			"date":     "$tanggal",
+			"unit":     "day",
+			"timezone": r.location.String(),
+		}},
+		"user_id": "$user_id",
+	}})
+
+	project := bson.M{
+		"_id":           0,
+		"date":          "$_id.date",
+		"user_id":       "$_id.user_id",
+		"department_id": bson.M{"$ifNull": bson.A{bson.M{"$first": "$member.department_id"}, ""}},
+		"rebuild_id":    rebuildID,
+		"updated_at":    "$$NOW",
+	}
+	for _, field := range rollupCountFields {
+		project[field] = 1
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: group}},
+		{{Key: "$addFields", Value: bson.M{"user_id": "$_id.user_id"}}},
+	}
+	pipeline = append(pipeline, memberLookupStages("$user_id")...)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$project", Value: project}},
+		bson.D{{Key: "$merge", Value: bson.M{
+			"into":           rollupCollectionName,
+			"on":             bson.A{"date", "user_id", "department_id"},
+			"whenMatched":    "replace",
+			"whenNotMatched": "insert",
+		}}},
+	)
+
+	// $merge mengganti rekap di tempat sehingga pembaca tidak pernah melihat rentang yang kosong
+	cursor, err := r.presensi.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	cursor.Close(ctx)
+
+	// Rekap hari yang tidak lagi memiliki presensi tidak ditimpa $merge. Rekap yang diperbarui
+	// Refresh selama rebuild berjalan tetap dipertahankan.
+	stale := bson.M{
+		"rebuild_id": bson.M{"$ne": rebuildID},
+		"updated_at": bson.M{"$lt": started},
+	}
+	if len(dates) > 0 {
+		stale["date"] = dates
+	}
+	if _, err := r.collection.DeleteMany(ctx, stale); err != nil {
+		return 0, err
+	}
+
+	written, err := r.collection.CountDocuments(ctx, rollupMatch)
+	if err != nil {
+		return 0, err
+	}
+
+	// Hanya rebuild seluruh hari yang membuat rekap terkini
+	if len(dates) == 0 {
+		if _, err := r.state.UpdateOne(ctx,
+			bson.M{"_id": rollupStateID},
+			bson.M{"$set": bson.M{"stale": false, "timezone": r.location.String(), "rebuilt_at": time.Now()}},
+			options.Update().SetUpsert(true),
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	return written, nil
+}
+
+// lock takes the rebuild lock, or fails with ErrRollupRebuildRunning while another rebuild holds it
+func (r *AttendanceRollupRepository) lock(ctx context.Context, rebuildID primitive.ObjectID, now time.Time) error {
+	// Lock yang kadaluarsa cocok dengan filter dan diambil alih, lock yang masih berlaku
+	// tidak cocok sehingga upsert gagal dengan duplicate key
+	_, err := r.state.UpdateOne(ctx,
+		bson.M{"_id": rollupLockID, "locked_until": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"owner": rebuildID, "locked_until": now.Add(rollupLockDuration)}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return repository.ErrRollupRebuildRunning
+	}
+	return err
+}
+
+// unlock releases the rebuild lock if it is still held by rebuildID
+func (r *AttendanceRollupRepository) unlock(ctx context.Context, rebuildID primitive.ObjectID) {
+	r.state.DeleteOne(ctx, bson.M{"_id": rollupLockID, "owner": rebuildID})
+}
+
+func (r *AttendanceRollupRepository) IsCurrent(ctx context.Context) (bool, error) {
+	var state struct {
+		Stale    bool   `bson:"stale"`
+		Timezone string `bson:"timezone"`
+	}
+	err := r.state.FindOne(ctx, bson.M{"_id": rollupStateID}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		// Belum pernah di-rebuild penuh, termasuk rekap dari versi tanpa state
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !state.Stale && state.Timezone == r.location.String(), nil
+}
+
+func (r *AttendanceRollupRepository) MarkStale(ctx context.Context) error {
+	_, err := r.state.UpdateOne(ctx,
+		bson.M{"_id": rollupStateID},
+		bson.M{"$set": bson.M{"stale": true, "stale_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// departmentOf returns the user's department, empty for unknown users
+func (r *AttendanceRollupRepository) departmentOf(ctx context.Context, userID string) (string, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", nil
+	}
+
+	var doc struct {
+		DepartmentID string `bson:"department_id"`
+	}
+	err = r.users.FindOne(ctx, bson.M{"_id": objectID}, options.FindOne().SetProjection(bson.M{"department_id": 1})).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	return doc.DepartmentID, err
+}
+
+// dayStart returns midnight of the calendar day containing t in loc
+func dayStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,268 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+func newMockRollupRepository(mt *mtest.T, location *time.Location) *AttendanceRollupRepository {
+	// Constructor membuat index rollup lebih dulu
+	mt.AddMockResponses(mtest.CreateSuccessResponse())
+	return NewAttendanceRollupRepository(mt.DB, location)
+}
+
+func TestAttendanceRollupRepositoryIsCurrent(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		state []bson.D
+		want  bool
+	}{
+		{"never rebuilt", nil, false},
+		{"rebuilt", []bson.D{{{Key: "_id", Value: rollupStateID}, {Key: "stale", Value: false}, {Key: "timezone", Value: "Asia/Jakarta"}}}, true},
+		{"stale", []bson.D{{{Key: "_id", Value: rollupStateID}, {Key: "stale", Value: true}, {Key: "timezone", Value: "Asia/Jakarta"}}}, false},
+		{"other time zone", []bson.D{{{Key: "_id", Value: rollupStateID}, {Key: "stale", Value: false}, {Key: "timezone", Value: "UTC"}}}, false},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			repo := newMockRollupRepository(mt, jakarta)
+			mt.AddMockResponses(cursorResponse(tt.state...))
+
+			current, err := repo.IsCurrent(context.Background())
+			if err != nil {
+				mt.Fatalf("IsCurrent: %v", err)
+			}
+			if current != tt.want {
+				mt.Errorf("IsCurrent = %v, want %v", current, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttendanceRollupRepositoryRebuildMarksCurrent(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	// stateUpdate returns the $set of the update sent to the rollup state document, nil without one
+	stateUpdate := func(mt *mtest.T) bson.Raw {
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName != "update" || event.Command.Lookup("update").StringValue() != "presensi_rollup_state" {
+				continue
+			}
+			update := event.Command.Lookup("updates").Array().Index(0).Value().Document()
+			if update.Lookup("q", "_id").StringValue() != rollupStateID {
+				continue
+			}
+			return update.Lookup("u", "$set").Document()
+		}
+		return nil
+	}
+
+	mt.Run("every day", func(mt *mtest.T) {
+		repo := newMockRollupRepository(mt, time.UTC)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}), // lock
+			cursorResponse(), // $merge
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),                     // stale rollups
+			cursorResponse(bson.D{{Key: "_id", Value: 1}, {Key: "n", Value: int32(3)}}), // count
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),                     // state
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),                     // unlock
+		)
+
+		written, err := repo.Rebuild(context.Background(), time.Time{}, time.Time{})
+		if err != nil {
+			mt.Fatalf("Rebuild: %v", err)
+		}
+		if written != 3 {
+			mt.Errorf("written = %d, want 3", written)
+		}
+
+		set := stateUpdate(mt)
+		if set == nil {
+			mt.Fatal("full rebuild did not record the rollup state")
+		}
+		if set.Lookup("stale").Boolean() || set.Lookup("timezone").StringValue() != "UTC" {
+			mt.Errorf("state = %v, want current in UTC", set)
+		}
+	})
+
+	mt.Run("date range", func(mt *mtest.T) {
+		repo := newMockRollupRepository(mt, time.UTC)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			cursorResponse(),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+			cursorResponse(bson.D{{Key: "_id", Value: 1}, {Key: "n", Value: int32(3)}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		if _, err := repo.Rebuild(context.Background(), start, start.AddDate(0, 1, 0)); err != nil {
+			mt.Fatalf("Rebuild: %v", err)
+		}
+		if set := stateUpdate(mt); set != nil {
+			mt.Errorf("partial rebuild recorded the rollup state %v", set)
+		}
+	})
+}
+
+func TestAttendanceRollupRepositoryRebuildKeepsRollupsReadable(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("merge before deleting stale rollups", func(mt *mtest.T) {
+		repo := newMockRollupRepository(mt, time.UTC)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			cursorResponse(),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+			cursorResponse(bson.D{{Key: "_id", Value: 1}, {Key: "n", Value: int32(3)}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		if _, err := repo.Rebuild(context.Background(), start, start.AddDate(0, 1, 0)); err != nil {
+			mt.Fatalf("Rebuild: %v", err)
+		}
+
+		var commands []string
+		var rebuildID primitive.ObjectID
+		var staleFilter bson.Raw
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName == "createIndexes" {
+				continue
+			}
+			commands = append(commands, event.CommandName+" "+event.Command.Lookup(event.CommandName).StringValue())
+			switch {
+			case event.CommandName == "aggregate":
+				stages, _ := event.Command.Lookup("pipeline").Array().Values()
+				for _, stage := range stages {
+					if project, ok := stage.Document().Lookup("$project").DocumentOK(); ok {
+						rebuildID = project.Lookup("rebuild_id").ObjectID()
+					}
+				}
+			case event.CommandName == "delete" && event.Command.Lookup("delete").StringValue() == rollupCollectionName:
+				staleFilter = event.Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+			}
+		}
+
+		want := []string{
+			"update presensi_rollup_state",
+			"aggregate presensi",
+			"delete " + rollupCollectionName,
+			"aggregate " + rollupCollectionName,
+			"delete presensi_rollup_state",
+		}
+		if len(commands) != len(want) {
+			mt.Fatalf("commands = %v, want %v", commands, want)
+		}
+		for i := range want {
+			if commands[i] != want[i] {
+				mt.Fatalf("commands = %v, want %v", commands, want)
+			}
+		}
+
+		// Hanya rekap dalam rentang yang tidak ditulis rebuild ini dan tidak di-refresh sejak rebuild mulai
+		if staleFilter == nil || rebuildID.IsZero() {
+			mt.Fatal("rebuild did not tag rollups or delete stale ones")
+		}
+		if staleFilter.Lookup("rebuild_id", "$ne").ObjectID() != rebuildID {
+			mt.Errorf("stale filter = %v, want rollups of other rebuilds", staleFilter)
+		}
+		if _, ok := staleFilter.Lookup("updated_at", "$lt").DateTimeOK(); !ok {
+			mt.Errorf("stale filter = %v, want rollups updated before the rebuild", staleFilter)
+		}
+		if _, ok := staleFilter.Lookup("date", "$gte").DateTimeOK(); !ok {
+			mt.Errorf("stale filter = %v, want the rebuilt range only", staleFilter)
+		}
+	})
+}
+
+func TestAttendanceRollupRepositoryRebuildLock(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("held by another instance", func(mt *mtest.T) {
+		repo := newMockRollupRepository(mt, time.UTC)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index: 0, Code: 11000, Message: "E11000 duplicate key error",
+		}))
+
+		if _, err := repo.Rebuild(context.Background(), time.Time{}, time.Time{}); !errors.Is(err, repository.ErrRollupRebuildRunning) {
+			mt.Fatalf("err = %v, want %v", err, repository.ErrRollupRebuildRunning)
+		}
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName == "aggregate" || event.CommandName == "delete" {
+				mt.Errorf("rebuild without the lock sent %s", event.CommandName)
+			}
+		}
+	})
+
+	mt.Run("released after a failed rebuild", func(mt *mtest.T) {
+		repo := newMockRollupRepository(mt, time.UTC)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "merge failed"}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		if _, err := repo.Rebuild(context.Background(), time.Time{}, time.Time{}); err == nil {
+			mt.Fatal("Rebuild succeeded, want the $merge error")
+		}
+
+		var lockOwner, unlockOwner primitive.ObjectID
+		for _, event := range mt.GetAllStartedEvents() {
+			switch event.CommandName {
+			case "update":
+				update := event.Command.Lookup("updates").Array().Index(0).Value().Document()
+				lockOwner = update.Lookup("u", "$set", "owner").ObjectID()
+			case "delete":
+				if event.Command.Lookup("delete").StringValue() == rollupCollectionName {
+					mt.Error("failed rebuild deleted rollups")
+					continue
+				}
+				filter := event.Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+				unlockOwner = filter.Lookup("owner").ObjectID()
+			}
+		}
+		if lockOwner.IsZero() || unlockOwner != lockOwner {
+			mt.Errorf("unlock owner = %v, want the lock owner %v", unlockOwner, lockOwner)
+		}
+	})
+}
+
+func TestAttendanceRollupRepositoryMarkStale(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("mark stale", func(mt *mtest.T) {
+		repo := newMockRollupRepository(mt, time.UTC)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if err := repo.MarkStale(context.Background()); err != nil {
+			mt.Fatalf("MarkStale: %v", err)
+		}
+
+		event := mt.GetStartedEvent()
+		for event != nil && event.CommandName != "update" {
+			event = mt.GetStartedEvent()
+		}
+		if event == nil {
+			mt.Fatal("MarkStale sent no update")
+		}
+		update := event.Command.Lookup("updates").Array().Index(0).Value().Document()
+		if !update.Lookup("u", "$set", "stale").Boolean() || !update.Lookup("upsert").Boolean() {
+			mt.Errorf("update = %v, want an upsert setting stale", update)
+		}
+	})
+}
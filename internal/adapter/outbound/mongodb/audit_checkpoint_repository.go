@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// auditCheckpointDocument adalah representasi MongoDB document untuk checkpoint hash chain audit
+type auditCheckpointDocument struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Sequence  int64              `bson:"sequence"`
+	Hash      string             `bson:"hash"`
+	Signature string             `bson:"signature"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// AuditCheckpointRepository implements repository.AuditCheckpointRepository.
+// Checkpoints are kept in their own collection without TTL so that they outlive the entries.
+type AuditCheckpointRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditCheckpointRepository creates a new audit checkpoint repository
+func NewAuditCheckpointRepository(db *mongo.Database) repository.AuditCheckpointRepository {
+	collection := db.Collection("audit_checkpoints")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "sequence", Value: 1}},
+	})
+
+	return &AuditCheckpointRepository{
+		collection: collection,
+	}
+}
+
+func (r *AuditCheckpointRepository) Create(ctx context.Context, checkpoint *entity.AuditCheckpoint) error {
+	result, err := r.collection.InsertOne(ctx, auditCheckpointDocument{
+		Sequence:  checkpoint.Sequence,
+		Hash:      checkpoint.Hash,
+		Signature: checkpoint.Signature,
+		CreatedAt: checkpoint.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	checkpoint.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *AuditCheckpointRepository) GetAll(ctx context.Context) ([]entity.AuditCheckpoint, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}, {Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []auditCheckpointDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	checkpoints := make([]entity.AuditCheckpoint, len(docs))
+	for i, doc := range docs {
+		checkpoints[i] = *toAuditCheckpointEntity(&doc)
+	}
+
+	return checkpoints, nil
+}
+
+func (r *AuditCheckpointRepository) GetLatest(ctx context.Context) (*entity.AuditCheckpoint, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}, {Key: "created_at", Value: -1}})
+
+	var doc auditCheckpointDocument
+	err := r.collection.FindOne(ctx, bson.M{}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toAuditCheckpointEntity(&doc), nil
+}
+
+func toAuditCheckpointEntity(doc *auditCheckpointDocument) *entity.AuditCheckpoint {
+	return &entity.AuditCheckpoint{
+		ID:        doc.ID.Hex(),
+		Sequence:  doc.Sequence,
+		Hash:      doc.Hash,
+		Signature: doc.Signature,
+		CreatedAt: doc.CreatedAt,
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// auditCursor is the position of the last entry of a page. It records the sort it was
+// created for, so a cursor cannot be reused with another order.
+type auditCursor struct {
+	Field     repository.AuditLogSortField `json:"f"`
+	Ascending bool                         `json:"a,omitempty"`
+	Value     *int64                       `json:"v,omitempty"` // nil when the entry has no value for the field
+	ID        string                       `json:"id"`
+
+	objectID primitive.ObjectID
+}
+
+func normalizeAuditLogSort(sort repository.AuditLogSort) repository.AuditLogSort {
+	if !sort.Field.IsValid() {
+		sort.Field = repository.AuditLogSortCreatedAt
+	}
+	return sort
+}
+
+// auditLogSortKeys sorts by the field and then by _id, so entries with the same value keep a stable order
+func auditLogSortKeys(sort repository.AuditLogSort) bson.D {
+	sort = normalizeAuditLogSort(sort)
+	direction := -1
+	if sort.Ascending {
+		direction = 1
+	}
+	return bson.D{{Key: string(sort.Field), Value: direction}, {Key: "_id", Value: direction}}
+}
+
+func encodeAuditCursor(auditLog *entity.AuditLog, sort repository.AuditLogSort) string {
+	cursor := auditCursor{Field: sort.Field, Ascending: sort.Ascending, ID: auditLog.ID}
+
+	var value int64
+	switch sort.Field {
+	case repository.AuditLogSortCreatedAt:
+		value = auditLog.CreatedAt.UnixMilli()
+	case repository.AuditLogSortDuration:
+		value = auditLog.Duration
+	case repository.AuditLogSortStatusCode:
+		value = int64(auditLog.StatusCode)
+	}
+	// Durasi dan status code nol tidak disimpan (omitempty)
+	if value != 0 || sort.Field == repository.AuditLogSortCreatedAt {
+		cursor.Value = &value
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAuditCursor(encoded string, sort repository.AuditLogSort) (*auditCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, repository.ErrInvalidAuditCursor
+	}
+
+	var cursor auditCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, repository.ErrInvalidAuditCursor
+	}
+	if cursor.Field != sort.Field || cursor.Ascending != sort.Ascending {
+		return nil, repository.ErrInvalidAuditCursor
+	}
+	if cursor.Value == nil && cursor.Field == repository.AuditLogSortCreatedAt {
+		return nil, repository.ErrInvalidAuditCursor
+	}
+
+	cursor.objectID, err = primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return nil, repository.ErrInvalidAuditCursor
+	}
+	return &cursor, nil
+}
+
+// after returns the $or conditions that select the entries following the cursor. Missing
+// values sort before every number, so they come last in descending and first in ascending order.
+func (c *auditCursor) after(sort repository.AuditLogSort) bson.A {
+	field := string(sort.Field)
+	compare := "$lt"
+	if sort.Ascending {
+		compare = "$gt"
+	}
+
+	if c.Value == nil {
+		conditions := bson.A{bson.M{field: nil, "_id": bson.M{compare: c.objectID}}}
+		if sort.Ascending {
+			conditions = append(conditions, bson.M{field: bson.M{"$ne": nil}})
+		}
+		return conditions
+	}
+
+	var value interface{} = *c.Value
+	if sort.Field == repository.AuditLogSortCreatedAt {
+		value = time.UnixMilli(*c.Value).UTC()
+	}
+
+	conditions := bson.A{
+		bson.M{field: bson.M{compare: value}},
+		bson.M{field: value, "_id": bson.M{compare: c.objectID}},
+	}
+	if !sort.Ascending {
+		conditions = append(conditions, bson.M{field: nil})
+	}
+	return conditions
+}
@@ -10,6 +10,8 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -40,11 +42,26 @@ type auditLogDocument struct {
 	ErrorMsg   string             `bson:"error_msg,omitempty"`
 	Duration   int64              `bson:"duration_ms,omitempty"`
 	CreatedAt  time.Time          `bson:"created_at"`
+	Sequence   int64              `bson:"sequence,omitempty"`
+	PrevHash   string             `bson:"prev_hash,omitempty"`
+	Hash       string             `bson:"hash,omitempty"`
+
+	Archived    bool   `bson:"archived,omitempty"`
+	ArchiveName string `bson:"archive_name,omitempty"`
 }
 
+// maxChainAppendAttempts bounds retries when another instance appends to the chain concurrently
+const maxChainAppendAttempts = 5
+
+var ErrAuditChainConflict = errors.New("gagal menambahkan audit log ke hash chain")
+
 // AuditLogRepository implements repository.AuditLogRepository
 type AuditLogRepository struct {
 	collection *mongo.Collection
+
+	// appendMu serializes appends within this process; the unique sequence index
+	// resolves races between instances
+	appendMu sync.Mutex
 }
 
 // NewAuditLogRepository creates a new audit log repository
@@ -69,11 +86,43 @@ func NewAuditLogRepository(db *mongo.Database) repository.AuditLogRepository {
 			Keys: bson.D{{Key: "action", Value: 1}},
 		},
 		{
-			Keys:    bson.D{{Key: "created_at", Value: 1}},
-			Options: options.Index().SetExpireAfterSeconds(90 * 24 * 60 * 60), // 90 days TTL
+			// Entri lama sebelum hash chain tidak memiliki sequence
+			Keys: bson.D{{Key: "sequence", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"sequence": bson.M{"$gt": 0},
+			}),
+		},
+		{
+			// Retention mencari entri per entity type yang lebih lama dari batas waktu
+			Keys: bson.D{{Key: "entity_type", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "request_id", Value: 1}},
+		},
+		{
+			// Pencarian teks pada pesan error dan nilai yang berubah
+			Keys: bson.D{
+				{Key: "error_msg", Value: "text"},
+				{Key: "changes", Value: "text"},
+				{Key: "old_value", Value: "text"},
+				{Key: "new_value", Value: "text"},
+			},
+			Options: options.Index().SetName("audit_text_search").SetDefaultLanguage("none"),
+		},
+		// Urutan yang didukung beserta _id sebagai pemisah untuk pagination cursor
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "duration_ms", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status_code", Value: -1}, {Key: "_id", Value: -1}},
 		},
 	}
 
+	// Retention dan arsip menggantikan TTL index 90 hari yang lama, yang menghapus entri tanpa arsip
+	collection.Indexes().DropOne(ctx, "created_at_1")
 	collection.Indexes().CreateMany(ctx, indexes)
 
 	return &AuditLogRepository{
@@ -82,14 +131,140 @@ func NewAuditLogRepository(db *mongo.Database) repository.AuditLogRepository {
 }
 
 func (r *AuditLogRepository) Create(ctx context.Context, auditLog *entity.AuditLog) error {
-	doc := toAuditLogDocument(auditLog)
-	result, err := r.collection.InsertOne(ctx, doc)
+	return r.CreateMany(ctx, []*entity.AuditLog{auditLog})
+}
+
+func (r *AuditLogRepository) CreateMany(ctx context.Context, auditLogs []*entity.AuditLog) error {
+	r.appendMu.Lock()
+	defer r.appendMu.Unlock()
+
+	// ID diberikan sebelum insert agar percobaan ulang dapat mengenali entri yang sudah tersimpan
+	retried := false
+	for _, auditLog := range auditLogs {
+		if auditLog.ID != "" {
+			retried = true
+			continue
+		}
+		auditLog.ID = primitive.NewObjectID().Hex()
+	}
+
+	pending := auditLogs
+	if retried {
+		var err error
+		if pending, err = r.withoutStored(ctx, auditLogs); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; attempt < maxChainAppendAttempts; attempt++ {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		prev, err := r.GetLast(ctx)
+		if err != nil {
+			return err
+		}
+		docs := make([]interface{}, len(pending))
+		for i, auditLog := range pending {
+			auditLog.Chain(prev)
+			prev = auditLog
+			docs[i] = toAuditLogDocument(auditLog)
+		}
+
+		_, err = r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(true))
+		if err == nil {
+			return nil
+		}
+
+		// Instance lain menambahkan entri dengan sequence yang sama. Insert berurutan berhenti
+		// di entri pertama yang gagal, sisanya diulang dari ujung chain yang baru.
+		var bulkErr mongo.BulkWriteException
+		if !mongo.IsDuplicateKeyError(err) || !errors.As(err, &bulkErr) || len(bulkErr.WriteErrors) == 0 {
+			return err
+		}
+		pending = pending[bulkErr.WriteErrors[0].Index:]
+	}
+
+	return ErrAuditChainConflict
+}
+
+// withoutStored returns the entries whose ID is not stored yet
+func (r *AuditLogRepository) withoutStored(ctx context.Context, auditLogs []*entity.AuditLog) ([]*entity.AuditLog, error) {
+	ids := make([]primitive.ObjectID, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		if objectID, err := primitive.ObjectIDFromHex(auditLog.ID); err == nil {
+			ids = append(ids, objectID)
+		}
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	stored := make(map[string]bool)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		stored[doc.ID.Hex()] = true
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := make([]*entity.AuditLog, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		if !stored[auditLog.ID] {
+			pending = append(pending, auditLog)
+		}
+	}
+	return pending, nil
+}
+
+func (r *AuditLogRepository) GetLast(ctx context.Context) (*entity.AuditLog, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}})
+
+	var doc auditLogDocument
+	err := r.collection.FindOne(ctx, bson.M{"sequence": bson.M{"$gt": 0}}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toAuditLogEntity(&doc), nil
+}
+
+func (r *AuditLogRepository) WalkChain(ctx context.Context, fromSequence int64, fn func(*entity.AuditLog) error) error {
+	if fromSequence < 1 {
+		fromSequence = 1
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"sequence": bson.M{"$gte": fromSequence}}, opts)
 	if err != nil {
 		return err
 	}
+	defer cursor.Close(ctx)
 
-	auditLog.ID = result.InsertedID.(primitive.ObjectID).Hex()
-	return nil
+	for cursor.Next(ctx) {
+		var doc auditLogDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if err := fn(toAuditLogEntity(&doc)); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
 }
 
 func (r *AuditLogRepository) GetByID(ctx context.Context, id string) (*entity.AuditLog, error) {
@@ -107,7 +282,7 @@ func (r *AuditLogRepository) GetByID(ctx context.Context, id string) (*entity.Au
 	return toAuditLogEntity(&doc), nil
 }
 
-func (r *AuditLogRepository) GetAll(ctx context.Context, filter repository.AuditLogFilter, page, limit int) ([]entity.AuditLog, int64, error) {
+func (r *AuditLogRepository) GetAll(ctx context.Context, filter repository.AuditLogFilter, sort repository.AuditLogSort, page, limit int) ([]entity.AuditLog, int64, error) {
 	bsonFilter := buildAuditLogFilter(filter)
 
 	total, err := r.collection.CountDocuments(ctx, bsonFilter)
@@ -119,25 +294,63 @@ func (r *AuditLogRepository) GetAll(ctx context.Context, filter repository.Audit
 	opts := options.Find().
 		SetSkip(skip).
 		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
+		SetSort(auditLogSortKeys(sort))
 
-	cursor, err := r.collection.Find(ctx, bsonFilter, opts)
+	auditLogs, err := r.find(ctx, bsonFilter, opts)
 	if err != nil {
 		return nil, 0, err
 	}
+
+	return auditLogs, total, nil
+}
+
+func (r *AuditLogRepository) GetAllByCursor(ctx context.Context, filter repository.AuditLogFilter, sort repository.AuditLogSort, cursor string, limit int) (*repository.AuditLogPage, error) {
+	sort = normalizeAuditLogSort(sort)
+	bsonFilter := buildAuditLogFilter(filter)
+
+	if cursor != "" {
+		position, err := decodeAuditCursor(cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		bsonFilter["$or"] = position.after(sort)
+	}
+
+	// Satu entri tambahan menandakan masih ada halaman berikutnya
+	opts := options.Find().
+		SetLimit(int64(limit) + 1).
+		SetSort(auditLogSortKeys(sort))
+
+	auditLogs, err := r.find(ctx, bsonFilter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &repository.AuditLogPage{Items: auditLogs}
+	if len(auditLogs) > limit {
+		page.Items = auditLogs[:limit]
+		page.NextCursor = encodeAuditCursor(&page.Items[limit-1], sort)
+	}
+	return page, nil
+}
+
+func (r *AuditLogRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]entity.AuditLog, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
 	defer cursor.Close(ctx)
 
 	var docs []auditLogDocument
 	if err := cursor.All(ctx, &docs); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
 	auditLogs := make([]entity.AuditLog, len(docs))
 	for i, doc := range docs {
 		auditLogs[i] = *toAuditLogEntity(&doc)
 	}
-
-	return auditLogs, total, nil
+	return auditLogs, nil
 }
 
 func (r *AuditLogRepository) GetByEntityID(ctx context.Context, entityType, entityID string) ([]entity.AuditLog, error) {
@@ -199,23 +412,113 @@ func (r *AuditLogRepository) GetByUserID(ctx context.Context, userID string, pag
 	return auditLogs, total, nil
 }
 
-func (r *AuditLogRepository) DeleteOldLogs(ctx context.Context, olderThan time.Time) (int64, error) {
-	result, err := r.collection.DeleteMany(ctx, bson.M{
-		"created_at": bson.M{"$lt": olderThan},
+func (r *AuditLogRepository) WalkUnarchived(ctx context.Context, filter repository.AuditLogFilter, fn func(*entity.AuditLog) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, buildAuditLogFilter(filter), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc auditLogDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if err := fn(toAuditLogEntity(&doc)); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (r *AuditLogRepository) MarkArchived(ctx context.Context, ids []string, archiveName string) (int64, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return 0, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	// Sequence, hash dan waktu tetap disimpan agar hash chain masih bisa diverifikasi
+	result, err := r.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": objectIDs}}, bson.M{
+		"$set": bson.M{
+			"archived":     true,
+			"archive_name": archiveName,
+		},
+		"$unset": bson.M{
+			"entity_id":   "",
+			"old_value":   "",
+			"new_value":   "",
+			"changes":     "",
+			"user_id":     "",
+			"user_name":   "",
+			"user_role":   "",
+			"ip_address":  "",
+			"user_agent":  "",
+			"request_id":  "",
+			"status_code": "",
+			"error_msg":   "",
+			"duration_ms": "",
+		},
 	})
 	if err != nil {
 		return 0, err
 	}
-	return result.DeletedCount, nil
+	return result.ModifiedCount, nil
+}
+
+func (r *AuditLogRepository) Restore(ctx context.Context, auditLog *entity.AuditLog) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(auditLog.ID)
+	if err != nil {
+		return false, nil
+	}
+
+	// Hanya stub arsip dengan hash yang sama yang boleh diisi kembali
+	filter := bson.M{"_id": objectID, "archived": true, "hash": auditLog.Hash}
+	if auditLog.Hash == "" {
+		filter["hash"] = bson.M{"$exists": false}
+	}
+
+	doc := toAuditLogDocument(auditLog)
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"entity_id":   doc.EntityID,
+			"old_value":   doc.OldValue,
+			"new_value":   doc.NewValue,
+			"changes":     doc.Changes,
+			"user_id":     doc.UserID,
+			"user_name":   doc.UserName,
+			"user_role":   doc.UserRole,
+			"ip_address":  doc.IPAddress,
+			"user_agent":  doc.UserAgent,
+			"request_id":  doc.RequestID,
+			"status_code": doc.StatusCode,
+			"error_msg":   doc.ErrorMsg,
+			"duration_ms": doc.Duration,
+		},
+		// archive_name tetap disimpan agar retention berikutnya tidak menulis arsip yang sama lagi
+		"$unset": bson.M{"archived": ""},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
 }
 
 // Helper functions
 
 func buildAuditLogFilter(filter repository.AuditLogFilter) bson.M {
-	bsonFilter := bson.M{}
+	// Stub entri yang sudah diarsip tidak memiliki isi
+	bsonFilter := bson.M{"archived": bson.M{"$ne": true}}
 
 	if filter.EntityType != "" {
 		bsonFilter["entity_type"] = filter.EntityType
+	} else if len(filter.ExcludeEntityTypes) > 0 {
+		bsonFilter["entity_type"] = bson.M{"$nin": filter.ExcludeEntityTypes}
 	}
 	if filter.EntityID != "" {
 		bsonFilter["entity_id"] = filter.EntityID
@@ -229,6 +532,18 @@ func buildAuditLogFilter(filter repository.AuditLogFilter) bson.M {
 	if filter.IPAddress != "" {
 		bsonFilter["ip_address"] = filter.IPAddress
 	}
+	if filter.RequestID != "" {
+		bsonFilter["request_id"] = filter.RequestID
+	}
+	if filter.Search != "" {
+		bsonFilter["$text"] = bson.M{"$search": filter.Search}
+	}
+	if statusFilter := rangeFilter(int64(filter.StatusCodeMin), int64(filter.StatusCodeMax)); statusFilter != nil {
+		bsonFilter["status_code"] = statusFilter
+	}
+	if durationFilter := rangeFilter(filter.MinDuration, filter.MaxDuration); durationFilter != nil {
+		bsonFilter["duration_ms"] = durationFilter
+	}
 	if !filter.StartDate.IsZero() || !filter.EndDate.IsZero() {
 		dateFilter := bson.M{}
 		if !filter.StartDate.IsZero() {
@@ -243,45 +558,73 @@ func buildAuditLogFilter(filter repository.AuditLogFilter) bson.M {
 	return bsonFilter
 }
 
+// rangeFilter returns an inclusive range condition, nil when both bounds are zero
+func rangeFilter(min, max int64) bson.M {
+	if min <= 0 && max <= 0 {
+		return nil
+	}
+	condition := bson.M{}
+	if min > 0 {
+		condition["$gte"] = min
+	}
+	if max > 0 {
+		condition["$lte"] = max
+	}
+	return condition
+}
+
 func toAuditLogDocument(a *entity.AuditLog) *auditLogDocument {
+	// ID kosong dibiarkan nol sehingga MongoDB membuat ID baru
+	objectID, _ := primitive.ObjectIDFromHex(a.ID)
+
 	return &auditLogDocument{
-		EntityType: a.EntityType,
-		EntityID:   a.EntityID,
-		Action:     string(a.Action),
-		OldValue:   a.OldValue,
-		NewValue:   a.NewValue,
-		Changes:    a.Changes,
-		UserID:     a.UserID,
-		UserName:   a.UserName,
-		UserRole:   a.UserRole,
-		IPAddress:  a.IPAddress,
-		UserAgent:  a.UserAgent,
-		RequestID:  a.RequestID,
-		StatusCode: a.StatusCode,
-		ErrorMsg:   a.ErrorMsg,
-		Duration:   a.Duration,
-		CreatedAt:  a.CreatedAt,
+		ID:          objectID,
+		EntityType:  a.EntityType,
+		EntityID:    a.EntityID,
+		Action:      string(a.Action),
+		OldValue:    a.OldValue,
+		NewValue:    a.NewValue,
+		Changes:     a.Changes,
+		UserID:      a.UserID,
+		UserName:    a.UserName,
+		UserRole:    a.UserRole,
+		IPAddress:   a.IPAddress,
+		UserAgent:   a.UserAgent,
+		RequestID:   a.RequestID,
+		StatusCode:  a.StatusCode,
+		ErrorMsg:    a.ErrorMsg,
+		Duration:    a.Duration,
+		CreatedAt:   a.CreatedAt,
+		Sequence:    a.Sequence,
+		PrevHash:    a.PrevHash,
+		Hash:        a.Hash,
+		ArchiveName: a.ArchiveName,
 	}
 }
 
 func toAuditLogEntity(doc *auditLogDocument) *entity.AuditLog {
 	return &entity.AuditLog{
-		ID:         doc.ID.Hex(),
-		EntityType: doc.EntityType,
-		EntityID:   doc.EntityID,
-		Action:     entity.AuditAction(doc.Action),
-		OldValue:   doc.OldValue,
-		NewValue:   doc.NewValue,
-		Changes:    doc.Changes,
-		UserID:     doc.UserID,
-		UserName:   doc.UserName,
-		UserRole:   doc.UserRole,
-		IPAddress:  doc.IPAddress,
-		UserAgent:  doc.UserAgent,
-		RequestID:  doc.RequestID,
-		StatusCode: doc.StatusCode,
-		ErrorMsg:   doc.ErrorMsg,
-		Duration:   doc.Duration,
-		CreatedAt:  doc.CreatedAt,
+		ID:          doc.ID.Hex(),
+		EntityType:  doc.EntityType,
+		EntityID:    doc.EntityID,
+		Action:      entity.AuditAction(doc.Action),
+		OldValue:    doc.OldValue,
+		NewValue:    doc.NewValue,
+		Changes:     doc.Changes,
+		UserID:      doc.UserID,
+		UserName:    doc.UserName,
+		UserRole:    doc.UserRole,
+		IPAddress:   doc.IPAddress,
+		UserAgent:   doc.UserAgent,
+		RequestID:   doc.RequestID,
+		StatusCode:  doc.StatusCode,
+		ErrorMsg:    doc.ErrorMsg,
+		Duration:    doc.Duration,
+		CreatedAt:   doc.CreatedAt,
+		Sequence:    doc.Sequence,
+		PrevHash:    doc.PrevHash,
+		Hash:        doc.Hash,
+		Archived:    doc.Archived,
+		ArchiveName: doc.ArchiveName,
 	}
 }
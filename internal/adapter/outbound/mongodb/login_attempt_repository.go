@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// loginAttemptDocument adalah representasi MongoDB document untuk percobaan login
+type loginAttemptDocument struct {
+	Email        string    `bson:"_id"`
+	FailedCount  int       `bson:"failed_count"`
+	LastFailedAt time.Time `bson:"last_failed_at"`
+	LockedUntil  time.Time `bson:"locked_until,omitempty"`
+}
+
+// LoginAttemptRepository implements repository.LoginAttemptRepository
+type LoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(db *mongo.Database) repository.LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		collection: db.Collection("login_attempts"),
+	}
+}
+
+func (r *LoginAttemptRepository) Get(ctx context.Context, email string) (*entity.LoginAttempt, error) {
+	email = normalizeEmail(email)
+
+	var doc loginAttemptDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": email}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return &entity.LoginAttempt{Email: email}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toLoginAttemptEntity(&doc), nil
+}
+
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, email string, at, windowStart time.Time) (*entity.LoginAttempt, error) {
+	// Pipeline update agar reset window dan increment terjadi dalam satu operasi atomik
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failed_count": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$last_failed_at", windowStart}},
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failed_count", 0}}, 1}},
+				1,
+			}},
+			"last_failed_at": at,
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var doc loginAttemptDocument
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": normalizeEmail(email)}, update, opts).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return toLoginAttemptEntity(&doc), nil
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, email string, until time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": normalizeEmail(email)},
+		bson.M{"$set": bson.M{"locked_until": until}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, email string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": normalizeEmail(email)})
+	return err
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func toLoginAttemptEntity(doc *loginAttemptDocument) *entity.LoginAttempt {
+	return &entity.LoginAttempt{
+		Email:        doc.Email,
+		FailedCount:  doc.FailedCount,
+		LastFailedAt: doc.LastFailedAt,
+		LockedUntil:  doc.LockedUntil,
+	}
+}
@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// departmentDocument adalah representasi MongoDB document untuk department
+type departmentDocument struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Name      string             `bson:"name"`
+	ParentID  string             `bson:"parent_id,omitempty"`
+	ManagerID string             `bson:"manager_id,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+// teamDocument adalah representasi MongoDB document untuk team
+type teamDocument struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	Name         string             `bson:"name"`
+	DepartmentID string             `bson:"department_id"`
+	ManagerID    string             `bson:"manager_id,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at"`
+}
+
+// DepartmentRepository implements repository.DepartmentRepository
+type DepartmentRepository struct {
+	collection *mongo.Collection
+}
+
+// NewDepartmentRepository creates a new department repository
+func NewDepartmentRepository(db *mongo.Database) repository.DepartmentRepository {
+	return &DepartmentRepository{
+		collection: db.Collection("departments"),
+	}
+}
+
+func (r *DepartmentRepository) Create(ctx context.Context, department *entity.Department) error {
+	result, err := r.collection.InsertOne(ctx, toDepartmentDocument(department))
+	if err != nil {
+		return err
+	}
+
+	department.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *DepartmentRepository) GetByID(ctx context.Context, id string) (*entity.Department, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc departmentDocument
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return toDepartmentEntity(&doc), nil
+}
+
+func (r *DepartmentRepository) GetAll(ctx context.Context) ([]entity.Department, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []departmentDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	departments := make([]entity.Department, len(docs))
+	for i, doc := range docs {
+		departments[i] = *toDepartmentEntity(&doc)
+	}
+
+	return departments, nil
+}
+
+func (r *DepartmentRepository) Update(ctx context.Context, department *entity.Department) error {
+	objectID, err := primitive.ObjectIDFromHex(department.ID)
+	if err != nil {
+		return err
+	}
+
+	doc := toDepartmentDocument(department)
+	doc.ID = objectID
+
+	_, err = r.collection.ReplaceOne(ctx, bson.M{"_id": objectID}, doc)
+	return err
+}
+
+func (r *DepartmentRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	return err
+}
+
+// TeamRepository implements repository.TeamRepository
+type TeamRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTeamRepository creates a new team repository
+func NewTeamRepository(db *mongo.Database) repository.TeamRepository {
+	collection := db.Collection("teams")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "department_id", Value: 1}},
+	})
+
+	return &TeamRepository{
+		collection: collection,
+	}
+}
+
+func (r *TeamRepository) Create(ctx context.Context, team *entity.Team) error {
+	result, err := r.collection.InsertOne(ctx, toTeamDocument(team))
+	if err != nil {
+		return err
+	}
+
+	team.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*entity.Team, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc teamDocument
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return toTeamEntity(&doc), nil
+}
+
+func (r *TeamRepository) GetAll(ctx context.Context) ([]entity.Team, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []teamDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	teams := make([]entity.Team, len(docs))
+	for i, doc := range docs {
+		teams[i] = *toTeamEntity(&doc)
+	}
+
+	return teams, nil
+}
+
+func (r *TeamRepository) Update(ctx context.Context, team *entity.Team) error {
+	objectID, err := primitive.ObjectIDFromHex(team.ID)
+	if err != nil {
+		return err
+	}
+
+	doc := toTeamDocument(team)
+	doc.ID = objectID
+
+	_, err = r.collection.ReplaceOne(ctx, bson.M{"_id": objectID}, doc)
+	return err
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	return err
+}
+
+func toDepartmentDocument(d *entity.Department) *departmentDocument {
+	return &departmentDocument{
+		Name:      d.Name,
+		ParentID:  d.ParentID,
+		ManagerID: d.ManagerID,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+func toDepartmentEntity(doc *departmentDocument) *entity.Department {
+	return &entity.Department{
+		ID:        doc.ID.Hex(),
+		Name:      doc.Name,
+		ParentID:  doc.ParentID,
+		ManagerID: doc.ManagerID,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+	}
+}
+
+func toTeamDocument(t *entity.Team) *teamDocument {
+	return &teamDocument{
+		Name:         t.Name,
+		DepartmentID: t.DepartmentID,
+		ManagerID:    t.ManagerID,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+func toTeamEntity(doc *teamDocument) *entity.Team {
+	return &entity.Team{
+		ID:           doc.ID.Hex(),
+		Name:         doc.Name,
+		DepartmentID: doc.DepartmentID,
+		ManagerID:    doc.ManagerID,
+		CreatedAt:    doc.CreatedAt,
+		UpdatedAt:    doc.UpdatedAt,
+	}
+}
@@ -74,8 +74,8 @@ func (r *PresensiRepository) GetByID(ctx context.Context, id string) (*entity.Pr
 func (r *PresensiRepository) GetAll(ctx context.Context, filter repository.PresensiFilter, page, limit int) ([]entity.Presensi, int64, error) {
 	bsonFilter := bson.M{}
 
-	if filter.UserID != "" {
-		bsonFilter["user_id"] = filter.UserID
+	if match, ok := userIDMatch(filter.UserID, filter.UserIDs); ok {
+		bsonFilter["user_id"] = match
 	}
 	if filter.Status != "" {
 		bsonFilter["status"] = string(filter.Status)
@@ -195,3 +195,25 @@ func toEntity(doc *presensiDocument) *entity.Presensi {
 
 	return p
 }
+
+// userIDMatch menggabungkan filter user_id tunggal dengan daftar user hasil resolve organisasi.
+// ok bernilai false jika tidak ada batasan user.
+func userIDMatch(userID string, userIDs []string) (interface{}, bool) {
+	if userIDs == nil {
+		if userID == "" {
+			return nil, false
+		}
+		return userID, true
+	}
+
+	if userID != "" {
+		for _, id := range userIDs {
+			if id == userID {
+				return userID, true
+			}
+		}
+		return bson.M{"$in": bson.A{}}, true
+	}
+
+	return bson.M{"$in": userIDs}, true
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// revokedRefreshTokenDocument adalah representasi MongoDB document untuk refresh token yang dicabut
+type revokedRefreshTokenDocument struct {
+	TokenID   string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	RevokedAt time.Time `bson:"revoked_at"`
+}
+
+// RefreshTokenRepository implements repository.RefreshTokenRepository.
+// Revoked token IDs are kept until the token expires, a TTL index removes them afterwards.
+type RefreshTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *mongo.Database) repository.RefreshTokenRepository {
+	collection := db.Collection("revoked_refresh_tokens")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return &RefreshTokenRepository{
+		collection: collection,
+	}
+}
+
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, tokenID, userID string, expiresAt time.Time) (bool, error) {
+	// Insert dengan _id = jti bersifat atomik, request kedua dengan token yang sama gagal dengan duplicate key
+	_, err := r.collection.InsertOne(ctx, revokedRefreshTokenDocument{
+		TokenID:   tokenID,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		RevokedAt: time.Now(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// roleDocument adalah representasi MongoDB document untuk custom role
+type roleDocument struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description,omitempty"`
+	Permissions []string           `bson:"permissions"`
+	DataScope   string             `bson:"data_scope"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
+
+// RoleRepository implements repository.RoleRepository
+type RoleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *mongo.Database) repository.RoleRepository {
+	collection := db.Collection("roles")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return &RoleRepository{
+		collection: collection,
+	}
+}
+
+func (r *RoleRepository) Create(ctx context.Context, role *entity.Role) error {
+	doc := toRoleDocument(role)
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return err
+	}
+
+	role.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return nil
+}
+
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	var doc roleDocument
+	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toRoleEntity(&doc), nil
+}
+
+func (r *RoleRepository) GetAll(ctx context.Context) ([]entity.Role, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []roleDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	roles := make([]entity.Role, len(docs))
+	for i, doc := range docs {
+		roles[i] = *toRoleEntity(&doc)
+	}
+
+	return roles, nil
+}
+
+func (r *RoleRepository) Update(ctx context.Context, role *entity.Role) error {
+	objectID, err := primitive.ObjectIDFromHex(role.ID)
+	if err != nil {
+		return err
+	}
+
+	doc := toRoleDocument(role)
+	doc.ID = objectID
+
+	_, err = r.collection.ReplaceOne(ctx, bson.M{"_id": objectID}, doc)
+	return err
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, name string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"name": name})
+	return err
+}
+
+func toRoleDocument(role *entity.Role) *roleDocument {
+	permissions := make([]string, len(role.Permissions))
+	for i, permission := range role.Permissions {
+		permissions[i] = string(permission)
+	}
+
+	return &roleDocument{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		DataScope:   string(role.DataScope),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+func toRoleEntity(doc *roleDocument) *entity.Role {
+	permissions := make([]entity.Permission, len(doc.Permissions))
+	for i, permission := range doc.Permissions {
+		permissions[i] = entity.Permission(permission)
+	}
+
+	return &entity.Role{
+		ID:          doc.ID.Hex(),
+		Name:        doc.Name,
+		Description: doc.Description,
+		Permissions: permissions,
+		DataScope:   entity.DataScope(doc.DataScope),
+		CreatedAt:   doc.CreatedAt,
+		UpdatedAt:   doc.UpdatedAt,
+	}
+}
@@ -7,20 +7,45 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/okinn/service-presensi/internal/domain/entity"
 	"github.com/okinn/service-presensi/internal/domain/repository"
 )
 
 type userDocument struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Email     string             `bson:"email"`
-	Password  string             `bson:"password"`
-	Nama      string             `bson:"nama"`
-	Role      string             `bson:"role"`
-	IsActive  bool               `bson:"is_active"`
-	CreatedAt time.Time          `bson:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at"`
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	Email           string             `bson:"email"`
+	Password        string             `bson:"password"`
+	PasswordHistory []string           `bson:"password_history,omitempty"`
+	Nama            string             `bson:"nama"`
+	Role            string             `bson:"role"`
+	IsActive        bool               `bson:"is_active"`
+
+	InvitationPending bool `bson:"invitation_pending,omitempty"`
+
+	EmployeeNumber string         `bson:"employee_number,omitempty"`
+	Phone          string         `bson:"phone,omitempty"`
+	JobTitle       string         `bson:"job_title,omitempty"`
+	HireDate       *time.Time     `bson:"hire_date,omitempty"`
+	EmploymentType string         `bson:"employment_type,omitempty"`
+	PhotoRef       string         `bson:"photo_ref,omitempty"`
+	WorkDays       []time.Weekday `bson:"work_days,omitempty"`
+
+	MFAEnabled       bool     `bson:"mfa_enabled"`
+	MFASecret        string   `bson:"mfa_secret,omitempty"`
+	MFARecoveryCodes []string `bson:"mfa_recovery_codes,omitempty"`
+	MFALastUsedStep  int64    `bson:"mfa_last_used_step,omitempty"`
+
+	AuthProvider string `bson:"auth_provider,omitempty"`
+	ExternalID   string `bson:"external_id,omitempty"`
+
+	DepartmentID string `bson:"department_id,omitempty"`
+	TeamID       string `bson:"team_id,omitempty"`
+	ManagerID    string `bson:"manager_id,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
 }
 
 type UserRepository struct {
@@ -28,8 +53,24 @@ type UserRepository struct {
 }
 
 func NewUserRepository(db *mongo.Database) repository.UserRepository {
+	collection := db.Collection("users")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "department_id", Value: 1}}},
+		{Keys: bson.D{{Key: "team_id", Value: 1}}},
+		{Keys: bson.D{{Key: "manager_id", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "employee_number", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"employee_number": bson.M{"$type": "string"}}),
+		},
+	})
+
 	return &UserRepository{
-		collection: db.Collection("users"),
+		collection: collection,
 	}
 }
 
@@ -69,6 +110,26 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*entity.
 	return toUserEntity(&doc), nil
 }
 
+func (r *UserRepository) GetByExternalID(ctx context.Context, provider, externalID string) (*entity.User, error) {
+	var doc userDocument
+	err := r.collection.FindOne(ctx, bson.M{"auth_provider": provider, "external_id": externalID}).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserEntity(&doc), nil
+}
+
+func (r *UserRepository) GetByEmployeeNumber(ctx context.Context, employeeNumber string) (*entity.User, error) {
+	var doc userDocument
+	err := r.collection.FindOne(ctx, bson.M{"employee_number": employeeNumber}).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserEntity(&doc), nil
+}
+
 func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 	objectID, err := primitive.ObjectIDFromHex(user.ID)
 	if err != nil {
@@ -93,13 +154,103 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
+func (r *UserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"role": role})
+}
+
+func (r *UserRepository) Find(ctx context.Context, filter repository.UserFilter) ([]entity.User, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "nama", Value: 1}})
+	cursor, err := r.collection.Find(ctx, toUserFilter(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []userDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	users := make([]entity.User, len(docs))
+	for i, doc := range docs {
+		users[i] = *toUserEntity(&doc)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) FindIDs(ctx context.Context, filter repository.UserFilter) ([]string, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, toUserFilter(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID.Hex()
+	}
+
+	return ids, nil
+}
+
+func toUserFilter(filter repository.UserFilter) bson.M {
+	var or bson.A
+	if len(filter.DepartmentIDs) > 0 {
+		or = append(or, bson.M{"department_id": bson.M{"$in": filter.DepartmentIDs}})
+	}
+	if len(filter.TeamIDs) > 0 {
+		or = append(or, bson.M{"team_id": bson.M{"$in": filter.TeamIDs}})
+	}
+	if len(filter.ManagerIDs) > 0 {
+		or = append(or, bson.M{"manager_id": bson.M{"$in": filter.ManagerIDs}})
+	}
+
+	if len(or) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"$or": or}
+}
+
 func toUserDocument(u *entity.User) *userDocument {
 	return &userDocument{
-		Email:     u.Email,
-		Password:  u.Password,
-		Nama:      u.Nama,
-		Role:      string(u.Role),
-		IsActive:  u.IsActive,
+		Email:           u.Email,
+		Password:        u.Password,
+		PasswordHistory: u.PasswordHistory,
+		Nama:            u.Nama,
+		Role:            string(u.Role),
+		IsActive:        u.IsActive,
+
+		InvitationPending: u.InvitationPending,
+
+		EmployeeNumber: u.EmployeeNumber,
+		Phone:          u.Phone,
+		JobTitle:       u.JobTitle,
+		HireDate:       u.HireDate,
+		EmploymentType: string(u.EmploymentType),
+		PhotoRef:       u.PhotoRef,
+		WorkDays:       u.WorkDays,
+
+		MFAEnabled:       u.MFAEnabled,
+		MFASecret:        u.MFASecret,
+		MFARecoveryCodes: u.MFARecoveryCodes,
+		MFALastUsedStep:  u.MFALastUsedStep,
+
+		AuthProvider: u.AuthProvider,
+		ExternalID:   u.ExternalID,
+
+		DepartmentID: u.DepartmentID,
+		TeamID:       u.TeamID,
+		ManagerID:    u.ManagerID,
+
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
@@ -107,12 +258,36 @@ func toUserDocument(u *entity.User) *userDocument {
 
 func toUserEntity(doc *userDocument) *entity.User {
 	return &entity.User{
-		ID:        doc.ID.Hex(),
-		Email:     doc.Email,
-		Password:  doc.Password,
-		Nama:      doc.Nama,
-		Role:      entity.UserRole(doc.Role),
-		IsActive:  doc.IsActive,
+		ID:              doc.ID.Hex(),
+		Email:           doc.Email,
+		Password:        doc.Password,
+		PasswordHistory: doc.PasswordHistory,
+		Nama:            doc.Nama,
+		Role:            entity.UserRole(doc.Role),
+		IsActive:        doc.IsActive,
+
+		InvitationPending: doc.InvitationPending,
+
+		EmployeeNumber: doc.EmployeeNumber,
+		Phone:          doc.Phone,
+		JobTitle:       doc.JobTitle,
+		HireDate:       doc.HireDate,
+		EmploymentType: entity.EmploymentType(doc.EmploymentType),
+		PhotoRef:       doc.PhotoRef,
+		WorkDays:       doc.WorkDays,
+
+		MFAEnabled:       doc.MFAEnabled,
+		MFASecret:        doc.MFASecret,
+		MFARecoveryCodes: doc.MFARecoveryCodes,
+		MFALastUsedStep:  doc.MFALastUsedStep,
+
+		AuthProvider: doc.AuthProvider,
+		ExternalID:   doc.ExternalID,
+
+		DepartmentID: doc.DepartmentID,
+		TeamID:       doc.TeamID,
+		ManagerID:    doc.ManagerID,
+
 		CreatedAt: doc.CreatedAt,
 		UpdatedAt: doc.UpdatedAt,
 	}
@@ -0,0 +1,322 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+var (
+	ErrInvalidIDToken     = errors.New("ID token tidak valid")
+	ErrTokenExchange      = errors.New("gagal menukar authorization code")
+	ErrDiscoveryMalformed = errors.New("dokumen discovery OpenID Connect tidak lengkap")
+)
+
+// jwksRefreshInterval membatasi pengambilan ulang JWKS saat menemukan kid yang tidak dikenal
+const jwksRefreshInterval = time.Minute
+
+// maxResponseSize membatasi ukuran respons dari identity provider
+const maxResponseSize = 1 << 20
+
+// Config adalah konfigurasi client OpenID Connect
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Provider implements repository.IdentityProvider using discovery,
+// authorization code + PKCE, and ID token verification against the provider's JWKS
+type Provider struct {
+	config     Config
+	discovery  discoveryDocument
+	httpClient *http.Client
+
+	mu            sync.RWMutex
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+// NewProvider fetches the discovery document and signing keys of the issuer
+func NewProvider(ctx context.Context, config Config) (repository.IdentityProvider, error) {
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	p := &Provider{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	discoveryURL := strings.TrimSuffix(config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, &p.discovery); err != nil {
+		return nil, fmt.Errorf("discovery OpenID Connect: %w", err)
+	}
+	if p.discovery.AuthorizationEndpoint == "" || p.discovery.TokenEndpoint == "" || p.discovery.JWKSURI == "" {
+		return nil, ErrDiscoveryMalformed
+	}
+	if strings.TrimSuffix(p.discovery.Issuer, "/") != strings.TrimSuffix(config.IssuerURL, "/") {
+		return nil, fmt.Errorf("issuer discovery %q tidak sama dengan %q", p.discovery.Issuer, config.IssuerURL)
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, fmt.Errorf("JWKS OpenID Connect: %w", err)
+	}
+
+	return p, nil
+}
+
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.config.ClientID)
+	params.Set("redirect_uri", p.config.RedirectURL)
+	params.Set("scope", strings.Join(p.config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.discovery.AuthorizationEndpoint + separator + params.Encode()
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*entity.ExternalIdentity, error) {
+	if code == "" {
+		return nil, ErrTokenExchange
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if p.config.ClientSecret == "" {
+		// Public client, hanya dilindungi PKCE
+		form.Set("client_id", p.config.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&token); err != nil {
+		return nil, ErrTokenExchange
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrTokenExchange, strings.TrimSpace(token.Error+" "+token.ErrorDescription))
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: respons tidak berisi id_token", ErrTokenExchange)
+	}
+
+	return p.verifyIDToken(ctx, token.IDToken)
+}
+
+// verifyIDToken memverifikasi signature, issuer, audience, dan masa berlaku ID token
+func (p *Provider) verifyIDToken(ctx context.Context, rawToken string) (*entity.ExternalIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(p.discovery.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// Jika ada beberapa audience, azp wajib sama dengan client ID
+	if azp, ok := claims["azp"].(string); ok && azp != p.config.ClientID {
+		return nil, ErrInvalidIDToken
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	identity := &entity.ExternalIdentity{
+		Provider:      p.discovery.Issuer,
+		Subject:       subject,
+		Email:         stringClaim(claims, "email"),
+		EmailVerified: boolClaim(claims, "email_verified"),
+		Name:          stringClaim(claims, "name"),
+		Groups:        stringsClaim(claims, p.config.GroupsClaim),
+		Nonce:         stringClaim(claims, "nonce"),
+	}
+
+	return identity, nil
+}
+
+// key mengembalikan public key untuk kid, dan mengambil ulang JWKS jika kid belum dikenal (rotasi key)
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.RLock()
+	key, ok := p.lookupKey(kid)
+	stale := time.Since(p.keysFetchedAt) >= jwksRefreshInterval
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if stale {
+		if err := p.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+		p.mu.RLock()
+		key, ok = p.lookupKey(kid)
+		p.mu.RUnlock()
+		if ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("signing key %q tidak ditemukan", kid)
+}
+
+// lookupKey harus dipanggil dengan lock. Tanpa kid, key hanya dipakai jika JWKS berisi satu key.
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(p.keys) == 1 {
+			for _, key := range p.keys {
+				return key, true
+			}
+		}
+		return nil, false
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	var set jsonWebKeySet
+	if err := p.getJSON(ctx, p.discovery.JWKSURI, &set); err != nil {
+		return err
+	}
+
+	keys, err := set.publicKeys()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", endpoint, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// boolClaim menerima boolean maupun string "true" (beberapa provider mengirim email_verified sebagai string)
+func boolClaim(claims jwt.MapClaims, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return strings.EqualFold(value, "true")
+	}
+	return false
+}
+
+// stringsClaim menerima array string maupun satu string
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
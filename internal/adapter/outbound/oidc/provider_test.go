@@ -0,0 +1,339 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	stubClientID     = "presensi"
+	stubClientSecret = "s3cret"
+	stubRedirectURL  = "https://presensi.example.com/api/auth/oidc/callback"
+	stubCode         = "auth-code"
+	stubVerifier     = "code-verifier"
+)
+
+// stubProvider is a local OpenID Connect provider serving discovery, JWKS and the token endpoint
+type stubProvider struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu      sync.Mutex
+	kid     string
+	key     *rsa.PrivateKey
+	idToken string
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+
+	s := &stubProvider{t: t, kid: "key-1", key: newRSAKey(t)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, discoveryDocument{
+			Issuer:                s.server.URL,
+			AuthorizationEndpoint: s.server.URL + "/authorize",
+			TokenEndpoint:         s.server.URL + "/token",
+			JWKSURI:               s.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		writeJSON(w, http.StatusOK, jsonWebKeySet{Keys: []jsonWebKey{{
+			Kty: "RSA",
+			Kid: s.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", s.token)
+
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// token only hands out the ID token for the expected code, client and PKCE verifier
+func (s *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, tokenResponse{Error: "invalid_request"})
+		return
+	}
+	clientID, secret, _ := r.BasicAuth()
+	if clientID != stubClientID || secret != stubClientSecret {
+		writeJSON(w, http.StatusUnauthorized, tokenResponse{Error: "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != stubCode ||
+		r.PostForm.Get("code_verifier") != stubVerifier || r.PostForm.Get("redirect_uri") != stubRedirectURL {
+		writeJSON(w, http.StatusBadRequest, tokenResponse{Error: "invalid_grant", ErrorDescription: "code atau verifier salah"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, tokenResponse{IDToken: s.idToken})
+}
+
+// issue sets the ID token returned by the token endpoint, signed with the current key
+func (s *stubProvider) issue(claims jwt.MapClaims) {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idToken = signRS256(s.t, s.key, s.kid, claims)
+}
+
+// rotate replaces the signing key published in the JWKS
+func (s *stubProvider) rotate(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kid = kid
+	s.key = newRSAKey(s.t)
+}
+
+func (s *stubProvider) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            s.server.URL,
+		"aud":            stubClientID,
+		"sub":            "subject-1",
+		"email":          "budi@example.com",
+		"email_verified": true,
+		"name":           "Budi",
+		"groups":         []string{"staff", "hr"},
+		"nonce":          "nonce-1",
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+func (s *stubProvider) newProvider(t *testing.T) *Provider {
+	t.Helper()
+	provider, err := NewProvider(context.Background(), Config{
+		IssuerURL:    s.server.URL,
+		ClientID:     stubClientID,
+		ClientSecret: stubClientSecret,
+		RedirectURL:  stubRedirectURL,
+	})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	return provider.(*Provider)
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	return key
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign ID token: %v", err)
+	}
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestProviderAuthCodeURL(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.newProvider(t)
+
+	authURL, err := url.Parse(provider.AuthCodeURL("state-1", "nonce-1", "challenge-1"))
+	if err != nil {
+		t.Fatalf("parse auth URL: %v", err)
+	}
+	if got := authURL.Scheme + "://" + authURL.Host + authURL.Path; got != stub.server.URL+"/authorize" {
+		t.Errorf("endpoint = %q, want %q", got, stub.server.URL+"/authorize")
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             stubClientID,
+		"redirect_uri":          stubRedirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	query := authURL.Query()
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestProviderExchange(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.newProvider(t)
+	claims := stub.claims()
+	claims["email_verified"] = "true" // beberapa provider mengirim string
+	stub.issue(claims)
+
+	identity, err := provider.Exchange(context.Background(), stubCode, stubVerifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if identity.Provider != stub.server.URL || identity.Subject != "subject-1" {
+		t.Errorf("identity = %s/%s, want %s/subject-1", identity.Provider, identity.Subject, stub.server.URL)
+	}
+	if identity.Email != "budi@example.com" || !identity.EmailVerified || identity.Name != "Budi" {
+		t.Errorf("identity email/name = %q %v %q", identity.Email, identity.EmailVerified, identity.Name)
+	}
+	if len(identity.Groups) != 2 || identity.Groups[0] != "staff" || identity.Groups[1] != "hr" {
+		t.Errorf("groups = %v, want [staff hr]", identity.Groups)
+	}
+	if identity.Nonce != "nonce-1" {
+		t.Errorf("nonce = %q, want nonce-1", identity.Nonce)
+	}
+}
+
+func TestProviderExchangeRejectsWrongVerifier(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.newProvider(t)
+	stub.issue(stub.claims())
+
+	_, err := provider.Exchange(context.Background(), stubCode, "other-verifier")
+	if !errors.Is(err, ErrTokenExchange) {
+		t.Fatalf("err = %v, want ErrTokenExchange", err)
+	}
+}
+
+func TestProviderExchangeRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(stub *stubProvider, claims jwt.MapClaims) string
+	}{
+		{"wrong audience", func(stub *stubProvider, claims jwt.MapClaims) string {
+			claims["aud"] = "other-client"
+			return signRS256(t, stub.key, stub.kid, claims)
+		}},
+		{"wrong authorized party", func(stub *stubProvider, claims jwt.MapClaims) string {
+			claims["aud"] = []string{stubClientID, "other-client"}
+			claims["azp"] = "other-client"
+			return signRS256(t, stub.key, stub.kid, claims)
+		}},
+		{"wrong issuer", func(stub *stubProvider, claims jwt.MapClaims) string {
+			claims["iss"] = "https://attacker.example.com"
+			return signRS256(t, stub.key, stub.kid, claims)
+		}},
+		{"expired", func(stub *stubProvider, claims jwt.MapClaims) string {
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return signRS256(t, stub.key, stub.kid, claims)
+		}},
+		{"missing expiry", func(stub *stubProvider, claims jwt.MapClaims) string {
+			delete(claims, "exp")
+			return signRS256(t, stub.key, stub.kid, claims)
+		}},
+		{"missing subject", func(stub *stubProvider, claims jwt.MapClaims) string {
+			delete(claims, "sub")
+			return signRS256(t, stub.key, stub.kid, claims)
+		}},
+		{"signed by unknown key", func(stub *stubProvider, claims jwt.MapClaims) string {
+			return signRS256(t, newRSAKey(t), stub.kid, claims)
+		}},
+		{"symmetric algorithm", func(stub *stubProvider, claims jwt.MapClaims) string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			token.Header["kid"] = stub.kid
+			signed, err := token.SignedString([]byte(stubClientSecret))
+			if err != nil {
+				t.Fatalf("sign ID token: %v", err)
+			}
+			return signed
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStubProvider(t)
+			provider := stub.newProvider(t)
+			stub.idToken = tt.modify(stub, stub.claims())
+
+			_, err := provider.Exchange(context.Background(), stubCode, stubVerifier)
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("err = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestProviderExchangeRefreshesRotatedKeys(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.newProvider(t)
+	stub.rotate("key-2")
+	stub.issue(stub.claims())
+
+	// JWKS baru saja diambil, kid yang tidak dikenal belum memicu pengambilan ulang
+	if _, err := provider.Exchange(context.Background(), stubCode, stubVerifier); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("err = %v, want ErrInvalidIDToken before the refresh interval", err)
+	}
+
+	provider.mu.Lock()
+	provider.keysFetchedAt = time.Now().Add(-jwksRefreshInterval)
+	provider.mu.Unlock()
+
+	if _, err := provider.Exchange(context.Background(), stubCode, stubVerifier); err != nil {
+		t.Fatalf("Exchange after rotation: %v", err)
+	}
+}
+
+func TestNewProviderRejectsIssuerMismatch(t *testing.T) {
+	stub := newStubProvider(t)
+
+	// Discovery di URL lain yang mengaku sebagai issuer stub
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := http.Get(stub.server.URL + r.URL.Path)
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, nil)
+			return
+		}
+		defer resp.Body.Close()
+		var document discoveryDocument
+		json.NewDecoder(resp.Body).Decode(&document)
+		writeJSON(w, resp.StatusCode, document)
+	}))
+	defer other.Close()
+
+	_, err := NewProvider(context.Background(), Config{IssuerURL: other.URL, ClientID: stubClientID})
+	if err == nil {
+		t.Fatal("NewProvider accepted a discovery document of another issuer")
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package rollup
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// PresensiRepository keeps the daily attendance rollups in step with every change to presensi
+type PresensiRepository struct {
+	repository.PresensiRepository
+	rollups repository.AttendanceRollupRepository
+	logger  *slog.Logger
+}
+
+// NewPresensiRepository wraps repo with rollup maintenance
+func NewPresensiRepository(repo repository.PresensiRepository, rollups repository.AttendanceRollupRepository, logger *slog.Logger) repository.PresensiRepository {
+	return &PresensiRepository{
+		PresensiRepository: repo,
+		rollups:            rollups,
+		logger:             logger,
+	}
+}
+
+func (r *PresensiRepository) Create(ctx context.Context, presensi *entity.Presensi) error {
+	if err := r.PresensiRepository.Create(ctx, presensi); err != nil {
+		return err
+	}
+	r.refresh(ctx, presensi.UserID, presensi.Tanggal)
+	return nil
+}
+
+func (r *PresensiRepository) Update(ctx context.Context, presensi *entity.Presensi) error {
+	before, _ := r.PresensiRepository.GetByID(ctx, presensi.ID)
+	if err := r.PresensiRepository.Update(ctx, presensi); err != nil {
+		return err
+	}
+
+	// Perubahan user atau tanggal memindahkan presensi ke rekap lain
+	r.refresh(ctx, presensi.UserID, presensi.Tanggal)
+	if before != nil && (before.UserID != presensi.UserID || !before.Tanggal.Equal(presensi.Tanggal)) {
+		r.refresh(ctx, before.UserID, before.Tanggal)
+	}
+	return nil
+}
+
+func (r *PresensiRepository) Delete(ctx context.Context, id string) error {
+	before, _ := r.PresensiRepository.GetByID(ctx, id)
+	if err := r.PresensiRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if before != nil {
+		r.refresh(ctx, before.UserID, before.Tanggal)
+	}
+	return nil
+}
+
+// refresh never fails the presensi change, a stale rollup is fixed by the rebuild command
+func (r *PresensiRepository) refresh(ctx context.Context, userID string, day time.Time) {
+	if err := r.rollups.Refresh(ctx, userID, day); err != nil {
+		r.logger.Error("Failed to refresh attendance rollup",
+			slog.String("user_id", userID),
+			slog.String("date", day.Format("2006-01-02")),
+			slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package smtp
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// Config adalah konfigurasi server SMTP
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	AppName  string
+}
+
+// Mailer mengirim email undangan melalui SMTP
+type Mailer struct {
+	config Config
+}
+
+// NewMailer membuat adapter SMTP, autentikasi PLAIN dipakai jika username diisi
+func NewMailer(config Config) repository.InvitationSender {
+	return &Mailer{config: config}
+}
+
+func (m *Mailer) SendInvitation(ctx context.Context, email, nama, acceptURL string) error {
+	if strings.ContainsAny(email, "\r\n") {
+		return fmt.Errorf("alamat email tidak valid: %q", email)
+	}
+
+	subject := fmt.Sprintf("Undangan %s", m.config.AppName)
+	body := fmt.Sprintf(
+		"Halo %s,\r\n\r\nAkun %s Anda sudah dibuat. Buka link berikut untuk menentukan password:\r\n\r\n%s\r\n\r\nLink ini hanya berlaku sementara dan hanya bisa dipakai sekali.\r\n",
+		nama, m.config.AppName, acceptURL,
+	)
+
+	var msg strings.Builder
+	msg.WriteString("From: " + m.config.From + "\r\n")
+	msg.WriteString("To: " + email + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := net.JoinHostPort(m.config.Host, fmt.Sprint(m.config.Port))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(addr, auth, m.config.From, []string{email}, []byte(msg.String()))
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+// Package syslog sends audit entries to a syslog server in RFC 5424 format
+package syslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+const (
+	facilityLogAudit = 13 // RFC 5424 facility "log audit"
+	severityWarning  = 4
+	severityNotice   = 5
+
+	// sdID is the structured data element ID, using the example private enterprise number from RFC 5424
+	sdID = "audit@32473"
+
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+// Sink writes audit entries to a syslog server. UDP sends one message per datagram,
+// TCP uses octet-counting framing (RFC 6587) and reconnects after a failed write.
+type Sink struct {
+	network  string
+	address  string
+	hostname string
+	appName  string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSink parses an address such as "udp://siem:514" or "tcp://siem:601"
+func NewSink(address, appName string) (*Sink, error) {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" || (u.Scheme != "udp" && u.Scheme != "tcp") {
+		return nil, fmt.Errorf("alamat syslog tidak valid: %q, gunakan udp://host:port atau tcp://host:port", address)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &Sink{
+		network:  u.Scheme,
+		address:  u.Host,
+		hostname: hostname,
+		appName:  appName,
+	}, nil
+}
+
+func (s *Sink) Name() string {
+	return "syslog"
+}
+
+func (s *Sink) Send(ctx context.Context, auditLog *entity.AuditLog) error {
+	message, err := s.format(auditLog)
+	if err != nil {
+		return err
+	}
+	if s.network == "tcp" {
+		message = strconv.Itoa(len(message)) + " " + message
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		dialer := net.Dialer{Timeout: dialTimeout}
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := s.conn.Write([]byte(message)); err != nil {
+		// Koneksi dibuka ulang pada pengiriman berikutnya
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// format builds "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG" with the entry as JSON message
+func (s *Sink) format(auditLog *entity.AuditLog) (string, error) {
+	severity := severityNotice
+	if auditLog.ErrorMsg != "" || auditLog.StatusCode >= 400 || auditLog.Action == entity.AuditActionLockout {
+		severity = severityWarning
+	}
+
+	body, err := json.Marshal(auditLog)
+	if err != nil {
+		return "", err
+	}
+
+	params := []struct{ name, value string }{
+		{"entity_type", auditLog.EntityType},
+		{"entity_id", auditLog.EntityID},
+		{"user_id", auditLog.UserID},
+		{"ip", auditLog.IPAddress},
+		{"request_id", auditLog.RequestID},
+		{"sequence", strconv.FormatInt(auditLog.Sequence, 10)},
+	}
+	var sd strings.Builder
+	sd.WriteString("[" + sdID)
+	for _, p := range params {
+		if p.value != "" {
+			sd.WriteString(" " + p.name + `="` + escapeParam(p.value) + `"`)
+		}
+	}
+	sd.WriteString("]")
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		facilityLogAudit*8+severity,
+		auditLog.CreatedAt.UTC().Format(time.RFC3339Nano),
+		header(s.hostname, 255),
+		header(s.appName, 48),
+		os.Getpid(),
+		header(string(auditLog.Action), 32),
+		sd.String(),
+		body,
+	), nil
+}
+
+// escapeParam escapes the characters RFC 5424 reserves in structured data values
+func escapeParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// header returns a header field limited to printable ASCII without spaces, "-" when empty
+func header(value string, maxLen int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if value == "" {
+		return "-"
+	}
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	return value
+}
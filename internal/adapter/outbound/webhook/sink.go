@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+// Package webhook posts audit entries as JSON to an HTTP endpoint
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// Config configures the webhook sink
+type Config struct {
+	URL         string
+	Secret      string // Signs the body as "X-Audit-Signature: sha256=<hex hmac>", empty disables signing
+	MaxAttempts int
+	BaseBackoff time.Duration // Doubled after every failed attempt
+	Timeout     time.Duration // Per attempt
+}
+
+// Sink posts every entry to the configured URL. Network errors, 429 and 5xx responses are
+// retried with exponential backoff; other 4xx responses are not.
+type Sink struct {
+	config Config
+	client *http.Client
+}
+
+// NewSink creates a new webhook sink
+func NewSink(config Config) *Sink {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &Sink{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+func (s *Sink) Name() string {
+	return "webhook"
+}
+
+func (s *Sink) Send(ctx context.Context, auditLog *entity.AuditLog) error {
+	body, err := json.Marshal(auditLog)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.config.BaseBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.config.MaxAttempts {
+			return fmt.Errorf("webhook audit gagal setelah %d percobaan: %w", attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends one attempt and reports whether a failure is worth retrying
+func (s *Sink) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.config.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Audit-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook membalas status %d", resp.StatusCode)
+}
+
+func (s *Sink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
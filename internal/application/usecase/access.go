@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/service"
+)
+
+var (
+	ErrAccessDenied = errors.New("akses ditolak")
+)
+
+// Actor adalah pemanggil use case, diambil dari klaim JWT atau API key
+type Actor struct {
+	UserID   string
+	Role     string
+	APIKeyID string
+}
+
+// RoleResolver me-resolve role (bawaan atau custom) berdasarkan nama
+type RoleResolver interface {
+	Resolve(ctx context.Context, name string) (*entity.Role, error)
+}
+
+// accessScope menentukan data presensi milik user mana saja yang boleh dilihat actor
+type accessScope struct {
+	roles      RoleResolver
+	orgService *service.OrganizationService
+}
+
+// visibleUserIDs returns nil when the actor may see everything.
+// API keys are already limited by their scopes and see all records.
+func (a accessScope) visibleUserIDs(ctx context.Context, actor Actor) ([]string, error) {
+	if actor.UserID == "" {
+		if actor.APIKeyID != "" {
+			return nil, nil
+		}
+		return nil, ErrAccessDenied
+	}
+
+	scope := entity.DataScopeOwn
+	role, err := a.roles.Resolve(ctx, actor.Role)
+	if err != nil && !errors.Is(err, ErrRoleNotFound) {
+		return nil, err
+	}
+	if role != nil {
+		scope = role.DataScope
+	}
+
+	return a.orgService.VisibleUserIDs(ctx, actor.UserID, scope)
+}
+
+// canSee checks whether the actor may see the records of userID
+func (a accessScope) canSee(ctx context.Context, actor Actor, userID string) (bool, error) {
+	visible, err := a.visibleUserIDs(ctx, actor)
+	if err != nil {
+		return false, err
+	}
+	if visible == nil {
+		return true, nil
+	}
+	for _, id := range visible {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// AnalyticsCacheConfig berisi pengaturan cache analytics
+type AnalyticsCacheConfig struct {
+	Location *time.Location // Zona waktu analytics, untuk menentukan rentang tanggal tiap hasil
+	TTL      time.Duration  // Untuk hasil yang rentangnya memuat hari ini atau sesudahnya
+	PastTTL  time.Duration  // Untuk hasil yang seluruh rentangnya sebelum hari ini
+}
+
+// cachedAnalyticsUseCase menyimpan hasil AnalyticsUseCase di cache per actor dan parameter.
+// Error tidak pernah di-cache, dan cache yang gagal diakses dilewati.
+type cachedAnalyticsUseCase struct {
+	next   AnalyticsUseCase
+	cache  repository.AnalyticsCache
+	config AnalyticsCacheConfig
+}
+
+// NewCachedAnalyticsUseCase membungkus next dengan cache. Entry dihapus saat presensi dalam
+// rentangnya berubah (lihat repository.AnalyticsCache), perubahan user dan organisasi
+// baru terlihat setelah TTL.
+func NewCachedAnalyticsUseCase(next AnalyticsUseCase, cache repository.AnalyticsCache, config AnalyticsCacheConfig) AnalyticsUseCase {
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	if config.PastTTL < config.TTL {
+		config.PastTTL = config.TTL
+	}
+
+	return &cachedAnalyticsUseCase{next: next, cache: cache, config: config}
+}
+
+// cacheKey berisi semua yang menentukan hasil. Rentang ikut dimasukkan agar periode berjalan tidak
+// terbawa ke periode berikutnya, dan hari ini karena hari kerja, streak dan check-out yang hilang
+// dihitung sampai hari ini.
+type cacheKey struct {
+	Method string      `json:"method"`
+	Actor  Actor       `json:"actor"`
+	Args   interface{} `json:"args"`
+	Start  time.Time   `json:"start"`
+	End    time.Time   `json:"end"`
+	Today  string      `json:"today"`
+}
+
+// cached mengembalikan hasil dari cache, atau memanggil load dan menyimpan hasilnya
+// untuk rentang [start, end)
+func cached[T any](ctx context.Context, uc *cachedAnalyticsUseCase, key cacheKey, load func() (T, error)) (T, error) {
+	key.Today = calendarDay(time.Now().In(uc.config.Location), uc.config.Location).Format("2006-01-02")
+	encodedKey, err := json.Marshal(key)
+	if err != nil {
+		return load()
+	}
+	sum := sha256.Sum256(encodedKey)
+	name := "analytics:" + key.Method + ":" + hex.EncodeToString(sum[:])
+
+	var result T
+	if value, ok, err := uc.cache.Get(ctx, name); err == nil && ok {
+		if err := json.Unmarshal(value, &result); err == nil {
+			return result, nil
+		}
+	}
+
+	result, err = load()
+	if err != nil {
+		return result, err
+	}
+
+	if value, err := json.Marshal(result); err == nil {
+		uc.cache.Set(ctx, name, value, key.Start, key.End, uc.ttl(key.End))
+	}
+	return result, nil
+}
+
+// ttl memakai PastTTL hanya bila rentang berakhir paling lambat awal hari ini
+func (uc *cachedAnalyticsUseCase) ttl(end time.Time) time.Duration {
+	if end.IsZero() {
+		return uc.config.TTL
+	}
+	today := calendarDay(time.Now().In(uc.config.Location), uc.config.Location)
+	if end.After(today) {
+		return uc.config.TTL
+	}
+	return uc.config.PastTTL
+}
+
+func (uc *cachedAnalyticsUseCase) GetSummary(ctx context.Context, actor Actor, startDate, endDate string, unit OrgUnit) (*entity.AttendanceSummary, error) {
+	start, end, err := parseDateRange(startDate, endDate, uc.config.Location)
+	if err != nil {
+		return uc.next.GetSummary(ctx, actor, startDate, endDate, unit)
+	}
+
+	key := cacheKey{Method: "summary", Actor: actor, Args: unit, Start: start, End: end}
+	return cached(ctx, uc, key, func() (*entity.AttendanceSummary, error) {
+		return uc.next.GetSummary(ctx, actor, startDate, endDate, unit)
+	})
+}
+
+func (uc *cachedAnalyticsUseCase) GetDailySummary(ctx context.Context, actor Actor, date string, unit OrgUnit) (*entity.DailySummary, error) {
+	start, err := time.ParseInLocation("2006-01-02", date, uc.config.Location)
+	if err != nil {
+		return uc.next.GetDailySummary(ctx, actor, date, unit)
+	}
+
+	key := cacheKey{Method: "daily", Actor: actor, Args: unit, Start: start, End: start.AddDate(0, 0, 1)}
+	return cached(ctx, uc, key, func() (*entity.DailySummary, error) {
+		return uc.next.GetDailySummary(ctx, actor, date, unit)
+	})
+}
+
+func (uc *cachedAnalyticsUseCase) GetMonthlySummary(ctx context.Context, actor Actor, month string, unit OrgUnit) (*entity.MonthlySummary, error) {
+	start, err := time.ParseInLocation("2006-01", month, uc.config.Location)
+	if err != nil {
+		return uc.next.GetMonthlySummary(ctx, actor, month, unit)
+	}
+
+	key := cacheKey{Method: "monthly", Actor: actor, Args: unit, Start: start, End: start.AddDate(0, 1, 0)}
+	return cached(ctx, uc, key, func() (*entity.MonthlySummary, error) {
+		return uc.next.GetMonthlySummary(ctx, actor, month, unit)
+	})
+}
+
+func (uc *cachedAnalyticsUseCase) GetUserSummary(ctx context.Context, actor Actor, userID, startDate, endDate string) (*entity.UserSummary, error) {
+	start, end, err := parseDateRange(startDate, endDate, uc.config.Location)
+	if err != nil {
+		return uc.next.GetUserSummary(ctx, actor, userID, startDate, endDate)
+	}
+
+	key := cacheKey{Method: "user", Actor: actor, Args: userID, Start: start, End: end}
+	return cached(ctx, uc, key, func() (*entity.UserSummary, error) {
+		return uc.next.GetUserSummary(ctx, actor, userID, startDate, endDate)
+	})
+}
+
+func (uc *cachedAnalyticsUseCase) GetStatusBreakdown(ctx context.Context, actor Actor, startDate, endDate string, unit OrgUnit) ([]entity.StatusBreakdown, error) {
+	start, end, err := parseDateRange(startDate, endDate, uc.config.Location)
+	if err != nil {
+		return uc.next.GetStatusBreakdown(ctx, actor, startDate, endDate, unit)
+	}
+
+	key := cacheKey{Method: "status-breakdown", Actor: actor, Args: unit, Start: start, End: end}
+	return cached(ctx, uc, key, func() ([]entity.StatusBreakdown, error) {
+		return uc.next.GetStatusBreakdown(ctx, actor, startDate, endDate, unit)
+	})
+}
+
+func (uc *cachedAnalyticsUseCase) GetPeriodSummary(ctx context.Context, actor Actor, input PeriodInput, unit OrgUnit) (*entity.PeriodSummary, error) {
+	period, err := parsePeriodSelection(input.PeriodSelection, uc.config.Location)
+	if err != nil {
+		return uc.next.GetPeriodSummary(ctx, actor, input, unit)
+	}
+
+	start := period.Start
+	if input.Compare {
+		start = period.Previous().Start
+	}
+	key := cacheKey{Method: "period", Actor: actor, Args: []interface{}{input, unit}, Start: start, End: period.End}
+	return cached(ctx, uc, key, func() (*entity.PeriodSummary, error) {
+		return uc.next.GetPeriodSummary(ctx, actor, input, unit)
+	})
+}
+
+func (uc *cachedAnalyticsUseCase) GetPunctuality(ctx context.Context, actor Actor, input PunctualityInput, unit OrgUnit) (*entity.PunctualityReport, error) {
+	period, err := parsePeriodSelection(input.PeriodSelection, uc.config.Location)
+	if err != nil {
+		return uc.next.GetPunctuality(ctx, actor, input, unit)
+	}
+
+	key := cacheKey{Method: "punctuality", Actor: actor, Args: []interface{}{input, unit}, Start: period.Start, End: period.End}
+	return cached(ctx, uc, key, func() (*entity.PunctualityReport, error) {
+		return uc.next.GetPunctuality(ctx, actor, input, unit)
+	})
+}
+
+func (uc *cachedAnalyticsUseCase) GetWorkingHours(ctx context.Context, actor Actor, input WorkingHoursInput, unit OrgUnit) (*entity.WorkingHoursReport, error) {
+	period, err := parsePeriodSelection(input.PeriodSelection, uc.config.Location)
+	if err != nil {
+		return uc.next.GetWorkingHours(ctx, actor, input, unit)
+	}
+
+	key := cacheKey{Method: "hours", Actor: actor, Args: []interface{}{input, unit}, Start: period.Start, End: period.End}
+	return cached(ctx, uc, key, func() (*entity.WorkingHoursReport, error) {
+		return uc.next.GetWorkingHours(ctx, actor, input, unit)
+	})
+}
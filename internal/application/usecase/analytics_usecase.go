@@ -6,98 +6,387 @@ import (
 
 	"github.com/okinn/service-presensi/internal/domain/entity"
 	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/internal/domain/service"
 )
 
+// OrgUnit membatasi query ke department, team, atau orang-orang di bawah manager tertentu
+type OrgUnit struct {
+	DepartmentID string
+	TeamID       string
+	ManagerID    string
+}
+
 // AnalyticsUseCase adalah interface untuk analytics use case
 type AnalyticsUseCase interface {
-	GetSummary(ctx context.Context, startDate, endDate string) (*entity.AttendanceSummary, error)
-	GetDailySummary(ctx context.Context, date string) (*entity.DailySummary, error)
-	GetMonthlySummary(ctx context.Context, month string) (*entity.MonthlySummary, error)
-	GetUserSummary(ctx context.Context, userID, startDate, endDate string) (*entity.UserSummary, error)
-	GetStatusBreakdown(ctx context.Context, startDate, endDate string) ([]entity.StatusBreakdown, error)
+	GetSummary(ctx context.Context, actor Actor, startDate, endDate string, unit OrgUnit) (*entity.AttendanceSummary, error)
+	GetDailySummary(ctx context.Context, actor Actor, date string, unit OrgUnit) (*entity.DailySummary, error)
+	GetMonthlySummary(ctx context.Context, actor Actor, month string, unit OrgUnit) (*entity.MonthlySummary, error)
+	GetUserSummary(ctx context.Context, actor Actor, userID, startDate, endDate string) (*entity.UserSummary, error)
+	GetStatusBreakdown(ctx context.Context, actor Actor, startDate, endDate string, unit OrgUnit) ([]entity.StatusBreakdown, error)
+	GetPeriodSummary(ctx context.Context, actor Actor, input PeriodInput, unit OrgUnit) (*entity.PeriodSummary, error)
+	GetPunctuality(ctx context.Context, actor Actor, input PunctualityInput, unit OrgUnit) (*entity.PunctualityReport, error)
+	GetWorkingHours(ctx context.Context, actor Actor, input WorkingHoursInput, unit OrgUnit) (*entity.WorkingHoursReport, error)
+}
+
+// Batas jumlah user pada daftar paling terlambat dan paling tepat waktu
+const (
+	DefaultPunctualityTop = 5
+	MaxPunctualityTop     = 50
+)
+
+// AnalyticsConfig berisi pengaturan analytics
+type AnalyticsConfig struct {
+	Location *time.Location // Zona waktu untuk batas hari, minggu, bulan dan tahun
+	Schedule entity.WorkSchedule
+}
+
+// PeriodSelection memilih periode analytics
+type PeriodSelection struct {
+	Period    string // week, month, quarter, year atau range. Kosong berarti month
+	Value     string // Label periode, misalnya "2024-W05", "2024-01", "2024-Q1" atau "2024". Kosong berarti periode berjalan
+	StartDate string // Untuk period range, YYYY-MM-DD
+	EndDate   string // Untuk period range, YYYY-MM-DD, inklusif
+}
+
+// PeriodInput adalah input untuk GetPeriodSummary
+type PeriodInput struct {
+	PeriodSelection
+	Interval string // day, week atau month. Kosong memakai interval default periode
+	Compare  bool   // Bandingkan dengan periode sebelumnya yang setara
+}
+
+// PunctualityInput adalah input untuk GetPunctuality
+type PunctualityInput struct {
+	PeriodSelection
+	Top int // Jumlah user pada daftar paling terlambat dan paling tepat waktu
+}
+
+// WorkingHoursInput adalah input untuk GetWorkingHours
+type WorkingHoursInput struct {
+	PeriodSelection
+	Interval string // day, week atau month. Kosong memakai interval default periode
 }
 
 type analyticsUseCase struct {
-	repo repository.AnalyticsRepository
+	repo       repository.AnalyticsRepository
+	userRepo   repository.UserRepository
+	orgService *service.OrganizationService
+	access     accessScope
+	location   *time.Location
+	schedule   entity.WorkSchedule
 }
 
-func NewAnalyticsUseCase(repo repository.AnalyticsRepository) AnalyticsUseCase {
+func NewAnalyticsUseCase(repo repository.AnalyticsRepository, userRepo repository.UserRepository, orgService *service.OrganizationService, roles RoleResolver, config AnalyticsConfig) AnalyticsUseCase {
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+
 	return &analyticsUseCase{
-		repo: repo,
+		repo:       repo,
+		userRepo:   userRepo,
+		orgService: orgService,
+		access:     accessScope{roles: roles, orgService: orgService},
+		location:   config.Location,
+		schedule:   config.Schedule,
 	}
 }
 
-func (uc *analyticsUseCase) GetSummary(ctx context.Context, startDate, endDate string) (*entity.AttendanceSummary, error) {
-	filter := entity.AnalyticsFilter{}
-
-	if startDate != "" {
-		parsed, err := time.Parse("2006-01-02", startDate)
-		if err != nil {
-			return nil, err
-		}
-		filter.StartDate = parsed
+func (uc *analyticsUseCase) GetSummary(ctx context.Context, actor Actor, startDate, endDate string, unit OrgUnit) (*entity.AttendanceSummary, error) {
+	filter, err := uc.orgFilter(ctx, actor, unit)
+	if err != nil {
+		return nil, err
 	}
 
-	if endDate != "" {
-		parsed, err := time.Parse("2006-01-02", endDate)
-		if err != nil {
-			return nil, err
-		}
-		// Include the entire end date
-		filter.EndDate = parsed.Add(24 * time.Hour)
+	filter.StartDate, filter.EndDate, err = parseDateRange(startDate, endDate, uc.location)
+	if err != nil {
+		return nil, err
 	}
 
 	return uc.repo.GetSummary(ctx, filter)
 }
 
-func (uc *analyticsUseCase) GetDailySummary(ctx context.Context, date string) (*entity.DailySummary, error) {
-	return uc.repo.GetDailySummary(ctx, date)
+func (uc *analyticsUseCase) GetDailySummary(ctx context.Context, actor Actor, date string, unit OrgUnit) (*entity.DailySummary, error) {
+	filter, err := uc.orgFilter(ctx, actor, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetDailySummary(ctx, date, filter)
 }
 
-func (uc *analyticsUseCase) GetMonthlySummary(ctx context.Context, month string) (*entity.MonthlySummary, error) {
-	return uc.repo.GetMonthlySummary(ctx, month)
+func (uc *analyticsUseCase) GetMonthlySummary(ctx context.Context, actor Actor, month string, unit OrgUnit) (*entity.MonthlySummary, error) {
+	filter, err := uc.orgFilter(ctx, actor, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetMonthlySummary(ctx, month, filter)
 }
 
-func (uc *analyticsUseCase) GetUserSummary(ctx context.Context, userID, startDate, endDate string) (*entity.UserSummary, error) {
+func (uc *analyticsUseCase) GetUserSummary(ctx context.Context, actor Actor, userID, startDate, endDate string) (*entity.UserSummary, error) {
+	allowed, err := uc.access.canSee(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrAccessDenied
+	}
+
 	filter := entity.AnalyticsFilter{}
 
-	if startDate != "" {
-		parsed, err := time.Parse("2006-01-02", startDate)
-		if err != nil {
-			return nil, err
-		}
-		filter.StartDate = parsed
+	filter.StartDate, filter.EndDate, err = parseDateRange(startDate, endDate, uc.location)
+	if err != nil {
+		return nil, err
 	}
 
-	if endDate != "" {
-		parsed, err := time.Parse("2006-01-02", endDate)
+	summary, err := uc.repo.GetUserSummary(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// User yang sudah dihapus tidak punya jadwal, hanya persentase dari record yang diisi
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		summary.PercentageHadirRecords = summary.Summary.PercentageHadir
+		return summary, nil
+	}
+	summary.CalculateScheduleRate(uc.expectedWorkDays(user, filter.StartDate, filter.EndDate))
+
+	return summary, nil
+}
+
+func (uc *analyticsUseCase) GetStatusBreakdown(ctx context.Context, actor Actor, startDate, endDate string, unit OrgUnit) ([]entity.StatusBreakdown, error) {
+	filter, err := uc.orgFilter(ctx, actor, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.StartDate, filter.EndDate, err = parseDateRange(startDate, endDate, uc.location)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetStatusBreakdown(ctx, filter)
+}
+
+func (uc *analyticsUseCase) GetPeriodSummary(ctx context.Context, actor Actor, input PeriodInput, unit OrgUnit) (*entity.PeriodSummary, error) {
+	period, interval, err := uc.parsePeriodInterval(input.PeriodSelection, input.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := uc.orgFilter(ctx, actor, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := uc.repo.GetPeriodSummary(ctx, period, interval, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Compare {
+		previous, err := uc.repo.GetPeriodSummary(ctx, period.Previous(), interval, filter)
 		if err != nil {
 			return nil, err
 		}
-		filter.EndDate = parsed.Add(24 * time.Hour)
+		summary.Comparison = &entity.PeriodComparison{
+			Previous: previous.Period,
+			Summary:  previous.Summary,
+			Delta:    entity.NewSummaryDelta(summary.Summary, previous.Summary),
+		}
 	}
 
-	return uc.repo.GetUserSummary(ctx, userID, filter)
+	return summary, nil
 }
 
-func (uc *analyticsUseCase) GetStatusBreakdown(ctx context.Context, startDate, endDate string) ([]entity.StatusBreakdown, error) {
-	filter := entity.AnalyticsFilter{}
+func (uc *analyticsUseCase) GetPunctuality(ctx context.Context, actor Actor, input PunctualityInput, unit OrgUnit) (*entity.PunctualityReport, error) {
+	period, err := uc.parsePeriod(input.PeriodSelection)
+	if err != nil {
+		return nil, err
+	}
+
+	top := input.Top
+	if top <= 0 {
+		top = DefaultPunctualityTop
+	}
+	if top > MaxPunctualityTop {
+		top = MaxPunctualityTop
+	}
 
+	filter, err := uc.orgFilter(ctx, actor, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.PunctualityReport{
+		Period:    period,
+		WorkStart: entity.FormatClock(uc.schedule.StartTime),
+	}
+
+	if report.Users, err = uc.repo.GetPunctuality(ctx, period, uc.schedule, entity.GroupByUser, filter); err != nil {
+		return nil, err
+	}
+	if report.Teams, err = uc.repo.GetPunctuality(ctx, period, uc.schedule, entity.GroupByTeam, filter); err != nil {
+		return nil, err
+	}
+	if report.Histogram, err = uc.repo.GetArrivalHistogram(ctx, period, filter); err != nil {
+		return nil, err
+	}
+	if report.Streaks, err = uc.repo.GetOnTimeStreaks(ctx, period, uc.schedule, filter); err != nil {
+		return nil, err
+	}
+	if report.MostLate, err = uc.repo.GetPunctualityRanking(ctx, period, uc.schedule, entity.RankMostLate, top, filter); err != nil {
+		return nil, err
+	}
+	if report.MostPunctual, err = uc.repo.GetPunctualityRanking(ctx, period, uc.schedule, entity.RankMostPunctual, top, filter); err != nil {
+		return nil, err
+	}
+
+	for _, user := range report.Users {
+		report.Overall.Add(user)
+	}
+	report.Overall.Calculate()
+
+	return report, nil
+}
+
+func (uc *analyticsUseCase) GetWorkingHours(ctx context.Context, actor Actor, input WorkingHoursInput, unit OrgUnit) (*entity.WorkingHoursReport, error) {
+	period, interval, err := uc.parsePeriodInterval(input.PeriodSelection, input.Interval)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := uc.orgFilter(ctx, actor, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.WorkingHoursReport{
+		Period:        period,
+		Interval:      interval,
+		ScheduleHours: uc.schedule.WorkHours.Hours(),
+	}
+
+	if report.Users, err = uc.repo.GetWorkingHours(ctx, period, uc.schedule, entity.GroupByUser, filter); err != nil {
+		return nil, err
+	}
+	if report.Departments, err = uc.repo.GetWorkingHours(ctx, period, uc.schedule, entity.GroupByDepartment, filter); err != nil {
+		return nil, err
+	}
+	if report.Series, err = uc.repo.GetWorkingHoursSeries(ctx, period, interval, uc.schedule, filter); err != nil {
+		return nil, err
+	}
+
+	for _, user := range report.Users {
+		report.Overall.Add(user)
+	}
+	report.Overall.Calculate()
+
+	return report, nil
+}
+
+// parsePeriodInterval memilih periode dan ukuran bucket time series-nya
+func (uc *analyticsUseCase) parsePeriodInterval(input PeriodSelection, value string) (entity.AnalyticsPeriod, entity.AnalyticsInterval, error) {
+	period, err := uc.parsePeriod(input)
+	if err != nil {
+		return period, "", err
+	}
+
+	interval := entity.AnalyticsInterval(value)
+	if interval == "" {
+		interval = period.DefaultInterval()
+	}
+	if !interval.IsValid() {
+		return period, "", entity.ErrInvalidInterval
+	}
+	return period, interval, nil
+}
+
+func (uc *analyticsUseCase) parsePeriod(input PeriodSelection) (entity.AnalyticsPeriod, error) {
+	return parsePeriodSelection(input, uc.location)
+}
+
+func parsePeriodSelection(input PeriodSelection, loc *time.Location) (entity.AnalyticsPeriod, error) {
+	kind := entity.AnalyticsPeriodKind(input.Period)
+	if kind == "" {
+		kind = entity.PeriodMonth
+	}
+
+	switch {
+	case kind == entity.PeriodRange:
+		return entity.NewRangePeriod(input.StartDate, input.EndDate, loc)
+	case input.Value == "":
+		return entity.AnalyticsPeriodAt(kind, time.Now().In(loc))
+	default:
+		return entity.ParseAnalyticsPeriod(kind, input.Value, loc)
+	}
+}
+
+// parseDateRange mengubah start_date dan end_date (YYYY-MM-DD, inklusif) menjadi rentang [start, end)
+// di loc. Tanggal kosong menghasilkan waktu nol, yaitu tanpa batas.
+func parseDateRange(startDate, endDate string, loc *time.Location) (start, end time.Time, err error) {
 	if startDate != "" {
-		parsed, err := time.Parse("2006-01-02", startDate)
+		start, err = time.ParseInLocation("2006-01-02", startDate, loc)
 		if err != nil {
-			return nil, err
+			return time.Time{}, time.Time{}, err
 		}
-		filter.StartDate = parsed
 	}
 
 	if endDate != "" {
-		parsed, err := time.Parse("2006-01-02", endDate)
+		end, err = time.ParseInLocation("2006-01-02", endDate, loc)
 		if err != nil {
-			return nil, err
+			return time.Time{}, time.Time{}, err
 		}
-		filter.EndDate = parsed.Add(24 * time.Hour)
+		// Include the entire end date
+		end = end.AddDate(0, 0, 1)
 	}
 
-	return uc.repo.GetStatusBreakdown(ctx, filter)
+	return start, end, nil
+}
+
+// expectedWorkDays menghitung hari kerja user dalam rentang [start, end), mulai dari tanggal
+// masuk (atau tanggal akun dibuat) dan paling lambat sampai hari ini
+func (uc *analyticsUseCase) expectedWorkDays(user *entity.User, start, end time.Time) int {
+	from := calendarDay(user.CreatedAt.In(uc.location), uc.location)
+	if user.HireDate != nil {
+		from = calendarDay(*user.HireDate, uc.location)
+	}
+	if !start.IsZero() && calendarDay(start, uc.location).After(from) {
+		from = calendarDay(start, uc.location)
+	}
+
+	until := calendarDay(time.Now().In(uc.location), uc.location).AddDate(0, 0, 1)
+	if !end.IsZero() && calendarDay(end, uc.location).Before(until) {
+		until = calendarDay(end, uc.location)
+	}
+
+	return uc.schedule.ForUser(user).ExpectedDays(from, until)
+}
+
+// calendarDay mengembalikan tengah malam pada tanggal kalender t di loc
+func calendarDay(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// orgFilter membuat filter analytics yang dibatasi ke anggota unit organisasi dan scope actor
+func (uc *analyticsUseCase) orgFilter(ctx context.Context, actor Actor, unit OrgUnit) (entity.AnalyticsFilter, error) {
+	filter := entity.AnalyticsFilter{
+		DepartmentID: unit.DepartmentID,
+		TeamID:       unit.TeamID,
+		ManagerID:    unit.ManagerID,
+	}
+
+	userIDs, err := uc.orgService.ResolveMembers(ctx, unit.DepartmentID, unit.TeamID, unit.ManagerID)
+	if err != nil {
+		return filter, err
+	}
+
+	visible, err := uc.access.visibleUserIDs(ctx, actor)
+	if err != nil {
+		return filter, err
+	}
+	filter.UserIDs = service.RestrictUserIDs(userIDs, visible)
+
+	return filter, nil
 }
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key tidak ditemukan")
+)
+
+// lastUsedResolution membatasi penulisan last_used_at agar tidak terjadi write di setiap request
+const lastUsedResolution = time.Minute
+
+// CreateAPIKeyInput adalah input untuk membuat API key
+type CreateAPIKeyInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+	CreatedBy string
+}
+
+// APIKeyOutput adalah output untuk API key, tanpa hash
+type APIKeyOutput struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"created_by"`
+	ExpiresAt  *string  `json:"expires_at,omitempty"`
+	LastUsedAt *string  `json:"last_used_at,omitempty"`
+	RevokedAt  *string  `json:"revoked_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// CreatedAPIKeyOutput berisi key plaintext yang hanya ditampilkan sekali
+type CreatedAPIKeyOutput struct {
+	Key    string        `json:"key"`
+	APIKey *APIKeyOutput `json:"api_key"`
+}
+
+// APIKeyUseCase adalah interface untuk use case API key
+type APIKeyUseCase interface {
+	Create(ctx context.Context, input CreateAPIKeyInput) (*CreatedAPIKeyOutput, error)
+	GetAll(ctx context.Context) ([]APIKeyOutput, error)
+	Revoke(ctx context.Context, id string) error
+
+	// Authenticate memvalidasi key plaintext dari header X-API-Key
+	Authenticate(ctx context.Context, rawKey string) (*entity.APIKey, error)
+}
+
+type apiKeyUseCase struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(repo repository.APIKeyRepository) APIKeyUseCase {
+	return &apiKeyUseCase{
+		repo: repo,
+	}
+}
+
+func (uc *apiKeyUseCase) Create(ctx context.Context, input CreateAPIKeyInput) (*CreatedAPIKeyOutput, error) {
+	scopes := make([]entity.APIKeyScope, len(input.Scopes))
+	for i, scope := range input.Scopes {
+		scopes[i] = entity.APIKeyScope(scope)
+	}
+
+	apiKey, rawKey, err := entity.NewAPIKey(input.Name, scopes, input.CreatedBy, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &CreatedAPIKeyOutput{
+		Key:    rawKey,
+		APIKey: toAPIKeyOutput(apiKey),
+	}, nil
+}
+
+func (uc *apiKeyUseCase) GetAll(ctx context.Context) ([]APIKeyOutput, error) {
+	apiKeys, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]APIKeyOutput, len(apiKeys))
+	for i, k := range apiKeys {
+		outputs[i] = *toAPIKeyOutput(&k)
+	}
+
+	return outputs, nil
+}
+
+func (uc *apiKeyUseCase) Revoke(ctx context.Context, id string) error {
+	apiKey, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	if err := apiKey.Revoke(); err != nil {
+		return err
+	}
+
+	return uc.repo.Update(ctx, apiKey)
+}
+
+func (uc *apiKeyUseCase) Authenticate(ctx context.Context, rawKey string) (*entity.APIKey, error) {
+	prefix, err := entity.ParseAPIKeyPrefix(rawKey)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := uc.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, entity.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if err := apiKey.Verify(rawKey, now); err != nil {
+		return nil, err
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		// Gagal mencatat last used tidak boleh menolak request
+		_ = uc.repo.UpdateLastUsed(ctx, apiKey.ID, now)
+		apiKey.LastUsedAt = &now
+	}
+
+	return apiKey, nil
+}
+
+func toAPIKeyOutput(k *entity.APIKey) *APIKeyOutput {
+	scopes := make([]string, len(k.Scopes))
+	for i, scope := range k.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	output := &APIKeyOutput{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Scopes:    scopes,
+		CreatedBy: k.CreatedBy,
+		CreatedAt: k.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	if k.ExpiresAt != nil {
+		expiresAt := k.ExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+		output.ExpiresAt = &expiresAt
+	}
+	if k.LastUsedAt != nil {
+		lastUsedAt := k.LastUsedAt.Format("2006-01-02T15:04:05Z07:00")
+		output.LastUsedAt = &lastUsedAt
+	}
+	if k.RevokedAt != nil {
+		revokedAt := k.RevokedAt.Format("2006-01-02T15:04:05Z07:00")
+		output.RevokedAt = &revokedAt
+	}
+
+	return output
+}
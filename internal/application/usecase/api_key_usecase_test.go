@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// memoryAPIKeyRepository menyimpan API key di memory dan mencatat panggilan UpdateLastUsed
+type memoryAPIKeyRepository struct {
+	repository.APIKeyRepository
+	keys        []*entity.APIKey
+	lastUsedLog []time.Time
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, apiKey *entity.APIKey) error {
+	apiKey.ID = fmt.Sprintf("key-%d", len(r.keys)+1)
+	stored := *apiKey
+	r.keys = append(r.keys, &stored)
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) GetByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	for _, apiKey := range r.keys {
+		if apiKey.ID == id {
+			copied := *apiKey
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (r *memoryAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	for _, apiKey := range r.keys {
+		if apiKey.Prefix == prefix {
+			copied := *apiKey
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (r *memoryAPIKeyRepository) Update(ctx context.Context, apiKey *entity.APIKey) error {
+	for i, stored := range r.keys {
+		if stored.ID == apiKey.ID {
+			copied := *apiKey
+			r.keys[i] = &copied
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (r *memoryAPIKeyRepository) UpdateLastUsed(ctx context.Context, id string, at time.Time) error {
+	r.lastUsedLog = append(r.lastUsedLog, at)
+	for _, stored := range r.keys {
+		if stored.ID == id {
+			stored.LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyUseCaseCreateStoresHashOnly(t *testing.T) {
+	repo := &memoryAPIKeyRepository{}
+	uc := NewAPIKeyUseCase(repo)
+
+	created, err := uc.Create(context.Background(), CreateAPIKeyInput{Name: "payroll", Scopes: []string{"presensi:read"}, CreatedBy: "admin-1"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(repo.keys) != 1 {
+		t.Fatalf("stored keys = %d, want 1", len(repo.keys))
+	}
+	stored := repo.keys[0]
+	if stored.KeyHash == "" || stored.KeyHash == created.Key {
+		t.Errorf("stored KeyHash = %q, want a hash of the key", stored.KeyHash)
+	}
+	if created.APIKey.Prefix != stored.Prefix || created.APIKey.ID != stored.ID {
+		t.Errorf("output = %+v, want the stored key", created.APIKey)
+	}
+
+	if _, err := uc.Create(context.Background(), CreateAPIKeyInput{Name: "payroll", Scopes: []string{"presensi:write"}}); !errors.Is(err, entity.ErrInvalidAPIKeyScope) {
+		t.Errorf("Create with unknown scope = %v, want %v", err, entity.ErrInvalidAPIKeyScope)
+	}
+	if len(repo.keys) != 1 {
+		t.Errorf("stored keys = %d after an invalid create, want 1", len(repo.keys))
+	}
+}
+
+func TestAPIKeyUseCaseAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryAPIKeyRepository{}
+	uc := NewAPIKeyUseCase(repo)
+
+	created, err := uc.Create(ctx, CreateAPIKeyInput{Name: "payroll", Scopes: []string{"presensi:read"}, CreatedBy: "admin-1"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	other, _, _ := entity.NewAPIKey("other", []entity.APIKeyScope{entity.ScopePresensiRead}, "admin-1", nil)
+
+	tests := []struct {
+		name   string
+		rawKey string
+		want   error
+	}{
+		{"malformed key", "not-a-key", entity.ErrInvalidAPIKey},
+		{"unknown prefix", "psk_" + other.Prefix + "_c2VjcmV0", entity.ErrInvalidAPIKey},
+		{"known prefix with wrong secret", "psk_" + created.APIKey.Prefix + "_c2VjcmV0", entity.ErrInvalidAPIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.Authenticate(ctx, tt.rawKey); !errors.Is(err, tt.want) {
+				t.Errorf("Authenticate = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(repo.lastUsedLog) != 0 {
+		t.Fatalf("rejected keys recorded last used %d times", len(repo.lastUsedLog))
+	}
+
+	apiKey, err := uc.Authenticate(ctx, created.Key)
+	if err != nil || apiKey.ID != created.APIKey.ID || !apiKey.HasScope(entity.ScopePresensiRead) {
+		t.Fatalf("Authenticate = %+v, %v, want the created key", apiKey, err)
+	}
+
+	// last_used_at hanya ditulis sekali per lastUsedResolution
+	if _, err := uc.Authenticate(ctx, created.Key); err != nil {
+		t.Fatalf("second Authenticate: %v", err)
+	}
+	if len(repo.lastUsedLog) != 1 {
+		t.Errorf("last used written %d times, want 1", len(repo.lastUsedLog))
+	}
+
+	if err := uc.Revoke(ctx, created.APIKey.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, created.Key); !errors.Is(err, entity.ErrAPIKeyRevoked) {
+		t.Errorf("Authenticate after Revoke = %v, want %v", err, entity.ErrAPIKeyRevoked)
+	}
+	if err := uc.Revoke(ctx, "missing"); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Revoke(missing) = %v, want %v", err, ErrAPIKeyNotFound)
+	}
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// Metode autentikasi yang dicatat pada event audit
+const (
+	authMethodPassword = "password"
+	authMethodMFA      = "mfa"
+	authMethodOIDC     = "oidc"
+	authMethodRefresh  = "refresh_token"
+)
+
+// Alasan kegagalan login yang dicatat pada event audit. Respons ke client tetap tidak
+// membedakan email tidak terdaftar dan password salah.
+const (
+	authReasonUnknownUser      = "unknown_user"
+	authReasonInvalidPassword  = "invalid_password"
+	authReasonInactive         = "inactive"
+	authReasonLocked           = "locked"
+	authReasonInvalidMFA       = "invalid_mfa_code"
+	authReasonInvalidChallenge = "invalid_mfa_challenge"
+	authReasonInvalidToken     = "invalid_refresh_token"
+	authReasonRevokedToken     = "revoked_refresh_token"
+	authReasonInvalidState     = "invalid_oidc_state"
+	authReasonInvalidNonce     = "invalid_oidc_nonce"
+	authReasonEmailUnverified  = "email_not_verified"
+	authReasonNotAllowed       = "sso_not_allowed"
+	authReasonSSOError         = "sso_error"
+)
+
+const authAuditTimeout = 5 * time.Second
+
+// authEvent adalah satu event autentikasi untuk audit log
+type authEvent struct {
+	Action    entity.AuditAction
+	Method    string // Kosong untuk logout
+	Reason    string // Kosong jika berhasil
+	UserID    string // Kosong jika user tidak dikenal
+	Email     string
+	Role      string
+	IPAddress string
+	UserAgent string
+}
+
+// authAuditor mencatat event autentikasi dengan entity type "auth", auditRepo boleh nil
+type authAuditor struct {
+	auditRepo repository.AuditLogRepository
+}
+
+// userEvent mengisi identitas user pada event
+func userEvent(action entity.AuditAction, method string, user *entity.User) authEvent {
+	return authEvent{
+		Action: action,
+		Method: method,
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   string(user.Role),
+	}
+}
+
+func (a authAuditor) record(ctx context.Context, event authEvent) {
+	if a.auditRepo == nil {
+		return
+	}
+
+	// IP dan user agent diambil dari request jika tidak diberikan oleh pemanggil
+	actor := entity.AuditActorFromContext(ctx)
+	if actor == nil {
+		actor = &entity.AuditActor{}
+	}
+	if event.IPAddress == "" {
+		event.IPAddress = actor.IPAddress
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = actor.UserAgent
+	}
+
+	entityID := event.UserID
+	if entityID == "" {
+		entityID = event.Email
+	}
+
+	auditLog := entity.NewAuditLog("auth", entityID, event.Action, event.UserID, event.Email, event.Role, event.IPAddress)
+	auditLog.RequestID = actor.RequestID
+	auditLog.UserAgent = event.UserAgent
+
+	details := make(map[string]string)
+	if event.Method != "" {
+		details["method"] = event.Method
+	}
+	if event.Reason != "" {
+		details["reason"] = event.Reason
+		auditLog.SetError(event.Reason)
+	}
+	if len(details) > 0 {
+		changes, _ := json.Marshal(details)
+		auditLog.SetChanges("", "", string(changes))
+	}
+
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), authAuditTimeout)
+	defer cancel()
+
+	// Kegagalan audit tidak boleh menggagalkan proses login. Entri request dari audit
+	// middleware dilewati agar login tidak tercatat sebagai "create" pada "auth".
+	if err := a.auditRepo.Create(writeCtx, auditLog); err == nil {
+		actor.MarkRecorded()
+	}
+}
@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/pkg/jwt"
+	"github.com/okinn/service-presensi/pkg/totp"
+)
+
+var (
+	ErrInvalidMFAChallenge = errors.New("token MFA tidak valid atau sudah expired")
+	ErrMFARequired         = errors.New("MFA wajib untuk role ini dan tidak dapat dinonaktifkan")
+)
+
+// totpSkew adalah jumlah time step sebelum/sesudah yang masih diterima (toleransi jam perangkat)
+const totpSkew = 1
+
+type MFAChallengeInput struct {
+	MFAToken  string
+	Code      string
+	IPAddress string
+	UserAgent string
+}
+
+type DisableMFAInput struct {
+	Password string
+	Code     string
+}
+
+type MFAEnrollmentOutput struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type MFARecoveryCodesOutput struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (uc *authUseCase) VerifyMFAChallenge(ctx context.Context, input MFAChallengeInput) (*AuthOutput, error) {
+	user, err := uc.userFromChallenge(ctx, input.MFAToken)
+	if err != nil {
+		reason := authReasonInvalidChallenge
+		if errors.Is(err, ErrUserNotActive) {
+			reason = authReasonInactive
+		}
+		uc.auditor.record(ctx, authEvent{
+			Action:    entity.AuditActionLoginFailed,
+			Method:    authMethodMFA,
+			Reason:    reason,
+			IPAddress: input.IPAddress,
+			UserAgent: input.UserAgent,
+		})
+		return nil, err
+	}
+
+	loginInput := LoginInput{Email: user.Email, IPAddress: input.IPAddress, UserAgent: input.UserAgent}
+	if uc.lockoutService != nil {
+		if err := uc.lockoutService.CheckLocked(ctx, user.Email); err != nil {
+			if errors.Is(err, entity.ErrAccountLocked) {
+				uc.loginFailed(ctx, authMethodMFA, loginInput, user, authReasonLocked)
+			}
+			return nil, err
+		}
+	}
+
+	var recoveryCodes []string
+	switch {
+	case user.MFAEnabled:
+		err = uc.verifyMFACode(user, input.Code)
+	case user.HasPendingMFA():
+		// Enrollment wajib saat login: kode pertama sekaligus mengaktifkan MFA
+		step, ok := totp.Validate(user.MFASecret, input.Code, time.Now(), totpSkew)
+		if !ok {
+			err = entity.ErrInvalidMFACode
+			break
+		}
+		recoveryCodes, err = user.ActivateMFA(step)
+	default:
+		return nil, entity.ErrMFANotEnrolled
+	}
+
+	if err != nil {
+		uc.registerLoginFailure(ctx, loginInput)
+		uc.loginFailed(ctx, authMethodMFA, loginInput, user, authReasonInvalidMFA)
+		return nil, err
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if uc.lockoutService != nil {
+		_ = uc.lockoutService.RegisterSuccess(ctx, user.Email)
+	}
+
+	output, err := uc.issueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+	output.RecoveryCodes = recoveryCodes
+
+	uc.recordLogin(ctx, entity.AuditActionLogin, authMethodMFA, user, input.IPAddress, input.UserAgent)
+	return output, nil
+}
+
+func (uc *authUseCase) EnrollMFAWithChallenge(ctx context.Context, mfaToken string) (*MFAEnrollmentOutput, error) {
+	user, err := uc.userFromChallenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.enroll(ctx, user)
+}
+
+func (uc *authUseCase) EnrollMFA(ctx context.Context, userID string) (*MFAEnrollmentOutput, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	return uc.enroll(ctx, user)
+}
+
+func (uc *authUseCase) ActivateMFA(ctx context.Context, userID, code string) (*MFARecoveryCodesOutput, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.MFAEnabled {
+		return nil, entity.ErrMFAAlreadyEnabled
+	}
+	if !user.HasPendingMFA() {
+		return nil, entity.ErrMFANotEnrolled
+	}
+
+	step, ok := totp.Validate(user.MFASecret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, entity.ErrInvalidMFACode
+	}
+
+	codes, err := user.ActivateMFA(step)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &MFARecoveryCodesOutput{RecoveryCodes: codes}, nil
+}
+
+func (uc *authUseCase) DisableMFA(ctx context.Context, userID string, input DisableMFAInput) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if !user.MFAEnabled {
+		return entity.ErrMFANotEnabled
+	}
+	if uc.mfaConfig.RequiredForAdmin && user.Role == entity.RoleAdmin {
+		return ErrMFARequired
+	}
+
+	if !user.ComparePassword(input.Password) {
+		return ErrInvalidCredentials
+	}
+	if err := uc.verifyMFACode(user, input.Code); err != nil {
+		return err
+	}
+
+	user.DisableMFA()
+	return uc.userRepo.Update(ctx, user)
+}
+
+func (uc *authUseCase) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*MFARecoveryCodesOutput, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if !user.MFAEnabled {
+		return nil, entity.ErrMFANotEnabled
+	}
+
+	step, ok := totp.Validate(user.MFASecret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, entity.ErrInvalidMFACode
+	}
+	if err := user.AcceptTOTPStep(step); err != nil {
+		return nil, err
+	}
+
+	codes, err := user.RegenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &MFARecoveryCodesOutput{RecoveryCodes: codes}, nil
+}
+
+func (uc *authUseCase) requiresMFA(user *entity.User) bool {
+	return uc.mfaConfig.requires(user)
+}
+
+func (uc *authUseCase) issueMFAChallenge(user *entity.User) (*AuthOutput, error) {
+	return newMFAChallenge(uc.jwtManager, uc.mfaConfig, user)
+}
+
+// requires menentukan apakah login user harus diselesaikan dengan kode MFA
+func (c MFAConfig) requires(user *entity.User) bool {
+	return user.MFAEnabled || (c.RequiredForAdmin && user.Role == entity.RoleAdmin)
+}
+
+// newMFAChallenge membuat token challenge pengganti access token sampai kode MFA diverifikasi
+func newMFAChallenge(jwtManager *jwt.JWTManager, config MFAConfig, user *entity.User) (*AuthOutput, error) {
+	token, err := jwtManager.GenerateMFAChallengeToken(user.ID, user.Email, string(user.Role), config.ChallengeDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthOutput{
+		MFARequired:           true,
+		MFAEnrollmentRequired: !user.MFAEnabled,
+		MFAToken:              token,
+	}, nil
+}
+
+func (uc *authUseCase) userFromChallenge(ctx context.Context, mfaToken string) (*entity.User, error) {
+	claims, err := uc.jwtManager.ValidateTokenType(mfaToken, jwt.MFAChallengeToken)
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserNotActive
+	}
+
+	return user, nil
+}
+
+func (uc *authUseCase) enroll(ctx context.Context, user *entity.User) (*MFAEnrollmentOutput, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.SetPendingMFASecret(secret); err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollmentOutput{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(uc.mfaConfig.Issuer, user.Email, secret),
+	}, nil
+}
+
+// verifyMFACode menerima kode TOTP atau recovery code
+func (uc *authUseCase) verifyMFACode(user *entity.User, code string) error {
+	if step, ok := totp.Validate(user.MFASecret, code, time.Now(), totpSkew); ok {
+		return user.AcceptTOTPStep(step)
+	}
+
+	if user.UseRecoveryCode(code) {
+		return nil
+	}
+
+	return entity.ErrInvalidMFACode
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/pkg/jwt"
+	"github.com/okinn/service-presensi/pkg/totp"
+)
+
+const mfaTestSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// newMFATest membuat use case dengan satu user yang sudah mengaktifkan MFA,
+// recovery code yang dikembalikan masih berlaku
+func newMFATest(t *testing.T) (AuthUseCase, *jwt.JWTManager, *entity.User, []string) {
+	t.Helper()
+	user := &entity.User{ID: "user-1", Email: "budi@example.com", Role: entity.RoleEmployee, IsActive: true, MFASecret: mfaTestSecret}
+	codes, err := user.ActivateMFA(0)
+	if err != nil {
+		t.Fatalf("ActivateMFA: %v", err)
+	}
+
+	manager := jwt.NewJWTManager("secret", time.Hour)
+	users := &memoryUserRepository{users: []*entity.User{user}}
+	useCase := NewAuthUseCase(users, manager, nil, entity.PasswordPolicy{}, nil, nil, MFAConfig{ChallengeDuration: 5 * time.Minute})
+	return useCase, manager, user, codes
+}
+
+func currentTOTP(t *testing.T) string {
+	t.Helper()
+	code, err := totp.GenerateCode(mfaTestSecret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("GenerateCode: %v", err)
+	}
+	return code
+}
+
+func TestVerifyMFAChallenge(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// prepare mengembalikan token dan kode yang dikirim
+		prepare func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string)
+		want    error
+	}{
+		{
+			name: "current code",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				return challengeToken(t, manager, user.ID, 5*time.Minute), currentTOTP(t)
+			},
+		},
+		{
+			name: "replayed step",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				// Kode langkah ini sudah dipakai pada login sebelumnya
+				user.MFALastUsedStep = totp.Step(time.Now()) + 1
+				return challengeToken(t, manager, user.ID, 5*time.Minute), currentTOTP(t)
+			},
+			want: entity.ErrInvalidMFACode,
+		},
+		{
+			name: "recovery code",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				return challengeToken(t, manager, user.ID, 5*time.Minute), codes[0]
+			},
+		},
+		{
+			name: "wrong code",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				return challengeToken(t, manager, user.ID, 5*time.Minute), "abcde-fghij"
+			},
+			want: entity.ErrInvalidMFACode,
+		},
+		{
+			name: "expired challenge",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				return challengeToken(t, manager, user.ID, -time.Minute), currentTOTP(t)
+			},
+			want: ErrInvalidMFAChallenge,
+		},
+		{
+			name: "access token as challenge",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				token, err := manager.GenerateToken(user.ID, user.Email, string(user.Role))
+				if err != nil {
+					t.Fatalf("GenerateToken: %v", err)
+				}
+				return token, currentTOTP(t)
+			},
+			want: ErrInvalidMFAChallenge,
+		},
+		{
+			name: "refresh token as challenge",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				token, err := manager.GenerateRefreshToken(user.ID, user.Email, string(user.Role))
+				if err != nil {
+					t.Fatalf("GenerateRefreshToken: %v", err)
+				}
+				return token, currentTOTP(t)
+			},
+			want: ErrInvalidMFAChallenge,
+		},
+		{
+			name: "challenge of an unknown user",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				return challengeToken(t, manager, "user-2", 5*time.Minute), currentTOTP(t)
+			},
+			want: ErrInvalidMFAChallenge,
+		},
+		{
+			name: "inactive user",
+			prepare: func(t *testing.T, manager *jwt.JWTManager, user *entity.User, codes []string) (string, string) {
+				user.IsActive = false
+				return challengeToken(t, manager, user.ID, 5*time.Minute), currentTOTP(t)
+			},
+			want: ErrUserNotActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, manager, user, codes := newMFATest(t)
+			token, code := tt.prepare(t, manager, user, codes)
+
+			output, err := useCase.VerifyMFAChallenge(ctx, MFAChallengeInput{MFAToken: token, Code: code})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if output != nil {
+					t.Errorf("output = %+v, want nil", output)
+				}
+				return
+			}
+			if _, err := manager.ValidateTokenType(output.Token, jwt.AccessToken); err != nil {
+				t.Errorf("access token: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyMFAChallengeRejectsReuse(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("TOTP code", func(t *testing.T) {
+		useCase, manager, user, _ := newMFATest(t)
+		code := currentTOTP(t)
+
+		if _, err := useCase.VerifyMFAChallenge(ctx, MFAChallengeInput{MFAToken: challengeToken(t, manager, user.ID, time.Minute), Code: code}); err != nil {
+			t.Fatalf("first use: %v", err)
+		}
+		_, err := useCase.VerifyMFAChallenge(ctx, MFAChallengeInput{MFAToken: challengeToken(t, manager, user.ID, time.Minute), Code: code})
+		if !errors.Is(err, entity.ErrInvalidMFACode) {
+			t.Errorf("second use err = %v, want %v", err, entity.ErrInvalidMFACode)
+		}
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		useCase, manager, user, codes := newMFATest(t)
+
+		if _, err := useCase.VerifyMFAChallenge(ctx, MFAChallengeInput{MFAToken: challengeToken(t, manager, user.ID, time.Minute), Code: codes[1]}); err != nil {
+			t.Fatalf("first use: %v", err)
+		}
+		if len(user.MFARecoveryCodes) != entity.RecoveryCodeCount-1 {
+			t.Errorf("recovery codes left = %d, want %d", len(user.MFARecoveryCodes), entity.RecoveryCodeCount-1)
+		}
+		_, err := useCase.VerifyMFAChallenge(ctx, MFAChallengeInput{MFAToken: challengeToken(t, manager, user.ID, time.Minute), Code: codes[1]})
+		if !errors.Is(err, entity.ErrInvalidMFACode) {
+			t.Errorf("second use err = %v, want %v", err, entity.ErrInvalidMFACode)
+		}
+	})
+}
+
+func challengeToken(t *testing.T, manager *jwt.JWTManager, userID string, duration time.Duration) string {
+	t.Helper()
+	token, err := manager.GenerateMFAChallengeToken(userID, "budi@example.com", string(entity.RoleEmployee), duration)
+	if err != nil {
+		t.Fatalf("GenerateMFAChallengeToken: %v", err)
+	}
+	return token
+}
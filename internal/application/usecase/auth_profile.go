@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+var (
+	ErrEmployeeNumberExists = errors.New("NIK sudah dipakai user lain")
+	ErrInvalidHireDate      = errors.New("tanggal masuk tidak valid (format: YYYY-MM-DD)")
+)
+
+// UpdateProfileInput berisi field yang boleh diubah sendiri oleh user, nil berarti tidak diubah
+type UpdateProfileInput struct {
+	Nama     *string
+	Phone    *string
+	PhotoRef *string
+}
+
+// UpdateEmployeeInput berisi semua field profil termasuk data kepegawaian yang hanya boleh diubah admin
+type UpdateEmployeeInput struct {
+	UpdateProfileInput
+	EmployeeNumber *string
+	JobTitle       *string
+	HireDate       *string // YYYY-MM-DD, string kosong menghapus tanggal masuk
+	EmploymentType *string
+	WorkDays       *[]string // Nama hari pendek ("mon".."sun"), kosong mengikuti jadwal perusahaan
+}
+
+func (uc *authUseCase) UpdateProfile(ctx context.Context, userID string, input UpdateProfileInput) (*UserOutput, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := applyProfile(user, input); err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return toUserOutput(user), nil
+}
+
+func (uc *authUseCase) UpdateEmployee(ctx context.Context, userID string, input UpdateEmployeeInput) (*UserOutput, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := applyProfile(user, input.UpdateProfileInput); err != nil {
+		return nil, err
+	}
+
+	employeeNumber := valueOr(input.EmployeeNumber, user.EmployeeNumber)
+	if employeeNumber != "" && employeeNumber != user.EmployeeNumber {
+		existing, _ := uc.userRepo.GetByEmployeeNumber(ctx, employeeNumber)
+		if existing != nil && existing.ID != user.ID {
+			return nil, ErrEmployeeNumberExists
+		}
+	}
+
+	hireDate := user.HireDate
+	if input.HireDate != nil {
+		hireDate = nil
+		if *input.HireDate != "" {
+			parsed, err := time.Parse("2006-01-02", *input.HireDate)
+			if err != nil {
+				return nil, ErrInvalidHireDate
+			}
+			hireDate = &parsed
+		}
+	}
+
+	employmentType := entity.EmploymentType(valueOr(input.EmploymentType, string(user.EmploymentType)))
+	if err := user.UpdateEmployment(employeeNumber, valueOr(input.JobTitle, user.JobTitle), hireDate, employmentType); err != nil {
+		return nil, err
+	}
+
+	if input.WorkDays != nil {
+		workDays, err := entity.ParseWeekdays(*input.WorkDays)
+		if err != nil {
+			return nil, err
+		}
+		user.UpdateWorkDays(workDays)
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return toUserOutput(user), nil
+}
+
+func applyProfile(user *entity.User, input UpdateProfileInput) error {
+	return user.UpdateProfile(
+		valueOr(input.Nama, user.Nama),
+		valueOr(input.Phone, user.Phone),
+		valueOr(input.PhotoRef, user.PhotoRef),
+	)
+}
+
+// valueOr returns *v, or fallback when v is nil
+func valueOr(v *string, fallback string) string {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
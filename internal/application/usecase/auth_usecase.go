@@ -3,59 +3,150 @@ package usecase
 import (
 	"context"
 	"errors"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/okinn/service-presensi/internal/domain/entity"
 	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/internal/domain/service"
 	"github.com/okinn/service-presensi/pkg/jwt"
 )
 
 var (
-	ErrInvalidCredentials = errors.New("email atau password salah")
-	ErrEmailAlreadyExists = errors.New("email sudah terdaftar")
-	ErrUserNotActive      = errors.New("user tidak aktif")
-	ErrUserNotFound       = errors.New("user tidak ditemukan")
+	ErrInvalidCredentials  = errors.New("email atau password salah")
+	ErrEmailAlreadyExists  = errors.New("email sudah terdaftar")
+	ErrUserNotActive       = errors.New("user tidak aktif")
+	ErrUserNotFound        = errors.New("user tidak ditemukan")
+	ErrLockoutDisabled     = errors.New("fitur lockout akun tidak aktif")
+	ErrInvalidRefreshToken = errors.New("refresh token tidak valid atau sudah expired")
 )
 
+// dummyPasswordHash dibandingkan saat email tidak terdaftar agar waktu respons
+// login sama dengan password salah
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
 type RegisterInput struct {
 	Email    string
 	Password string
 	Nama     string
-	Role     string
 }
 
 type LoginInput struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	IPAddress string
+	UserAgent string
+}
+
+type RefreshInput struct {
+	RefreshToken string
+	IPAddress    string
+	UserAgent    string
+}
+
+type LogoutInput struct {
+	RefreshToken string // Opsional, dicabut agar tidak bisa ditukar lagi
+	UserID       string
+	Email        string
+	Role         string
+	IPAddress    string
+	UserAgent    string
+}
+
+type ChangePasswordInput struct {
+	OldPassword string
+	NewPassword string
+}
+
+type UnlockAccountInput struct {
+	Email     string
+	ActorID   string
+	ActorRole string
+	IPAddress string
 }
 
 type AuthOutput struct {
-	Token string      `json:"token"`
-	User  *UserOutput `json:"user"`
+	Token        string      `json:"token,omitempty"`
+	RefreshToken string      `json:"refresh_token,omitempty"`
+	User         *UserOutput `json:"user,omitempty"`
+
+	// Diisi jika login memerlukan langkah verifikasi MFA
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string   `json:"mfa_token,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
 }
 
 type UserOutput struct {
-	ID       string `json:"id"`
-	Email    string `json:"email"`
-	Nama     string `json:"nama"`
-	Role     string `json:"role"`
-	IsActive bool   `json:"is_active"`
+	ID           string `json:"id"`
+	Email        string `json:"email"`
+	Nama         string `json:"nama"`
+	Role         string `json:"role"`
+	IsActive     bool   `json:"is_active"`
+	MFAEnabled   bool   `json:"mfa_enabled"`
+	DepartmentID string `json:"department_id,omitempty"`
+	TeamID       string `json:"team_id,omitempty"`
+	ManagerID    string `json:"manager_id,omitempty"`
+
+	EmployeeNumber string   `json:"employee_number,omitempty"`
+	Phone          string   `json:"phone,omitempty"`
+	JobTitle       string   `json:"job_title,omitempty"`
+	HireDate       string   `json:"hire_date,omitempty"`
+	EmploymentType string   `json:"employment_type,omitempty"`
+	PhotoRef       string   `json:"photo_ref,omitempty"`
+	WorkDays       []string `json:"work_days,omitempty"`
+}
+
+// MFAConfig adalah konfigurasi two-factor authentication
+type MFAConfig struct {
+	Issuer            string
+	RequiredForAdmin  bool
+	ChallengeDuration time.Duration
 }
 
 type AuthUseCase interface {
 	Register(ctx context.Context, input RegisterInput) (*AuthOutput, error)
 	Login(ctx context.Context, input LoginInput) (*AuthOutput, error)
+	Refresh(ctx context.Context, input RefreshInput) (*AuthOutput, error)
+	Logout(ctx context.Context, input LogoutInput) error
 	GetProfile(ctx context.Context, userID string) (*UserOutput, error)
+	ChangePassword(ctx context.Context, userID string, input ChangePasswordInput) error
+	UnlockAccount(ctx context.Context, input UnlockAccountInput) error
+
+	// Profil karyawan
+	UpdateProfile(ctx context.Context, userID string, input UpdateProfileInput) (*UserOutput, error)
+	UpdateEmployee(ctx context.Context, userID string, input UpdateEmployeeInput) (*UserOutput, error)
+
+	// Two-factor authentication
+	VerifyMFAChallenge(ctx context.Context, input MFAChallengeInput) (*AuthOutput, error)
+	EnrollMFAWithChallenge(ctx context.Context, mfaToken string) (*MFAEnrollmentOutput, error)
+	EnrollMFA(ctx context.Context, userID string) (*MFAEnrollmentOutput, error)
+	ActivateMFA(ctx context.Context, userID, code string) (*MFARecoveryCodesOutput, error)
+	DisableMFA(ctx context.Context, userID string, input DisableMFAInput) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*MFARecoveryCodesOutput, error)
 }
 
 type authUseCase struct {
-	userRepo   repository.UserRepository
-	jwtManager *jwt.JWTManager
+	userRepo       repository.UserRepository
+	jwtManager     *jwt.JWTManager
+	refreshTokens  repository.RefreshTokenRepository
+	passwordPolicy entity.PasswordPolicy
+	lockoutService *service.LoginLockoutService
+	mfaConfig      MFAConfig
+	auditor        authAuditor
 }
 
-func NewAuthUseCase(userRepo repository.UserRepository, jwtManager *jwt.JWTManager) AuthUseCase {
+// NewAuthUseCase membuat use case autentikasi, lockoutService dan auditRepo boleh nil
+func NewAuthUseCase(userRepo repository.UserRepository, jwtManager *jwt.JWTManager, refreshTokens repository.RefreshTokenRepository, passwordPolicy entity.PasswordPolicy, lockoutService *service.LoginLockoutService, auditRepo repository.AuditLogRepository, mfaConfig MFAConfig) AuthUseCase {
 	return &authUseCase{
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
+		userRepo:       userRepo,
+		jwtManager:     jwtManager,
+		refreshTokens:  refreshTokens,
+		passwordPolicy: passwordPolicy,
+		lockoutService: lockoutService,
+		mfaConfig:      mfaConfig,
+		auditor:        authAuditor{auditRepo: auditRepo},
 	}
 }
 
@@ -65,12 +156,8 @@ func (uc *authUseCase) Register(ctx context.Context, input RegisterInput) (*Auth
 		return nil, ErrEmailAlreadyExists
 	}
 
-	role := entity.UserRole(input.Role)
-	if !role.IsValid() {
-		role = entity.RoleEmployee
-	}
-
-	user, err := entity.NewUser(input.Email, input.Password, input.Nama, role)
+	// Registrasi mandiri selalu menjadi employee, role hanya diubah lewat PUT /api/users/{id}/role
+	user, err := entity.NewUser(input.Email, input.Password, input.Nama, entity.RoleEmployee, uc.passwordPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -79,40 +166,137 @@ func (uc *authUseCase) Register(ctx context.Context, input RegisterInput) (*Auth
 		return nil, err
 	}
 
-	token, err := uc.jwtManager.GenerateToken(user.ID, user.Email, string(user.Role))
-	if err != nil {
-		return nil, err
+	if uc.requiresMFA(user) {
+		return uc.issueMFAChallenge(user)
 	}
 
-	return &AuthOutput{
-		Token: token,
-		User:  toUserOutput(user),
-	}, nil
+	return uc.issueTokens(user)
 }
 
 func (uc *authUseCase) Login(ctx context.Context, input LoginInput) (*AuthOutput, error) {
+	// Lockout dicek per email, termasuk email yang tidak terdaftar,
+	// agar respons tidak membedakan keduanya
+	if uc.lockoutService != nil {
+		if err := uc.lockoutService.CheckLocked(ctx, input.Email); err != nil {
+			if errors.Is(err, entity.ErrAccountLocked) {
+				uc.loginFailed(ctx, authMethodPassword, input, nil, authReasonLocked)
+			}
+			return nil, err
+		}
+	}
+
 	user, err := uc.userRepo.GetByEmail(ctx, input.Email)
 	if err != nil {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(input.Password))
+		uc.registerLoginFailure(ctx, input)
+		uc.loginFailed(ctx, authMethodPassword, input, nil, authReasonUnknownUser)
 		return nil, ErrInvalidCredentials
 	}
 
 	if !user.ComparePassword(input.Password) {
+		uc.registerLoginFailure(ctx, input)
+		uc.loginFailed(ctx, authMethodPassword, input, user, authReasonInvalidPassword)
 		return nil, ErrInvalidCredentials
 	}
 
 	if !user.IsActive {
+		uc.loginFailed(ctx, authMethodPassword, input, user, authReasonInactive)
 		return nil, ErrUserNotActive
 	}
 
-	token, err := uc.jwtManager.GenerateToken(user.ID, user.Email, string(user.Role))
+	// Password benar, tapi access token baru diberikan setelah kode MFA diverifikasi
+	if uc.requiresMFA(user) {
+		uc.recordLogin(ctx, entity.AuditActionMFAChallenge, authMethodPassword, user, input.IPAddress, input.UserAgent)
+		return uc.issueMFAChallenge(user)
+	}
+
+	if uc.lockoutService != nil {
+		_ = uc.lockoutService.RegisterSuccess(ctx, input.Email)
+	}
+
+	output, err := uc.issueTokens(user)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AuthOutput{
-		Token: token,
-		User:  toUserOutput(user),
-	}, nil
+	uc.recordLogin(ctx, entity.AuditActionLogin, authMethodPassword, user, input.IPAddress, input.UserAgent)
+	return output, nil
+}
+
+// Refresh menukar refresh token dengan access token dan refresh token baru. Role diambil
+// ulang dari database sehingga perubahan role langsung berlaku. Refresh token yang ditukar
+// dicabut, token yang sudah dicabut (ditukar sebelumnya atau logout) ditolak.
+func (uc *authUseCase) Refresh(ctx context.Context, input RefreshInput) (*AuthOutput, error) {
+	event := authEvent{
+		Action:    entity.AuditActionTokenRefresh,
+		Method:    authMethodRefresh,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	}
+
+	claims, err := uc.jwtManager.ValidateTokenType(input.RefreshToken, jwt.RefreshToken)
+	if err != nil || claims.ID == "" {
+		event.Reason = authReasonInvalidToken
+		uc.auditor.record(ctx, event)
+		return nil, ErrInvalidRefreshToken
+	}
+	event.UserID, event.Email, event.Role = claims.UserID, claims.Email, claims.Role
+
+	user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		event.Reason = authReasonUnknownUser
+		uc.auditor.record(ctx, event)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if !user.IsActive {
+		event.Reason = authReasonInactive
+		uc.auditor.record(ctx, event)
+		return nil, ErrUserNotActive
+	}
+
+	revoked, err := uc.refreshTokens.Revoke(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		event.Reason = authReasonRevokedToken
+		uc.auditor.record(ctx, event)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	output, err := uc.issueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Role = string(user.Role)
+	uc.auditor.record(ctx, event)
+	return output, nil
+}
+
+// Logout mencabut refresh token milik user dan mencatat event logout. Access token tetap
+// berlaku sampai expired, client menghapusnya.
+func (uc *authUseCase) Logout(ctx context.Context, input LogoutInput) error {
+	// Token yang tidak valid atau expired sudah tidak bisa ditukar, token user lain tidak dicabut
+	if input.RefreshToken != "" {
+		claims, err := uc.jwtManager.ValidateTokenType(input.RefreshToken, jwt.RefreshToken)
+		if err == nil && claims.ID != "" && claims.UserID == input.UserID {
+			if _, err := uc.refreshTokens.Revoke(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+				return err
+			}
+		}
+	}
+
+	uc.auditor.record(ctx, authEvent{
+		Action:    entity.AuditActionLogout,
+		UserID:    input.UserID,
+		Email:     input.Email,
+		Role:      input.Role,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+	return nil
 }
 
 func (uc *authUseCase) GetProfile(ctx context.Context, userID string) (*UserOutput, error) {
@@ -124,12 +308,101 @@ func (uc *authUseCase) GetProfile(ctx context.Context, userID string) (*UserOutp
 	return toUserOutput(user), nil
 }
 
+func (uc *authUseCase) ChangePassword(ctx context.Context, userID string, input ChangePasswordInput) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if !user.ComparePassword(input.OldPassword) {
+		return ErrInvalidCredentials
+	}
+
+	if err := user.UpdatePassword(input.NewPassword, uc.passwordPolicy); err != nil {
+		return err
+	}
+
+	return uc.userRepo.Update(ctx, user)
+}
+
+func (uc *authUseCase) UnlockAccount(ctx context.Context, input UnlockAccountInput) error {
+	if uc.lockoutService == nil {
+		return ErrLockoutDisabled
+	}
+	return uc.lockoutService.Unlock(ctx, input.Email, input.ActorID, input.ActorRole, input.IPAddress)
+}
+
+func (uc *authUseCase) registerLoginFailure(ctx context.Context, input LoginInput) {
+	if uc.lockoutService == nil {
+		return
+	}
+	_ = uc.lockoutService.RegisterFailure(ctx, input.Email, input.IPAddress, input.UserAgent)
+}
+
+// issueTokens membuat access token dan refresh token untuk user yang berhasil login
+func (uc *authUseCase) issueTokens(user *entity.User) (*AuthOutput, error) {
+	token, err := uc.jwtManager.GenerateToken(user.ID, user.Email, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := uc.jwtManager.GenerateRefreshToken(user.ID, user.Email, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthOutput{
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         toUserOutput(user),
+	}, nil
+}
+
+func (uc *authUseCase) recordLogin(ctx context.Context, action entity.AuditAction, method string, user *entity.User, ipAddress, userAgent string) {
+	event := userEvent(action, method, user)
+	event.IPAddress = ipAddress
+	event.UserAgent = userAgent
+	uc.auditor.record(ctx, event)
+}
+
+// loginFailed mencatat login yang gagal, user nil jika email tidak terdaftar
+func (uc *authUseCase) loginFailed(ctx context.Context, method string, input LoginInput, user *entity.User, reason string) {
+	event := authEvent{Action: entity.AuditActionLoginFailed, Method: method, Email: input.Email}
+	if user != nil {
+		event = userEvent(entity.AuditActionLoginFailed, method, user)
+	}
+	event.Reason = reason
+	event.IPAddress = input.IPAddress
+	event.UserAgent = input.UserAgent
+	uc.auditor.record(ctx, event)
+}
+
 func toUserOutput(u *entity.User) *UserOutput {
-	return &UserOutput{
-		ID:       u.ID,
-		Email:    u.Email,
-		Nama:     u.Nama,
-		Role:     string(u.Role),
-		IsActive: u.IsActive,
+	output := &UserOutput{
+		ID:           u.ID,
+		Email:        u.Email,
+		Nama:         u.Nama,
+		Role:         string(u.Role),
+		IsActive:     u.IsActive,
+		MFAEnabled:   u.MFAEnabled,
+		DepartmentID: u.DepartmentID,
+		TeamID:       u.TeamID,
+		ManagerID:    u.ManagerID,
+
+		EmployeeNumber: u.EmployeeNumber,
+		Phone:          u.Phone,
+		JobTitle:       u.JobTitle,
+		EmploymentType: string(u.EmploymentType),
+		PhotoRef:       u.PhotoRef,
+	}
+
+	if len(u.WorkDays) > 0 {
+		output.WorkDays = entity.FormatWeekdays(u.WorkDays)
 	}
+
+	if u.HireDate != nil {
+		output.HireDate = u.HireDate.Format("2006-01-02")
+	}
+
+	return output
 }
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/pkg/jwt"
+)
+
+// memoryRefreshTokens menyimpan ID refresh token yang sudah dicabut
+type memoryRefreshTokens struct {
+	revoked map[string]time.Time
+}
+
+func (r *memoryRefreshTokens) Revoke(ctx context.Context, tokenID, userID string, expiresAt time.Time) (bool, error) {
+	if _, ok := r.revoked[tokenID]; ok {
+		return false, nil
+	}
+	r.revoked[tokenID] = expiresAt
+	return true, nil
+}
+
+// capturedAuthEvents mencatat audit log autentikasi
+type capturedAuthEvents struct {
+	repository.AuditLogRepository
+	logs []*entity.AuditLog
+}
+
+func (r *capturedAuthEvents) Create(ctx context.Context, auditLog *entity.AuditLog) error {
+	r.logs = append(r.logs, auditLog)
+	return nil
+}
+
+func newRefreshTest(t *testing.T) (AuthUseCase, *jwt.JWTManager, *memoryRefreshTokens, *capturedAuthEvents) {
+	t.Helper()
+	user := &entity.User{ID: "user-1", Email: "budi@example.com", Role: entity.RoleEmployee, IsActive: true}
+	users := &memoryUserRepository{users: []*entity.User{user}}
+	manager := jwt.NewJWTManagerWithRefresh("secret", time.Minute, time.Hour)
+	refreshTokens := &memoryRefreshTokens{revoked: map[string]time.Time{}}
+	events := &capturedAuthEvents{}
+	useCase := NewAuthUseCase(users, manager, refreshTokens, entity.PasswordPolicy{}, nil, events, MFAConfig{})
+	return useCase, manager, refreshTokens, events
+}
+
+func refreshToken(t *testing.T, manager *jwt.JWTManager, userID string) string {
+	t.Helper()
+	token, err := manager.GenerateRefreshToken(userID, "budi@example.com", "employee")
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken: %v", err)
+	}
+	return token
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	ctx := context.Background()
+	useCase, manager, refreshTokens, events := newRefreshTest(t)
+	first := refreshToken(t, manager, "user-1")
+
+	output, err := useCase.Refresh(ctx, RefreshInput{RefreshToken: first})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if output.Token == "" || output.RefreshToken == "" || output.RefreshToken == first {
+		t.Fatalf("Refresh = %+v, want a new access and refresh token", output)
+	}
+	claims, _ := manager.ValidateTokenType(first, jwt.RefreshToken)
+	if expiresAt, ok := refreshTokens.revoked[claims.ID]; !ok || !expiresAt.Equal(claims.ExpiresAt.Time) {
+		t.Errorf("revoked = %v, want %s until it expires", refreshTokens.revoked, claims.ID)
+	}
+
+	// Token lama sudah ditukar, token baru masih bisa dipakai sekali
+	if _, err := useCase.Refresh(ctx, RefreshInput{RefreshToken: first}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh with a used token = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	last := events.logs[len(events.logs)-1]
+	if last.Action != entity.AuditActionTokenRefresh || last.ErrorMsg != authReasonRevokedToken {
+		t.Errorf("audit = %s %q, want a refresh rejected as %s", last.Action, last.ErrorMsg, authReasonRevokedToken)
+	}
+	if _, err := useCase.Refresh(ctx, RefreshInput{RefreshToken: output.RefreshToken}); err != nil {
+		t.Errorf("Refresh with the rotated token = %v", err)
+	}
+}
+
+func TestRefreshRejectsInvalidTokens(t *testing.T) {
+	ctx := context.Background()
+	useCase, manager, refreshTokens, _ := newRefreshTest(t)
+	accessToken, _ := manager.GenerateToken("user-1", "budi@example.com", "employee")
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"access token", accessToken, ErrInvalidRefreshToken},
+		{"unknown user", refreshToken(t, manager, "user-2"), ErrInvalidRefreshToken},
+		{"malformed", "not-a-token", ErrInvalidRefreshToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := useCase.Refresh(ctx, RefreshInput{RefreshToken: tt.token}); !errors.Is(err, tt.want) {
+				t.Errorf("Refresh = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(refreshTokens.revoked) != 0 {
+		t.Errorf("rejected tokens were revoked: %v", refreshTokens.revoked)
+	}
+}
+
+func TestLogoutRevokesRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	useCase, manager, refreshTokens, _ := newRefreshTest(t)
+	own := refreshToken(t, manager, "user-1")
+	other := refreshToken(t, manager, "user-2")
+
+	if err := useCase.Logout(ctx, LogoutInput{UserID: "user-1", RefreshToken: own}); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if _, err := useCase.Refresh(ctx, RefreshInput{RefreshToken: own}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh after Logout = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	// Refresh token milik user lain tidak dicabut, logout tanpa token hanya dicatat
+	if err := useCase.Logout(ctx, LogoutInput{UserID: "user-1", RefreshToken: other}); err != nil {
+		t.Fatalf("Logout with another user's token: %v", err)
+	}
+	if err := useCase.Logout(ctx, LogoutInput{UserID: "user-1"}); err != nil {
+		t.Fatalf("Logout without a token: %v", err)
+	}
+	if len(refreshTokens.revoked) != 1 {
+		t.Errorf("revoked = %d tokens, want only the user's own token", len(refreshTokens.revoked))
+	}
+}
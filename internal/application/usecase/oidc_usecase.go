@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/pkg/jwt"
+)
+
+var (
+	ErrInvalidOIDCState  = errors.New("state login SSO tidak valid atau sudah kadaluarsa")
+	ErrInvalidOIDCNonce  = errors.New("nonce ID token tidak sesuai")
+	ErrSSOUserNotAllowed = errors.New("user tidak diizinkan login melalui SSO")
+)
+
+// OIDCGroupRole memetakan group dari identity provider ke role aplikasi
+type OIDCGroupRole struct {
+	Group string
+	Role  entity.UserRole
+}
+
+// OIDCConfig adalah konfigurasi single sign-on OpenID Connect
+type OIDCConfig struct {
+	// GroupRoles diperiksa berurutan; group pertama yang cocok menentukan role
+	GroupRoles  []OIDCGroupRole
+	DefaultRole entity.UserRole
+
+	// AutoProvision membuat user baru saat login SSO pertama kali
+	AutoProvision bool
+
+	// SyncRole memperbarui role user dari group setiap login
+	SyncRole bool
+
+	StateDuration time.Duration
+
+	// MFA diterapkan sama seperti login password, termasuk MFA wajib untuk admin
+	MFA MFAConfig
+}
+
+// OIDCLoginStart berisi URL redirect ke identity provider dan token state untuk cookie
+type OIDCLoginStart struct {
+	AuthURL    string
+	StateToken string
+}
+
+// OIDCCallbackInput adalah input dari redirect callback identity provider
+type OIDCCallbackInput struct {
+	Code       string
+	State      string
+	StateToken string
+}
+
+// OIDCUseCase adalah interface untuk login single sign-on
+type OIDCUseCase interface {
+	BeginLogin(ctx context.Context) (*OIDCLoginStart, error)
+	CompleteLogin(ctx context.Context, input OIDCCallbackInput) (*AuthOutput, error)
+}
+
+type oidcUseCase struct {
+	provider   repository.IdentityProvider
+	userRepo   repository.UserRepository
+	jwtManager *jwt.JWTManager
+	config     OIDCConfig
+	auditor    authAuditor
+}
+
+// NewOIDCUseCase membuat use case login SSO, auditRepo boleh nil
+func NewOIDCUseCase(provider repository.IdentityProvider, userRepo repository.UserRepository, jwtManager *jwt.JWTManager, auditRepo repository.AuditLogRepository, config OIDCConfig) OIDCUseCase {
+	if !config.DefaultRole.IsValid() {
+		config.DefaultRole = entity.RoleEmployee
+	}
+	if config.StateDuration <= 0 {
+		config.StateDuration = 10 * time.Minute
+	}
+
+	return &oidcUseCase{
+		provider:   provider,
+		userRepo:   userRepo,
+		jwtManager: jwtManager,
+		config:     config,
+		auditor:    authAuditor{auditRepo: auditRepo},
+	}
+}
+
+func (uc *oidcUseCase) BeginLogin(ctx context.Context) (*OIDCLoginStart, error) {
+	state, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	stateToken, err := uc.jwtManager.GenerateOIDCStateToken(state, nonce, verifier, uc.config.StateDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCLoginStart{
+		AuthURL:    uc.provider.AuthCodeURL(state, nonce, codeChallengeS256(verifier)),
+		StateToken: stateToken,
+	}, nil
+}
+
+func (uc *oidcUseCase) CompleteLogin(ctx context.Context, input OIDCCallbackInput) (*AuthOutput, error) {
+	user, email, err := uc.authenticate(ctx, input)
+	if err != nil {
+		event := authEvent{Action: entity.AuditActionLoginFailed, Method: authMethodOIDC, Email: email}
+		if user != nil {
+			event = userEvent(entity.AuditActionLoginFailed, authMethodOIDC, user)
+		}
+		event.Reason = oidcFailureReason(err)
+		uc.auditor.record(ctx, event)
+		return nil, err
+	}
+
+	// Identity provider tidak menggantikan MFA aplikasi, access token baru diberikan setelah
+	// kode diverifikasi lewat /api/auth/mfa/challenge
+	if uc.config.MFA.requires(user) {
+		uc.auditor.record(ctx, userEvent(entity.AuditActionMFAChallenge, authMethodOIDC, user))
+		return newMFAChallenge(uc.jwtManager, uc.config.MFA, user)
+	}
+
+	token, err := uc.jwtManager.GenerateToken(user.ID, user.Email, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := uc.jwtManager.GenerateRefreshToken(user.ID, user.Email, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	uc.auditor.record(ctx, userEvent(entity.AuditActionLogin, authMethodOIDC, user))
+	return &AuthOutput{
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         toUserOutput(user),
+	}, nil
+}
+
+// authenticate memvalidasi callback dan mengembalikan user yang login. Email identitas
+// dikembalikan juga saat gagal agar kegagalan dapat dicatat, user hanya diisi jika tidak aktif.
+func (uc *oidcUseCase) authenticate(ctx context.Context, input OIDCCallbackInput) (*entity.User, string, error) {
+	flow, err := uc.jwtManager.ValidateOIDCStateToken(input.StateToken)
+	if err != nil {
+		return nil, "", ErrInvalidOIDCState
+	}
+	if input.State == "" || subtle.ConstantTimeCompare([]byte(flow.State), []byte(input.State)) != 1 {
+		return nil, "", ErrInvalidOIDCState
+	}
+
+	identity, err := uc.provider.Exchange(ctx, input.Code, flow.CodeVerifier)
+	if err != nil {
+		return nil, "", err
+	}
+	if subtle.ConstantTimeCompare([]byte(flow.Nonce), []byte(identity.Nonce)) != 1 {
+		return nil, identity.Email, ErrInvalidOIDCNonce
+	}
+	if identity.Email == "" {
+		return nil, "", entity.ErrInvalidEmail
+	}
+	if !identity.EmailVerified {
+		return nil, identity.Email, entity.ErrEmailNotVerified
+	}
+
+	user, err := uc.resolveUser(ctx, identity)
+	if err != nil {
+		return nil, identity.Email, err
+	}
+
+	if !user.IsActive {
+		return user, identity.Email, ErrUserNotActive
+	}
+
+	return user, identity.Email, nil
+}
+
+// oidcFailureReason memetakan error login SSO ke alasan pada audit log
+func oidcFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidOIDCState):
+		return authReasonInvalidState
+	case errors.Is(err, ErrInvalidOIDCNonce):
+		return authReasonInvalidNonce
+	case errors.Is(err, entity.ErrEmailNotVerified):
+		return authReasonEmailUnverified
+	case errors.Is(err, ErrSSOUserNotAllowed):
+		return authReasonNotAllowed
+	case errors.Is(err, ErrUserNotActive):
+		return authReasonInactive
+	default:
+		return authReasonSSOError
+	}
+}
+
+// resolveUser mencari user berdasarkan subject, lalu email, dan membuat user baru jika diizinkan
+func (uc *oidcUseCase) resolveUser(ctx context.Context, identity *entity.ExternalIdentity) (*entity.User, error) {
+	role := uc.mapRole(identity.Groups)
+
+	user, err := uc.userRepo.GetByExternalID(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return uc.syncRole(ctx, user, role, false)
+	}
+
+	user, err = uc.userRepo.GetByEmail(ctx, identity.Email)
+	if err != nil {
+		if !uc.config.AutoProvision {
+			return nil, ErrSSOUserNotAllowed
+		}
+
+		user, err = entity.NewSSOUser(identity, role)
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// Akun yang sudah tertaut ke subject lain tidak boleh diambil alih lewat email
+	if user.ExternalID != "" {
+		return nil, ErrSSOUserNotAllowed
+	}
+	user.LinkExternalIdentity(identity)
+
+	return uc.syncRole(ctx, user, role, true)
+}
+
+func (uc *oidcUseCase) syncRole(ctx context.Context, user *entity.User, role entity.UserRole, changed bool) (*entity.User, error) {
+	if uc.config.SyncRole && user.Role != role {
+		user.ChangeRole(role)
+		changed = true
+	}
+
+	if changed {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+func (uc *oidcUseCase) mapRole(groups []string) entity.UserRole {
+	for _, mapping := range uc.config.GroupRoles {
+		for _, group := range groups {
+			if strings.EqualFold(group, mapping.Group) {
+				return mapping.Role
+			}
+		}
+	}
+	return uc.config.DefaultRole
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallengeS256 menghitung PKCE code challenge dari verifier (RFC 7636)
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/pkg/jwt"
+)
+
+const stubIssuer = "https://idp.example.com"
+
+// stubIdentityProvider adalah identity provider lokal: mengingat request authorization terakhir
+// dan hanya menukar code jika verifier cocok dengan code challenge
+type stubIdentityProvider struct {
+	nonce         string
+	codeChallenge string
+	identity      entity.ExternalIdentity
+}
+
+func (p *stubIdentityProvider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	p.nonce = nonce
+	p.codeChallenge = codeChallenge
+	return stubIssuer + "/authorize?" + url.Values{"state": {state}}.Encode()
+}
+
+func (p *stubIdentityProvider) Exchange(ctx context.Context, code, codeVerifier string) (*entity.ExternalIdentity, error) {
+	if code != "code" || codeChallengeS256(codeVerifier) != p.codeChallenge {
+		return nil, errors.New("invalid_grant")
+	}
+	identity := p.identity
+	if identity.Nonce == "" {
+		identity.Nonce = p.nonce
+	}
+	return &identity, nil
+}
+
+// memoryUserRepository menyimpan user di memory, hanya method yang dipakai login SSO dan MFA
+type memoryUserRepository struct {
+	repository.UserRepository
+	users []*entity.User
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *entity.User) error {
+	user.ID = fmt.Sprintf("user-%d", len(r.users)+1)
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *memoryUserRepository) GetByExternalID(ctx context.Context, provider, externalID string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.AuthProvider == provider && user.ExternalID == externalID {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *entity.User) error {
+	return nil
+}
+
+type oidcTest struct {
+	provider   *stubIdentityProvider
+	users      *memoryUserRepository
+	jwtManager *jwt.JWTManager
+	useCase    OIDCUseCase
+}
+
+func newOIDCTest(config OIDCConfig) *oidcTest {
+	test := &oidcTest{
+		provider: &stubIdentityProvider{identity: entity.ExternalIdentity{
+			Provider:      stubIssuer,
+			Subject:       "subject-1",
+			Email:         "budi@example.com",
+			EmailVerified: true,
+			Name:          "Budi",
+			Groups:        []string{"staff", "HR"},
+		}},
+		users:      &memoryUserRepository{},
+		jwtManager: jwt.NewJWTManager("secret", time.Hour),
+	}
+	test.useCase = NewOIDCUseCase(test.provider, test.users, test.jwtManager, nil, config)
+	return test
+}
+
+// login menjalankan flow lengkap: BeginLogin, redirect ke provider, lalu callback dengan state dari URL
+func (test *oidcTest) login(t *testing.T) (*AuthOutput, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	start, err := test.useCase.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	authURL, err := url.Parse(start.AuthURL)
+	if err != nil {
+		t.Fatalf("parse auth URL: %v", err)
+	}
+
+	return test.useCase.CompleteLogin(ctx, OIDCCallbackInput{
+		Code:       "code",
+		State:      authURL.Query().Get("state"),
+		StateToken: start.StateToken,
+	})
+}
+
+func TestOIDCLoginProvisionsUser(t *testing.T) {
+	test := newOIDCTest(OIDCConfig{
+		GroupRoles:    []OIDCGroupRole{{Group: "hr", Role: entity.RoleManager}, {Group: "staff", Role: entity.RoleEmployee}},
+		AutoProvision: true,
+	})
+
+	output, err := test.login(t)
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+
+	if len(test.users.users) != 1 {
+		t.Fatalf("provisioned %d users, want 1", len(test.users.users))
+	}
+	user := test.users.users[0]
+	if user.Role != entity.RoleManager || user.ExternalID != "subject-1" || user.AuthProvider != stubIssuer {
+		t.Errorf("user = role %s, identity %s/%s", user.Role, user.AuthProvider, user.ExternalID)
+	}
+
+	claims, err := test.jwtManager.ValidateTokenType(output.Token, jwt.AccessToken)
+	if err != nil {
+		t.Fatalf("access token: %v", err)
+	}
+	if claims.UserID != user.ID || claims.Role != string(entity.RoleManager) {
+		t.Errorf("claims = %s/%s, want %s/%s", claims.UserID, claims.Role, user.ID, entity.RoleManager)
+	}
+	if _, err := test.jwtManager.ValidateTokenType(output.RefreshToken, jwt.RefreshToken); err != nil {
+		t.Errorf("refresh token: %v", err)
+	}
+
+	// Login berikutnya menemukan user yang sama melalui subject
+	if _, err := test.login(t); err != nil {
+		t.Fatalf("second CompleteLogin: %v", err)
+	}
+	if len(test.users.users) != 1 {
+		t.Errorf("second login provisioned another user")
+	}
+}
+
+func TestOIDCLoginLinksExistingUser(t *testing.T) {
+	test := newOIDCTest(OIDCConfig{SyncRole: true})
+	test.users.users = []*entity.User{{ID: "user-1", Email: "budi@example.com", Role: entity.RoleAdmin, IsActive: true}}
+
+	if _, err := test.login(t); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+
+	user := test.users.users[0]
+	if user.ExternalID != "subject-1" || user.AuthProvider != stubIssuer {
+		t.Errorf("user identity = %s/%s, want %s/subject-1", user.AuthProvider, user.ExternalID, stubIssuer)
+	}
+	if user.Role != entity.RoleEmployee {
+		t.Errorf("role = %s, want the default role %s", user.Role, entity.RoleEmployee)
+	}
+}
+
+func TestOIDCLoginRejectsInvalidCallback(t *testing.T) {
+	ctx := context.Background()
+	test := newOIDCTest(OIDCConfig{AutoProvision: true})
+
+	start, err := test.useCase.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	authURL, _ := url.Parse(start.AuthURL)
+	state := authURL.Query().Get("state")
+
+	accessToken, err := test.jwtManager.GenerateToken("user-1", "budi@example.com", string(entity.RoleAdmin))
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input OIDCCallbackInput
+		want  error
+	}{
+		{"state mismatch", OIDCCallbackInput{Code: "code", State: "other", StateToken: start.StateToken}, ErrInvalidOIDCState},
+		{"missing state", OIDCCallbackInput{Code: "code", StateToken: start.StateToken}, ErrInvalidOIDCState},
+		{"access token as state token", OIDCCallbackInput{Code: "code", State: state, StateToken: accessToken}, ErrInvalidOIDCState},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := test.useCase.CompleteLogin(ctx, tt.input); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Token state tidak pernah diterima sebagai access token
+	if _, err := test.jwtManager.ValidateTokenType(start.StateToken, jwt.AccessToken); err == nil {
+		t.Error("state token accepted as access token")
+	}
+	if len(test.users.users) != 0 {
+		t.Errorf("rejected callbacks provisioned %d users", len(test.users.users))
+	}
+}
+
+func TestOIDCLoginRejectsIdentity(t *testing.T) {
+	tests := []struct {
+		name   string
+		config OIDCConfig
+		modify func(test *oidcTest)
+		want   error
+	}{
+		{"nonce mismatch", OIDCConfig{AutoProvision: true}, func(test *oidcTest) {
+			test.provider.identity.Nonce = "other-nonce"
+		}, ErrInvalidOIDCNonce},
+		{"unverified email", OIDCConfig{AutoProvision: true}, func(test *oidcTest) {
+			test.provider.identity.EmailVerified = false
+		}, entity.ErrEmailNotVerified},
+		{"provisioning disabled", OIDCConfig{}, func(test *oidcTest) {}, ErrSSOUserNotAllowed},
+		{"email linked to another subject", OIDCConfig{AutoProvision: true}, func(test *oidcTest) {
+			test.users.users = []*entity.User{{
+				ID: "user-1", Email: "budi@example.com", IsActive: true,
+				AuthProvider: stubIssuer, ExternalID: "subject-2",
+			}}
+		}, ErrSSOUserNotAllowed},
+		{"inactive user", OIDCConfig{}, func(test *oidcTest) {
+			test.users.users = []*entity.User{{ID: "user-1", Email: "budi@example.com"}}
+		}, ErrUserNotActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newOIDCTest(tt.config)
+			tt.modify(test)
+
+			output, err := test.login(t)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if output != nil {
+				t.Errorf("output = %+v, want nil", output)
+			}
+		})
+	}
+}
+
+func TestOIDCLoginRequiresMFA(t *testing.T) {
+	mfa := MFAConfig{RequiredForAdmin: true, ChallengeDuration: 5 * time.Minute}
+
+	tests := []struct {
+		name           string
+		config         OIDCConfig
+		users          []*entity.User
+		wantMFA        bool
+		wantEnrollment bool
+	}{
+		{
+			name:   "enrolled user linked by subject",
+			config: OIDCConfig{MFA: mfa},
+			users: []*entity.User{{
+				ID: "user-1", Email: "budi@example.com", Role: entity.RoleEmployee, IsActive: true,
+				AuthProvider: stubIssuer, ExternalID: "subject-1", MFAEnabled: true,
+			}},
+			wantMFA: true,
+		},
+		{
+			name:    "enrolled user linked by email",
+			config:  OIDCConfig{MFA: mfa},
+			users:   []*entity.User{{ID: "user-1", Email: "budi@example.com", Role: entity.RoleEmployee, IsActive: true, MFAEnabled: true}},
+			wantMFA: true,
+		},
+		{
+			name: "admin from group mapping",
+			config: OIDCConfig{
+				GroupRoles:    []OIDCGroupRole{{Group: "hr", Role: entity.RoleAdmin}},
+				AutoProvision: true,
+				MFA:           mfa,
+			},
+			wantMFA:        true,
+			wantEnrollment: true,
+		},
+		{
+			name:   "admin without mandatory MFA",
+			config: OIDCConfig{MFA: MFAConfig{ChallengeDuration: 5 * time.Minute}},
+			users:  []*entity.User{{ID: "user-1", Email: "budi@example.com", Role: entity.RoleAdmin, IsActive: true}},
+		},
+		{
+			name:   "employee without MFA",
+			config: OIDCConfig{MFA: mfa},
+			users:  []*entity.User{{ID: "user-1", Email: "budi@example.com", Role: entity.RoleEmployee, IsActive: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newOIDCTest(tt.config)
+			test.users.users = tt.users
+
+			output, err := test.login(t)
+			if err != nil {
+				t.Fatalf("CompleteLogin: %v", err)
+			}
+
+			if !tt.wantMFA {
+				if output.MFARequired || output.Token == "" {
+					t.Fatalf("output = %+v, want tokens", output)
+				}
+				return
+			}
+
+			if !output.MFARequired || output.MFAEnrollmentRequired != tt.wantEnrollment {
+				t.Errorf("MFA required = %v, enrollment = %v, want true, %v", output.MFARequired, output.MFAEnrollmentRequired, tt.wantEnrollment)
+			}
+			if output.Token != "" || output.RefreshToken != "" {
+				t.Errorf("tokens issued before MFA verification")
+			}
+			if _, err := test.jwtManager.ValidateTokenType(output.MFAToken, jwt.MFAChallengeToken); err != nil {
+				t.Errorf("MFA token: %v", err)
+			}
+			if _, err := test.jwtManager.ValidateTokenType(output.MFAToken, jwt.AccessToken); err == nil {
+				t.Error("MFA token accepted as access token")
+			}
+		})
+	}
+}
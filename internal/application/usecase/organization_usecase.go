@@ -0,0 +1,368 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/internal/domain/service"
+)
+
+var (
+	ErrDepartmentNotFound = errors.New("department tidak ditemukan")
+	ErrTeamNotFound       = errors.New("team tidak ditemukan")
+	ErrManagerNotFound    = errors.New("manager tidak ditemukan")
+	ErrDepartmentNotEmpty = errors.New("department masih memiliki sub-department, team, atau anggota")
+	ErrTeamNotEmpty       = errors.New("team masih memiliki anggota")
+)
+
+// DepartmentInput adalah input untuk membuat atau mengubah department
+type DepartmentInput struct {
+	Name      string
+	ParentID  string
+	ManagerID string
+}
+
+// TeamInput adalah input untuk membuat atau mengubah team
+type TeamInput struct {
+	Name         string
+	DepartmentID string
+	ManagerID    string
+}
+
+// AssignOrganizationInput adalah input untuk menempatkan user di department, team, dan manager
+type AssignOrganizationInput struct {
+	DepartmentID string
+	TeamID       string
+	ManagerID    string
+}
+
+// DepartmentOutput adalah output untuk department
+type DepartmentOutput struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ParentID  string `json:"parent_id,omitempty"`
+	ManagerID string `json:"manager_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// TeamOutput adalah output untuk team
+type TeamOutput struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	DepartmentID string `json:"department_id"`
+	ManagerID    string `json:"manager_id,omitempty"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// OrganizationUseCase adalah interface untuk use case department, team, dan hierarki pelaporan
+type OrganizationUseCase interface {
+	GetDepartments(ctx context.Context) ([]DepartmentOutput, error)
+	CreateDepartment(ctx context.Context, input DepartmentInput) (*DepartmentOutput, error)
+	UpdateDepartment(ctx context.Context, id string, input DepartmentInput) (*DepartmentOutput, error)
+	DeleteDepartment(ctx context.Context, id string) error
+
+	GetTeams(ctx context.Context) ([]TeamOutput, error)
+	CreateTeam(ctx context.Context, input TeamInput) (*TeamOutput, error)
+	UpdateTeam(ctx context.Context, id string, input TeamInput) (*TeamOutput, error)
+	DeleteTeam(ctx context.Context, id string) error
+
+	AssignUser(ctx context.Context, userID string, input AssignOrganizationInput) (*UserOutput, error)
+	GetTree(ctx context.Context) ([]entity.OrgTreeDepartment, error)
+}
+
+type organizationUseCase struct {
+	departmentRepo repository.DepartmentRepository
+	teamRepo       repository.TeamRepository
+	userRepo       repository.UserRepository
+	orgService     *service.OrganizationService
+}
+
+func NewOrganizationUseCase(departmentRepo repository.DepartmentRepository, teamRepo repository.TeamRepository, userRepo repository.UserRepository, orgService *service.OrganizationService) OrganizationUseCase {
+	return &organizationUseCase{
+		departmentRepo: departmentRepo,
+		teamRepo:       teamRepo,
+		userRepo:       userRepo,
+		orgService:     orgService,
+	}
+}
+
+func (uc *organizationUseCase) GetDepartments(ctx context.Context) ([]DepartmentOutput, error) {
+	departments, err := uc.departmentRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]DepartmentOutput, len(departments))
+	for i, d := range departments {
+		outputs[i] = *toDepartmentOutput(&d)
+	}
+	return outputs, nil
+}
+
+func (uc *organizationUseCase) CreateDepartment(ctx context.Context, input DepartmentInput) (*DepartmentOutput, error) {
+	if err := uc.validateDepartmentRefs(ctx, input); err != nil {
+		return nil, err
+	}
+
+	department, err := entity.NewDepartment(input.Name, input.ParentID, input.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.departmentRepo.Create(ctx, department); err != nil {
+		return nil, err
+	}
+
+	return toDepartmentOutput(department), nil
+}
+
+func (uc *organizationUseCase) UpdateDepartment(ctx context.Context, id string, input DepartmentInput) (*DepartmentOutput, error) {
+	department, err := uc.departmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrDepartmentNotFound
+	}
+
+	if err := uc.validateDepartmentRefs(ctx, input); err != nil {
+		return nil, err
+	}
+	if err := uc.orgService.ValidateParent(ctx, id, input.ParentID); err != nil {
+		return nil, err
+	}
+
+	if err := department.Update(input.Name, input.ParentID, input.ManagerID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.departmentRepo.Update(ctx, department); err != nil {
+		return nil, err
+	}
+
+	return toDepartmentOutput(department), nil
+}
+
+func (uc *organizationUseCase) DeleteDepartment(ctx context.Context, id string) error {
+	if _, err := uc.departmentRepo.GetByID(ctx, id); err != nil {
+		return ErrDepartmentNotFound
+	}
+
+	departments, err := uc.departmentRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, d := range departments {
+		if d.ParentID == id {
+			return ErrDepartmentNotEmpty
+		}
+	}
+
+	teams, err := uc.teamRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range teams {
+		if t.DepartmentID == id {
+			return ErrDepartmentNotEmpty
+		}
+	}
+
+	members, err := uc.userRepo.FindIDs(ctx, repository.UserFilter{DepartmentIDs: []string{id}})
+	if err != nil {
+		return err
+	}
+	if len(members) > 0 {
+		return ErrDepartmentNotEmpty
+	}
+
+	return uc.departmentRepo.Delete(ctx, id)
+}
+
+func (uc *organizationUseCase) GetTeams(ctx context.Context) ([]TeamOutput, error) {
+	teams, err := uc.teamRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]TeamOutput, len(teams))
+	for i, t := range teams {
+		outputs[i] = *toTeamOutput(&t)
+	}
+	return outputs, nil
+}
+
+func (uc *organizationUseCase) CreateTeam(ctx context.Context, input TeamInput) (*TeamOutput, error) {
+	if err := uc.validateTeamRefs(ctx, input); err != nil {
+		return nil, err
+	}
+
+	team, err := entity.NewTeam(input.Name, input.DepartmentID, input.ManagerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.teamRepo.Create(ctx, team); err != nil {
+		return nil, err
+	}
+
+	return toTeamOutput(team), nil
+}
+
+func (uc *organizationUseCase) UpdateTeam(ctx context.Context, id string, input TeamInput) (*TeamOutput, error) {
+	team, err := uc.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrTeamNotFound
+	}
+
+	if err := uc.validateTeamRefs(ctx, input); err != nil {
+		return nil, err
+	}
+
+	// Memindahkan team ke department lain juga memindahkan anggotanya
+	movedDepartment := team.DepartmentID != input.DepartmentID
+
+	if err := team.Update(input.Name, input.DepartmentID, input.ManagerID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.teamRepo.Update(ctx, team); err != nil {
+		return nil, err
+	}
+
+	if movedDepartment {
+		members, err := uc.userRepo.Find(ctx, repository.UserFilter{TeamIDs: []string{team.ID}})
+		if err != nil {
+			return nil, err
+		}
+		for i := range members {
+			member := &members[i]
+			if err := member.AssignOrganization(team.DepartmentID, member.TeamID, member.ManagerID); err != nil {
+				return nil, err
+			}
+			if err := uc.userRepo.Update(ctx, member); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return toTeamOutput(team), nil
+}
+
+func (uc *organizationUseCase) DeleteTeam(ctx context.Context, id string) error {
+	if _, err := uc.teamRepo.GetByID(ctx, id); err != nil {
+		return ErrTeamNotFound
+	}
+
+	members, err := uc.userRepo.FindIDs(ctx, repository.UserFilter{TeamIDs: []string{id}})
+	if err != nil {
+		return err
+	}
+	if len(members) > 0 {
+		return ErrTeamNotEmpty
+	}
+
+	return uc.teamRepo.Delete(ctx, id)
+}
+
+func (uc *organizationUseCase) AssignUser(ctx context.Context, userID string, input AssignOrganizationInput) (*UserOutput, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	departmentID := input.DepartmentID
+	if input.TeamID != "" {
+		team, err := uc.teamRepo.GetByID(ctx, input.TeamID)
+		if err != nil {
+			return nil, ErrTeamNotFound
+		}
+		// Department mengikuti team jika tidak diisi
+		if departmentID == "" {
+			departmentID = team.DepartmentID
+		}
+		if departmentID != team.DepartmentID {
+			return nil, entity.ErrTeamDepartmentMismatch
+		}
+	}
+
+	if departmentID != "" {
+		if _, err := uc.departmentRepo.GetByID(ctx, departmentID); err != nil {
+			return nil, ErrDepartmentNotFound
+		}
+	}
+
+	if input.ManagerID != "" {
+		if _, err := uc.userRepo.GetByID(ctx, input.ManagerID); err != nil {
+			return nil, ErrManagerNotFound
+		}
+		if err := uc.orgService.ValidateManager(ctx, userID, input.ManagerID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := user.AssignOrganization(departmentID, input.TeamID, input.ManagerID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return toUserOutput(user), nil
+}
+
+func (uc *organizationUseCase) GetTree(ctx context.Context) ([]entity.OrgTreeDepartment, error) {
+	return uc.orgService.BuildTree(ctx)
+}
+
+func (uc *organizationUseCase) validateDepartmentRefs(ctx context.Context, input DepartmentInput) error {
+	if input.ParentID != "" {
+		if _, err := uc.departmentRepo.GetByID(ctx, input.ParentID); err != nil {
+			return ErrDepartmentNotFound
+		}
+	}
+	if input.ManagerID != "" {
+		if _, err := uc.userRepo.GetByID(ctx, input.ManagerID); err != nil {
+			return ErrManagerNotFound
+		}
+	}
+	return nil
+}
+
+func (uc *organizationUseCase) validateTeamRefs(ctx context.Context, input TeamInput) error {
+	if input.DepartmentID != "" {
+		if _, err := uc.departmentRepo.GetByID(ctx, input.DepartmentID); err != nil {
+			return ErrDepartmentNotFound
+		}
+	}
+	if input.ManagerID != "" {
+		if _, err := uc.userRepo.GetByID(ctx, input.ManagerID); err != nil {
+			return ErrManagerNotFound
+		}
+	}
+	return nil
+}
+
+func toDepartmentOutput(d *entity.Department) *DepartmentOutput {
+	return &DepartmentOutput{
+		ID:        d.ID,
+		Name:      d.Name,
+		ParentID:  d.ParentID,
+		ManagerID: d.ManagerID,
+		CreatedAt: d.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: d.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func toTeamOutput(t *entity.Team) *TeamOutput {
+	return &TeamOutput{
+		ID:           t.ID,
+		Name:         t.Name,
+		DepartmentID: t.DepartmentID,
+		ManagerID:    t.ManagerID,
+		CreatedAt:    t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:    t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -16,8 +16,7 @@ var (
 
 // CreatePresensiInput adalah input untuk membuat presensi
 type CreatePresensiInput struct {
-	UserID     string
-	Nama       string
+	UserID     string // Kosong berarti presensi milik actor
 	Status     string
 	Keterangan string
 	Latitude   float64
@@ -54,28 +53,53 @@ type LokasiOutput struct {
 
 // PresensiUseCase adalah interface untuk use case presensi
 type PresensiUseCase interface {
-	Create(ctx context.Context, input CreatePresensiInput) (*PresensiOutput, error)
-	GetByID(ctx context.Context, id string) (*PresensiOutput, error)
-	GetAll(ctx context.Context, filter repository.PresensiFilter, page, limit int) ([]PresensiOutput, int64, error)
-	Update(ctx context.Context, id string, input UpdatePresensiInput) (*PresensiOutput, error)
-	Delete(ctx context.Context, id string) error
-	CheckIn(ctx context.Context, id string) error
-	CheckOut(ctx context.Context, id string) error
+	Create(ctx context.Context, actor Actor, input CreatePresensiInput) (*PresensiOutput, error)
+	GetByID(ctx context.Context, actor Actor, id string) (*PresensiOutput, error)
+	GetAll(ctx context.Context, actor Actor, filter repository.PresensiFilter, page, limit int) ([]PresensiOutput, int64, error)
+	Update(ctx context.Context, actor Actor, id string, input UpdatePresensiInput) (*PresensiOutput, error)
+	Delete(ctx context.Context, actor Actor, id string) error
+	CheckIn(ctx context.Context, actor Actor, id string) error
+	CheckOut(ctx context.Context, actor Actor, id string) error
 }
 
 type presensiUseCase struct {
 	repo            repository.PresensiRepository
+	userRepo        repository.UserRepository
 	locationService *service.LocationService
+	orgService      *service.OrganizationService
+	access          accessScope
 }
 
-func NewPresensiUseCase(repo repository.PresensiRepository, locationService *service.LocationService) PresensiUseCase {
+func NewPresensiUseCase(repo repository.PresensiRepository, userRepo repository.UserRepository, locationService *service.LocationService, orgService *service.OrganizationService, roles RoleResolver) PresensiUseCase {
 	return &presensiUseCase{
 		repo:            repo,
+		userRepo:        userRepo,
 		locationService: locationService,
+		orgService:      orgService,
+		access:          accessScope{roles: roles, orgService: orgService},
 	}
 }
 
-func (uc *presensiUseCase) Create(ctx context.Context, input CreatePresensiInput) (*PresensiOutput, error) {
+func (uc *presensiUseCase) Create(ctx context.Context, actor Actor, input CreatePresensiInput) (*PresensiOutput, error) {
+	// Presensi hanya boleh dibuat untuk user dalam scope actor, employee hanya untuk dirinya sendiri
+	userID := input.UserID
+	if userID == "" {
+		userID = actor.UserID
+	}
+	allowed, err := uc.access.canSee(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed || userID == "" {
+		return nil, ErrAccessDenied
+	}
+
+	// Nama diambil dari user yang tersimpan, bukan dari request
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
 	// Validate location if geofencing is enabled
 	if uc.locationService != nil {
 		if err := uc.locationService.ValidateCheckInLocation(ctx, input.Latitude, input.Longitude); err != nil {
@@ -86,8 +110,8 @@ func (uc *presensiUseCase) Create(ctx context.Context, input CreatePresensiInput
 	lokasi := valueobject.NewLokasi(input.Latitude, input.Longitude, input.Alamat)
 
 	presensi, err := entity.NewPresensi(
-		input.UserID,
-		input.Nama,
+		userID,
+		user.Nama,
 		valueobject.StatusPresensi(input.Status),
 		input.Keterangan,
 		lokasi,
@@ -103,15 +127,35 @@ func (uc *presensiUseCase) Create(ctx context.Context, input CreatePresensiInput
 	return toPresensiOutput(presensi), nil
 }
 
-func (uc *presensiUseCase) GetByID(ctx context.Context, id string) (*PresensiOutput, error) {
+func (uc *presensiUseCase) GetByID(ctx context.Context, actor Actor, id string) (*PresensiOutput, error) {
+	presensi, err := uc.getVisible(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPresensiOutput(presensi), nil
+}
+
+// getVisible mengambil presensi yang boleh dilihat actor.
+// Presensi di luar scope diperlakukan seperti tidak ada.
+func (uc *presensiUseCase) getVisible(ctx context.Context, actor Actor, id string) (*entity.Presensi, error) {
 	presensi, err := uc.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, ErrPresensiNotFound
 	}
-	return toPresensiOutput(presensi), nil
+
+	allowed, err := uc.access.canSee(ctx, actor, presensi.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrPresensiNotFound
+	}
+
+	return presensi, nil
 }
 
-func (uc *presensiUseCase) GetAll(ctx context.Context, filter repository.PresensiFilter, page, limit int) ([]PresensiOutput, int64, error) {
+func (uc *presensiUseCase) GetAll(ctx context.Context, actor Actor, filter repository.PresensiFilter, page, limit int) ([]PresensiOutput, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -119,6 +163,16 @@ func (uc *presensiUseCase) GetAll(ctx context.Context, filter repository.Presens
 		limit = 10
 	}
 
+	userIDs, err := uc.orgService.ResolveMembers(ctx, filter.DepartmentID, filter.TeamID, filter.ManagerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	visible, err := uc.access.visibleUserIDs(ctx, actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.UserIDs = service.RestrictUserIDs(userIDs, visible)
+
 	presensiList, total, err := uc.repo.GetAll(ctx, filter, page, limit)
 	if err != nil {
 		return nil, 0, err
@@ -132,10 +186,10 @@ func (uc *presensiUseCase) GetAll(ctx context.Context, filter repository.Presens
 	return outputs, total, nil
 }
 
-func (uc *presensiUseCase) Update(ctx context.Context, id string, input UpdatePresensiInput) (*PresensiOutput, error) {
-	presensi, err := uc.repo.GetByID(ctx, id)
+func (uc *presensiUseCase) Update(ctx context.Context, actor Actor, id string, input UpdatePresensiInput) (*PresensiOutput, error) {
+	presensi, err := uc.getVisible(ctx, actor, id)
 	if err != nil {
-		return nil, ErrPresensiNotFound
+		return nil, err
 	}
 
 	if input.Status != "" {
@@ -154,18 +208,17 @@ func (uc *presensiUseCase) Update(ctx context.Context, id string, input UpdatePr
 	return toPresensiOutput(presensi), nil
 }
 
-func (uc *presensiUseCase) Delete(ctx context.Context, id string) error {
-	_, err := uc.repo.GetByID(ctx, id)
-	if err != nil {
-		return ErrPresensiNotFound
+func (uc *presensiUseCase) Delete(ctx context.Context, actor Actor, id string) error {
+	if _, err := uc.getVisible(ctx, actor, id); err != nil {
+		return err
 	}
 	return uc.repo.Delete(ctx, id)
 }
 
-func (uc *presensiUseCase) CheckIn(ctx context.Context, id string) error {
-	presensi, err := uc.repo.GetByID(ctx, id)
+func (uc *presensiUseCase) CheckIn(ctx context.Context, actor Actor, id string) error {
+	presensi, err := uc.getVisible(ctx, actor, id)
 	if err != nil {
-		return ErrPresensiNotFound
+		return err
 	}
 
 	if err := presensi.CheckIn(); err != nil {
@@ -175,10 +228,10 @@ func (uc *presensiUseCase) CheckIn(ctx context.Context, id string) error {
 	return uc.repo.Update(ctx, presensi)
 }
 
-func (uc *presensiUseCase) CheckOut(ctx context.Context, id string) error {
-	presensi, err := uc.repo.GetByID(ctx, id)
+func (uc *presensiUseCase) CheckOut(ctx context.Context, actor Actor, id string) error {
+	presensi, err := uc.getVisible(ctx, actor, id)
 	if err != nil {
-		return ErrPresensiNotFound
+		return err
 	}
 
 	if err := presensi.CheckOut(); err != nil {
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/internal/domain/service"
+	"github.com/okinn/service-presensi/internal/domain/valueobject"
+)
+
+// memoryPresensiRepository menyimpan presensi di memory berdasarkan ID
+type memoryPresensiRepository struct {
+	repository.PresensiRepository
+	records map[string]*entity.Presensi
+}
+
+func (r *memoryPresensiRepository) Create(ctx context.Context, presensi *entity.Presensi) error {
+	presensi.ID = "presensi-new"
+	r.records[presensi.ID] = presensi
+	return nil
+}
+
+func (r *memoryPresensiRepository) GetByID(ctx context.Context, id string) (*entity.Presensi, error) {
+	presensi, ok := r.records[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *presensi
+	return &copied, nil
+}
+
+func (r *memoryPresensiRepository) Update(ctx context.Context, presensi *entity.Presensi) error {
+	r.records[presensi.ID] = presensi
+	return nil
+}
+
+func (r *memoryPresensiRepository) Delete(ctx context.Context, id string) error {
+	delete(r.records, id)
+	return nil
+}
+
+// builtInRoles me-resolve role bawaan saja
+type builtInRoles struct{}
+
+func (builtInRoles) Resolve(ctx context.Context, name string) (*entity.Role, error) {
+	if role, ok := entity.BuiltInRole(name); ok {
+		return role, nil
+	}
+	return nil, ErrRoleNotFound
+}
+
+var (
+	employeeActor = Actor{UserID: "user-1", Role: string(entity.RoleEmployee)}
+	adminActor    = Actor{UserID: "admin-1", Role: string(entity.RoleAdmin)}
+)
+
+func newPresensiTest(t *testing.T) (PresensiUseCase, *memoryPresensiRepository) {
+	t.Helper()
+	repo := &memoryPresensiRepository{records: map[string]*entity.Presensi{}}
+	for id, userID := range map[string]string{"own": "user-1", "other": "user-2"} {
+		presensi, err := entity.NewPresensi(userID, "Budi", valueobject.StatusIzin, "", nil)
+		if err != nil {
+			t.Fatalf("NewPresensi: %v", err)
+		}
+		presensi.ID = id
+		repo.records[id] = presensi
+	}
+
+	users := &memoryUserRepository{users: []*entity.User{
+		{ID: "user-1", Nama: "Budi"},
+		{ID: "user-2", Nama: "Siti"},
+	}}
+	orgService := service.NewOrganizationService(nil, nil, nil)
+	return NewPresensiUseCase(repo, users, nil, orgService, builtInRoles{}), repo
+}
+
+func TestPresensiUpdateScope(t *testing.T) {
+	ctx := context.Background()
+	useCase, repo := newPresensiTest(t)
+	input := UpdatePresensiInput{Keterangan: "diubah"}
+
+	if _, err := useCase.Update(ctx, employeeActor, "other", input); !errors.Is(err, ErrPresensiNotFound) {
+		t.Errorf("employee updating another user's record: err = %v, want ErrPresensiNotFound", err)
+	}
+	if repo.records["other"].Keterangan == "diubah" {
+		t.Error("record outside the scope was updated")
+	}
+
+	if _, err := useCase.Update(ctx, employeeActor, "own", input); err != nil {
+		t.Errorf("employee updating own record: %v", err)
+	}
+	if _, err := useCase.Update(ctx, adminActor, "other", input); err != nil {
+		t.Errorf("admin updating another user's record: %v", err)
+	}
+	if _, err := useCase.Update(ctx, Actor{}, "own", input); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("anonymous update: err = %v, want ErrAccessDenied", err)
+	}
+}
+
+func TestPresensiDeleteScope(t *testing.T) {
+	ctx := context.Background()
+	useCase, repo := newPresensiTest(t)
+
+	if err := useCase.Delete(ctx, employeeActor, "other"); !errors.Is(err, ErrPresensiNotFound) {
+		t.Errorf("employee deleting another user's record: err = %v, want ErrPresensiNotFound", err)
+	}
+	if _, ok := repo.records["other"]; !ok {
+		t.Error("record outside the scope was deleted")
+	}
+
+	if err := useCase.Delete(ctx, adminActor, "other"); err != nil {
+		t.Errorf("admin deleting another user's record: %v", err)
+	}
+	if err := useCase.Delete(ctx, adminActor, "missing"); !errors.Is(err, ErrPresensiNotFound) {
+		t.Errorf("deleting a missing record: err = %v, want ErrPresensiNotFound", err)
+	}
+}
+
+func TestPresensiCreateScope(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		actor    Actor
+		userID   string
+		wantUser string
+		wantNama string
+		wantErr  error
+	}{
+		{"employee without user ID", employeeActor, "", "user-1", "Budi", nil},
+		{"employee for themselves", employeeActor, "user-1", "user-1", "Budi", nil},
+		{"employee for another user", employeeActor, "user-2", "", "", ErrAccessDenied},
+		{"admin for another user", adminActor, "user-2", "user-2", "Siti", nil},
+		{"admin for a missing user", adminActor, "user-3", "", "", ErrUserNotFound},
+		{"anonymous", Actor{}, "user-1", "", "", ErrAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase, repo := newPresensiTest(t)
+
+			output, err := useCase.Create(ctx, tt.actor, CreatePresensiInput{UserID: tt.userID, Status: "izin"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if _, ok := repo.records["presensi-new"]; ok {
+					t.Error("record was created")
+				}
+				return
+			}
+			if output.UserID != tt.wantUser {
+				t.Errorf("user ID = %q, want %q", output.UserID, tt.wantUser)
+			}
+			// Nama selalu dari user yang tersimpan
+			if output.Nama != tt.wantNama {
+				t.Errorf("nama = %q, want %q", output.Nama, tt.wantNama)
+			}
+		})
+	}
+}
+
+func TestPresensiCheckInOutScope(t *testing.T) {
+	ctx := context.Background()
+	useCase, repo := newPresensiTest(t)
+
+	if err := useCase.CheckIn(ctx, employeeActor, "other"); !errors.Is(err, ErrPresensiNotFound) {
+		t.Errorf("employee checking in another user's record: err = %v, want ErrPresensiNotFound", err)
+	}
+	if err := useCase.CheckOut(ctx, employeeActor, "other"); !errors.Is(err, ErrPresensiNotFound) {
+		t.Errorf("employee checking out another user's record: err = %v, want ErrPresensiNotFound", err)
+	}
+	if other := repo.records["other"]; other.JamMasuk != nil || other.JamKeluar != nil {
+		t.Error("record outside the scope was checked in or out")
+	}
+
+	if err := useCase.CheckIn(ctx, employeeActor, "own"); err != nil {
+		t.Fatalf("employee checking in own record: %v", err)
+	}
+	if err := useCase.CheckOut(ctx, employeeActor, "own"); err != nil {
+		t.Fatalf("employee checking out own record: %v", err)
+	}
+	if own := repo.records["own"]; own.JamMasuk == nil || own.JamKeluar == nil {
+		t.Error("own record was not checked in and out")
+	}
+}
@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role tidak ditemukan")
+	ErrRoleAlreadyExists = errors.New("role sudah ada")
+	ErrRoleInUse         = errors.New("role masih dipakai oleh user")
+)
+
+// roleCacheTTL membatasi berapa lama perubahan custom role dan role user dari instance lain belum terlihat
+const roleCacheTTL = 30 * time.Second
+
+// RoleInput adalah input untuk membuat atau mengubah custom role
+type RoleInput struct {
+	Name        string
+	Description string
+	Permissions []string
+	DataScope   string
+}
+
+// RoleOutput adalah output untuk role
+type RoleOutput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+	DataScope   string   `json:"data_scope"`
+	BuiltIn     bool     `json:"built_in"`
+}
+
+// RoleUseCase adalah interface untuk use case role dan permission
+type RoleUseCase interface {
+	GetAll(ctx context.Context) ([]RoleOutput, error)
+	GetPermissions() []string
+	Create(ctx context.Context, input RoleInput) (*RoleOutput, error)
+	Update(ctx context.Context, name string, input RoleInput) (*RoleOutput, error)
+	Delete(ctx context.Context, name string) error
+	AssignRole(ctx context.Context, userID, role string) (*UserOutput, error)
+
+	// Resolve mengembalikan role bawaan atau custom berdasarkan nama
+	Resolve(ctx context.Context, name string) (*entity.Role, error)
+
+	// HasPermission dipakai middleware untuk mengecek permission role user
+	HasPermission(ctx context.Context, role string, permission entity.Permission) (bool, error)
+
+	// UserRole mengembalikan role yang tersimpan untuk user, dipakai middleware sebagai pengganti
+	// role di JWT agar perubahan role berlaku sebelum token kadaluarsa
+	UserRole(ctx context.Context, userID string) (string, error)
+}
+
+type cachedRole struct {
+	role      *entity.Role // nil jika role tidak ditemukan
+	expiresAt time.Time
+}
+
+type cachedUserRole struct {
+	role      string
+	expiresAt time.Time
+}
+
+type roleUseCase struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+
+	mu        sync.RWMutex
+	cache     map[string]cachedRole
+	userRoles map[string]cachedUserRole
+}
+
+func NewRoleUseCase(roleRepo repository.RoleRepository, userRepo repository.UserRepository) RoleUseCase {
+	return &roleUseCase{
+		roleRepo:  roleRepo,
+		userRepo:  userRepo,
+		cache:     make(map[string]cachedRole),
+		userRoles: make(map[string]cachedUserRole),
+	}
+}
+
+func (uc *roleUseCase) GetAll(ctx context.Context) ([]RoleOutput, error) {
+	customRoles, err := uc.roleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	builtIn := entity.BuiltInRoles()
+	outputs := make([]RoleOutput, 0, len(builtIn)+len(customRoles))
+	for i := range builtIn {
+		outputs = append(outputs, *toRoleOutput(&builtIn[i]))
+	}
+	for i := range customRoles {
+		outputs = append(outputs, *toRoleOutput(&customRoles[i]))
+	}
+
+	return outputs, nil
+}
+
+func (uc *roleUseCase) GetPermissions() []string {
+	permissions := make([]string, len(entity.AllPermissions))
+	for i, permission := range entity.AllPermissions {
+		permissions[i] = string(permission)
+	}
+	return permissions
+}
+
+func (uc *roleUseCase) Create(ctx context.Context, input RoleInput) (*RoleOutput, error) {
+	existing, err := uc.roleRepo.GetByName(ctx, input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrRoleAlreadyExists
+	}
+
+	role, err := entity.NewRole(input.Name, input.Description, toPermissions(input.Permissions), entity.DataScope(input.DataScope))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+	uc.invalidate(role.Name)
+
+	return toRoleOutput(role), nil
+}
+
+func (uc *roleUseCase) Update(ctx context.Context, name string, input RoleInput) (*RoleOutput, error) {
+	if _, ok := entity.BuiltInRole(name); ok {
+		return nil, entity.ErrBuiltInRole
+	}
+
+	role, err := uc.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	if err := role.Update(input.Description, toPermissions(input.Permissions), entity.DataScope(input.DataScope)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.roleRepo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+	uc.invalidate(name)
+
+	return toRoleOutput(role), nil
+}
+
+func (uc *roleUseCase) Delete(ctx context.Context, name string) error {
+	if _, ok := entity.BuiltInRole(name); ok {
+		return entity.ErrBuiltInRole
+	}
+
+	role, err := uc.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return ErrRoleNotFound
+	}
+
+	count, err := uc.userRepo.CountByRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+
+	if err := uc.roleRepo.Delete(ctx, name); err != nil {
+		return err
+	}
+	uc.invalidate(name)
+
+	return nil
+}
+
+func (uc *roleUseCase) AssignRole(ctx context.Context, userID, role string) (*UserOutput, error) {
+	if _, err := uc.Resolve(ctx, role); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	user.ChangeRole(entity.UserRole(role))
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Instance ini langsung memakai role baru, instance lain setelah roleCacheTTL
+	uc.mu.Lock()
+	delete(uc.userRoles, user.ID)
+	uc.mu.Unlock()
+
+	return toUserOutput(user), nil
+}
+
+func (uc *roleUseCase) UserRole(ctx context.Context, userID string) (string, error) {
+	uc.mu.RLock()
+	cached, ok := uc.userRoles[userID]
+	uc.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.role, nil
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", ErrUserNotFound
+	}
+
+	uc.mu.Lock()
+	uc.userRoles[userID] = cachedUserRole{role: string(user.Role), expiresAt: time.Now().Add(roleCacheTTL)}
+	uc.mu.Unlock()
+
+	return string(user.Role), nil
+}
+
+func (uc *roleUseCase) Resolve(ctx context.Context, name string) (*entity.Role, error) {
+	if role, ok := entity.BuiltInRole(name); ok {
+		return role, nil
+	}
+
+	uc.mu.RLock()
+	cached, ok := uc.cache[name]
+	uc.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		if cached.role == nil {
+			return nil, ErrRoleNotFound
+		}
+		return cached.role, nil
+	}
+
+	// Role yang tidak ditemukan juga di-cache, error koneksi tidak
+	role, err := uc.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	uc.cache[name] = cachedRole{role: role, expiresAt: time.Now().Add(roleCacheTTL)}
+	uc.mu.Unlock()
+
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (uc *roleUseCase) HasPermission(ctx context.Context, role string, permission entity.Permission) (bool, error) {
+	resolved, err := uc.Resolve(ctx, role)
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return resolved.HasPermission(permission), nil
+}
+
+func (uc *roleUseCase) invalidate(name string) {
+	uc.mu.Lock()
+	delete(uc.cache, name)
+	uc.mu.Unlock()
+}
+
+func toPermissions(values []string) []entity.Permission {
+	permissions := make([]entity.Permission, len(values))
+	for i, value := range values {
+		permissions[i] = entity.Permission(value)
+	}
+	return permissions
+}
+
+func toRoleOutput(role *entity.Role) *RoleOutput {
+	permissions := make([]string, len(role.Permissions))
+	for i, permission := range role.Permissions {
+		permissions[i] = string(permission)
+	}
+
+	return &RoleOutput{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		DataScope:   string(role.DataScope),
+		BuiltIn:     role.BuiltIn,
+	}
+}
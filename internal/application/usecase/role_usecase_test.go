@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// countingUserRepository menghitung pembacaan user berdasarkan ID
+type countingUserRepository struct {
+	repository.UserRepository
+	users map[string]*entity.User
+	reads int
+}
+
+func (r *countingUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	r.reads++
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *countingUserRepository) Update(ctx context.Context, user *entity.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func TestUserRoleFollowsAssignRole(t *testing.T) {
+	ctx := context.Background()
+	users := &countingUserRepository{users: map[string]*entity.User{
+		"user-1": {ID: "user-1", Role: entity.RoleAdmin},
+	}}
+	useCase := NewRoleUseCase(nil, users)
+
+	for i := 0; i < 2; i++ {
+		role, err := useCase.UserRole(ctx, "user-1")
+		if err != nil || role != string(entity.RoleAdmin) {
+			t.Fatalf("UserRole = %q, %v, want admin", role, err)
+		}
+	}
+	if users.reads != 1 {
+		t.Errorf("user read %d times, want 1 (cached)", users.reads)
+	}
+
+	if _, err := useCase.AssignRole(ctx, "user-1", string(entity.RoleEmployee)); err != nil {
+		t.Fatalf("AssignRole: %v", err)
+	}
+
+	// Demosi langsung berlaku meskipun JWT user masih berisi admin
+	role, err := useCase.UserRole(ctx, "user-1")
+	if err != nil || role != string(entity.RoleEmployee) {
+		t.Fatalf("UserRole after AssignRole = %q, %v, want employee", role, err)
+	}
+	allowed, err := useCase.HasPermission(ctx, role, entity.PermissionUserManage)
+	if err != nil || allowed {
+		t.Errorf("demoted user HasPermission(user.manage) = %v, %v, want false", allowed, err)
+	}
+
+	if _, err := useCase.UserRole(ctx, "missing"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("UserRole(missing) err = %v, want ErrUserNotFound", err)
+	}
+}
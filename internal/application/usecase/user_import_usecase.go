@@ -0,0 +1,435 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+	"github.com/okinn/service-presensi/pkg/jwt"
+	"github.com/okinn/service-presensi/pkg/spreadsheet"
+	"github.com/okinn/service-presensi/pkg/validator"
+)
+
+// MaxImportRows adalah jumlah maksimal baris data per file import
+const MaxImportRows = 5000
+
+var (
+	ErrImportEmpty         = errors.New("file import tidak berisi data")
+	ErrImportTooManyRows   = fmt.Errorf("file import maksimal %d baris data", MaxImportRows)
+	ErrInvitationDisabled  = errors.New("pengiriman undangan tidak aktif, konfigurasi SMTP belum diisi")
+	ErrInvalidInvitation   = errors.New("undangan tidak valid atau sudah dipakai")
+	ErrImportMissingColumn = errors.New("kolom email dan nama wajib ada di header")
+)
+
+// importColumns memetakan nama kolom di header (dan alias-nya) ke field import
+var importColumns = map[string]string{
+	"email":           "email",
+	"nama":            "nama",
+	"name":            "nama",
+	"role":            "role",
+	"password":        "password",
+	"employee_number": "employee_number",
+	"nik":             "employee_number",
+	"phone":           "phone",
+	"job_title":       "job_title",
+	"hire_date":       "hire_date",
+	"employment_type": "employment_type",
+}
+
+// importRow divalidasi dengan tag yang sama seperti request HTTP
+type importRow struct {
+	Email          string `json:"email" validate:"required,email"`
+	Nama           string `json:"nama" validate:"required,min=2,max=100"`
+	Role           string `json:"role"`
+	Password       string `json:"password"`
+	EmployeeNumber string `json:"employee_number" validate:"employee_number"`
+	Phone          string `json:"phone" validate:"phone"`
+	JobTitle       string `json:"job_title" validate:"max=100"`
+	HireDate       string `json:"hire_date"`
+	EmploymentType string `json:"employment_type" validate:"employment_type"`
+}
+
+// ImportUsersInput adalah input import user, baris pertama Rows adalah header
+type ImportUsersInput struct {
+	Rows   [][]string
+	DryRun bool // Hanya validasi, tidak ada yang disimpan
+	Upsert bool // Update user yang email-nya sudah terdaftar
+	Invite bool // Kirim undangan untuk user baru, kolom password diabaikan
+}
+
+// ImportRowResult adalah hasil per baris, Row adalah nomor baris di file (header = 1)
+type ImportRowResult struct {
+	Row     int      `json:"row"`
+	Email   string   `json:"email,omitempty"`
+	Action  string   `json:"action"` // created, updated, failed
+	Invited bool     `json:"invited,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// ImportUsersOutput adalah ringkasan hasil import
+type ImportUsersOutput struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Invited int               `json:"invited"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// InvitationConfig adalah konfigurasi undangan user hasil import
+type InvitationConfig struct {
+	AcceptURL string // Halaman frontend, token ditambahkan sebagai query parameter "token"
+	Duration  time.Duration
+}
+
+// UserImportUseCase adalah interface untuk import user massal dan undangan
+type UserImportUseCase interface {
+	Import(ctx context.Context, input ImportUsersInput) (*ImportUsersOutput, error)
+	AcceptInvitation(ctx context.Context, token, password string) (*UserOutput, error)
+}
+
+type userImportUseCase struct {
+	userRepo       repository.UserRepository
+	roles          RoleResolver
+	jwtManager     *jwt.JWTManager
+	passwordPolicy entity.PasswordPolicy
+	invitations    repository.InvitationSender
+	config         InvitationConfig
+}
+
+// NewUserImportUseCase membuat use case import, invitations boleh nil jika undangan tidak dipakai
+func NewUserImportUseCase(userRepo repository.UserRepository, roles RoleResolver, jwtManager *jwt.JWTManager, passwordPolicy entity.PasswordPolicy, invitations repository.InvitationSender, config InvitationConfig) UserImportUseCase {
+	return &userImportUseCase{
+		userRepo:       userRepo,
+		roles:          roles,
+		jwtManager:     jwtManager,
+		passwordPolicy: passwordPolicy,
+		invitations:    invitations,
+		config:         config,
+	}
+}
+
+func (uc *userImportUseCase) Import(ctx context.Context, input ImportUsersInput) (*ImportUsersOutput, error) {
+	if input.Invite && uc.invitations == nil {
+		return nil, ErrInvitationDisabled
+	}
+	if len(input.Rows) < 2 {
+		return nil, ErrImportEmpty
+	}
+	if len(input.Rows)-1 > MaxImportRows {
+		return nil, ErrImportTooManyRows
+	}
+
+	columns, err := importHeader(input.Rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ImportUsersOutput{DryRun: input.DryRun, Rows: []ImportRowResult{}}
+	seenEmails := make(map[string]int)
+	seenNumbers := make(map[string]int)
+
+	for i, cells := range input.Rows[1:] {
+		rowNumber := i + 2
+		row, ok := toImportRow(columns, cells)
+		if !ok {
+			continue // Baris kosong
+		}
+		output.Total++
+
+		result := uc.importRow(ctx, input, row, rowNumber, seenEmails, seenNumbers)
+		switch result.Action {
+		case "created":
+			output.Created++
+		case "updated":
+			output.Updated++
+		default:
+			output.Failed++
+		}
+		if result.Invited {
+			output.Invited++
+		}
+		output.Rows = append(output.Rows, result)
+	}
+
+	if output.Total == 0 {
+		return nil, ErrImportEmpty
+	}
+
+	return output, nil
+}
+
+func (uc *userImportUseCase) importRow(ctx context.Context, input ImportUsersInput, row importRow, rowNumber int, seenEmails, seenNumbers map[string]int) ImportRowResult {
+	result := ImportRowResult{Row: rowNumber, Email: row.Email, Action: "failed"}
+	fail := func(messages ...string) ImportRowResult {
+		result.Errors = append(result.Errors, messages...)
+		return result
+	}
+
+	if err := validator.Validate(row); err != nil {
+		var messages []string
+		if validationErrs, ok := err.(validator.ValidationErrors); ok {
+			for _, e := range validationErrs {
+				messages = append(messages, e.Field+": "+e.Message)
+			}
+		} else {
+			messages = append(messages, err.Error())
+		}
+		return fail(messages...)
+	}
+
+	if first, ok := seenEmails[row.Email]; ok {
+		return fail(fmt.Sprintf("email sama dengan baris %d", first))
+	}
+	seenEmails[row.Email] = rowNumber
+	if row.EmployeeNumber != "" {
+		if first, ok := seenNumbers[row.EmployeeNumber]; ok {
+			return fail(fmt.Sprintf("NIK sama dengan baris %d", first))
+		}
+		seenNumbers[row.EmployeeNumber] = rowNumber
+	}
+
+	var hireDate *time.Time
+	if row.HireDate != "" {
+		parsed, err := spreadsheet.ParseDate(row.HireDate)
+		if err != nil {
+			return fail("hire_date: " + err.Error())
+		}
+		hireDate = &parsed
+	}
+
+	role := entity.RoleEmployee
+	if row.Role != "" {
+		resolved, err := uc.roles.Resolve(ctx, row.Role)
+		if err != nil {
+			return fail("role: " + err.Error())
+		}
+		role = entity.UserRole(resolved.Name)
+	}
+
+	existing, _ := uc.userRepo.GetByEmail(ctx, row.Email)
+	if existing != nil && !input.Upsert {
+		return fail(ErrEmailAlreadyExists.Error())
+	}
+
+	if row.EmployeeNumber != "" {
+		owner, _ := uc.userRepo.GetByEmployeeNumber(ctx, row.EmployeeNumber)
+		if owner != nil && (existing == nil || owner.ID != existing.ID) {
+			return fail(ErrEmployeeNumberExists.Error())
+		}
+	}
+
+	if existing != nil {
+		if err := applyImportedProfile(existing, row, hireDate, true); err != nil {
+			return fail(err.Error())
+		}
+		if row.Role != "" {
+			existing.ChangeRole(role)
+		}
+		result.Action = "updated"
+		// Undangan yang belum diterima dikirim ulang
+		result.Invited = input.Invite && existing.InvitationPending
+		if input.DryRun {
+			return result
+		}
+
+		if err := uc.userRepo.Update(ctx, existing); err != nil {
+			result.Action = "failed"
+			result.Invited = false
+			return fail(err.Error())
+		}
+		if result.Invited {
+			if err := uc.sendInvitation(ctx, existing); err != nil {
+				result.Invited = false
+				result.Errors = append(result.Errors, "undangan gagal dikirim: "+err.Error())
+			}
+		}
+		return result
+	}
+
+	password := row.Password
+	if input.Invite {
+		password = randomPassword()
+	} else if password == "" {
+		return fail("password: field ini wajib diisi jika undangan tidak dikirim")
+	}
+
+	// NewUser hanya mengenal role bawaan, custom role diset setelahnya
+	user, err := entity.NewUser(row.Email, password, row.Nama, entity.RoleEmployee, uc.passwordPolicy)
+	if err != nil {
+		return fail(err.Error())
+	}
+	user.ChangeRole(role)
+	if err := applyImportedProfile(user, row, hireDate, false); err != nil {
+		return fail(err.Error())
+	}
+	if input.Invite {
+		user.MarkInvited()
+	}
+
+	result.Action = "created"
+	result.Invited = input.Invite
+	if input.DryRun {
+		return result
+	}
+
+	if err := uc.userRepo.Create(ctx, user); err != nil {
+		result.Action = "failed"
+		result.Invited = false
+		return fail(err.Error())
+	}
+
+	if input.Invite {
+		if err := uc.sendInvitation(ctx, user); err != nil {
+			// User tetap dibuat, undangan dikirim ulang dengan import upsert + invite
+			result.Invited = false
+			result.Errors = append(result.Errors, "undangan gagal dikirim: "+err.Error())
+		}
+	}
+
+	return result
+}
+
+func (uc *userImportUseCase) AcceptInvitation(ctx context.Context, token, password string) (*UserOutput, error) {
+	claims, err := uc.jwtManager.ValidateTokenType(token, jwt.InvitationToken)
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || !user.InvitationPending || user.Email != claims.Email {
+		return nil, ErrInvalidInvitation
+	}
+
+	if err := user.AcceptInvitation(password, uc.passwordPolicy); err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return toUserOutput(user), nil
+}
+
+func (uc *userImportUseCase) sendInvitation(ctx context.Context, user *entity.User) error {
+	token, err := uc.jwtManager.GenerateInvitationToken(user.ID, user.Email, uc.config.Duration)
+	if err != nil {
+		return err
+	}
+
+	acceptURL, err := url.Parse(uc.config.AcceptURL)
+	if err != nil {
+		return err
+	}
+	query := acceptURL.Query()
+	query.Set("token", token)
+	acceptURL.RawQuery = query.Encode()
+
+	return uc.invitations.SendInvitation(ctx, user.Email, user.Nama, acceptURL.String())
+}
+
+// importHeader memetakan index kolom ke field import
+func importHeader(header []string) (map[int]string, error) {
+	columns := make(map[int]string, len(header))
+	found := make(map[string]bool, len(header))
+
+	for i, name := range header {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if key == "" {
+			continue
+		}
+		field, ok := importColumns[key]
+		if !ok {
+			return nil, fmt.Errorf("kolom tidak dikenal: %s", name)
+		}
+		if found[field] {
+			return nil, fmt.Errorf("kolom ganda: %s", name)
+		}
+		found[field] = true
+		columns[i] = field
+	}
+
+	if !found["email"] || !found["nama"] {
+		return nil, ErrImportMissingColumn
+	}
+	return columns, nil
+}
+
+// toImportRow returns false for rows without any value
+func toImportRow(columns map[int]string, cells []string) (importRow, bool) {
+	var row importRow
+	empty := true
+
+	for i, cell := range cells {
+		field, ok := columns[i]
+		if !ok {
+			continue
+		}
+		value := strings.TrimSpace(cell)
+		if value != "" {
+			empty = false
+		}
+
+		switch field {
+		case "email":
+			row.Email = value
+		case "nama":
+			row.Nama = value
+		case "role":
+			row.Role = value
+		case "password":
+			row.Password = cell // Password tidak di-trim
+		case "employee_number":
+			row.EmployeeNumber = value
+		case "phone":
+			row.Phone = value
+		case "job_title":
+			row.JobTitle = value
+		case "hire_date":
+			row.HireDate = value
+		case "employment_type":
+			row.EmploymentType = value
+		}
+	}
+
+	return row, !empty
+}
+
+// applyImportedProfile mengisi profil dari baris import. Saat upsert, sel kosong tidak mengubah data yang ada.
+func applyImportedProfile(user *entity.User, row importRow, hireDate *time.Time, keepEmpty bool) error {
+	pick := func(value, current string) string {
+		if value == "" && keepEmpty {
+			return current
+		}
+		return value
+	}
+	if hireDate == nil && keepEmpty {
+		hireDate = user.HireDate
+	}
+
+	if err := user.UpdateProfile(row.Nama, pick(row.Phone, user.Phone), user.PhotoRef); err != nil {
+		return err
+	}
+	return user.UpdateEmployment(
+		pick(row.EmployeeNumber, user.EmployeeNumber),
+		pick(row.JobTitle, user.JobTitle),
+		hireDate,
+		entity.EmploymentType(pick(row.EmploymentType, string(user.EmploymentType))),
+	)
+}
+
+// randomPassword membuat password acak untuk user yang diundang, memenuhi semua aturan kompleksitas
+func randomPassword() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b) + "aA1!"
+}
@@ -46,6 +46,23 @@ type UserSummary struct {
 	Period       string            `json:"period"` // e.g., "2024-01" or "2024-01-01 to 2024-01-31"
 	Summary      AttendanceSummary `json:"summary"`
 	StatusDetail []StatusBreakdown `json:"status_detail"`
+
+	// Attendance rate against records and against expected working days. A user with a single
+	// "hadir" record has 100% against records, but not against a month of working days.
+	ExpectedWorkDays        int     `json:"expected_work_days"` // From the schedule, holidays and hire date, up to today
+	PercentageHadirRecords  float64 `json:"percentage_hadir_records"`
+	PercentageHadirSchedule float64 `json:"percentage_hadir_schedule"`
+}
+
+// CalculateScheduleRate sets the percentages from the summary and the expected working days
+func (s *UserSummary) CalculateScheduleRate(expectedWorkDays int) {
+	s.ExpectedWorkDays = expectedWorkDays
+	s.PercentageHadirRecords = s.Summary.PercentageHadir
+	s.PercentageHadirSchedule = 0
+	if expectedWorkDays > 0 {
+		present := s.Summary.TotalHadir + s.Summary.TotalTerlambat
+		s.PercentageHadirSchedule = float64(present) / float64(expectedWorkDays) * 100
+	}
 }
 
 // AnalyticsFilter for filtering analytics queries
@@ -53,6 +70,14 @@ type AnalyticsFilter struct {
 	UserID    string
 	StartDate time.Time
 	EndDate   time.Time
+
+	// DepartmentID, TeamID and ManagerID are resolved into UserIDs by the use case
+	DepartmentID string
+	TeamID       string
+	ManagerID    string
+
+	// UserIDs restricts results to these users; nil means no restriction, an empty slice matches nothing
+	UserIDs []string
 }
 
 // CalculatePercentage calculates and sets the attendance percentage
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import "time"
+
+// WorkingHoursStats summarizes the time between check-in and check-out of a user, a
+// department or a bucket of a period. Check-ins of the current day without a check-out are
+// counted as days only, earlier ones as missing check-outs.
+type WorkingHoursStats struct {
+	Key              string  `json:"key,omitempty"`  // User or department ID, empty for users without a department
+	Name             string  `json:"name,omitempty"` // User name, only for users
+	Days             int     `json:"days"`           // Days with a check-in
+	CompletedDays    int     `json:"completed_days"` // Days with a check-in and a check-out
+	MissingCheckOuts int     `json:"missing_check_outs"`
+	TotalHours       float64 `json:"total_hours"`
+	AverageHours     float64 `json:"average_hours"`  // Per completed day
+	OvertimeHours    float64 `json:"overtime_hours"` // Beyond the scheduled hours of each day
+	ShortHours       float64 `json:"short_hours"`    // Missing to the scheduled hours of each completed day
+}
+
+// Calculate sets the average from the totals
+func (s *WorkingHoursStats) Calculate() {
+	s.AverageHours = 0
+	if s.CompletedDays > 0 {
+		s.AverageHours = s.TotalHours / float64(s.CompletedDays)
+	}
+}
+
+// Add adds the totals of other to s, Calculate must be called afterwards
+func (s *WorkingHoursStats) Add(other WorkingHoursStats) {
+	s.Days += other.Days
+	s.CompletedDays += other.CompletedDays
+	s.MissingCheckOuts += other.MissingCheckOuts
+	s.TotalHours += other.TotalHours
+	s.OvertimeHours += other.OvertimeHours
+	s.ShortHours += other.ShortHours
+}
+
+// WorkingHoursBucket is one point of a working hours time series
+type WorkingHoursBucket struct {
+	Label string            `json:"label"`
+	Start time.Time         `json:"start"`
+	Stats WorkingHoursStats `json:"stats"`
+}
+
+// WorkingHoursReport is the working hours analytics of a period
+type WorkingHoursReport struct {
+	Period        AnalyticsPeriod      `json:"period"`
+	Interval      AnalyticsInterval    `json:"interval"`
+	ScheduleHours float64              `json:"schedule_hours"` // Per day
+	Overall       WorkingHoursStats    `json:"overall"`
+	Users         []WorkingHoursStats  `json:"users"`
+	Departments   []WorkingHoursStats  `json:"departments"`
+	Series        []WorkingHoursBucket `json:"series"`
+}
@@ -0,0 +1,311 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPeriod   = errors.New("periode analytics tidak valid")
+	ErrInvalidInterval = errors.New("interval analytics harus day, week atau month")
+)
+
+// AnalyticsPeriodKind is the kind of period an analytics summary covers
+type AnalyticsPeriodKind string
+
+const (
+	PeriodWeek    AnalyticsPeriodKind = "week"    // ISO week, "2024-W05"
+	PeriodMonth   AnalyticsPeriodKind = "month"   // "2024-01"
+	PeriodQuarter AnalyticsPeriodKind = "quarter" // "2024-Q1"
+	PeriodYear    AnalyticsPeriodKind = "year"    // "2024"
+	PeriodRange   AnalyticsPeriodKind = "range"   // arbitrary dates, both inclusive
+)
+
+// AnalyticsInterval is the size of the buckets in a period's time series
+type AnalyticsInterval string
+
+const (
+	IntervalDay   AnalyticsInterval = "day"
+	IntervalWeek  AnalyticsInterval = "week"
+	IntervalMonth AnalyticsInterval = "month"
+)
+
+// IsValid checks whether the interval is supported
+func (i AnalyticsInterval) IsValid() bool {
+	switch i {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return true
+	}
+	return false
+}
+
+// AnalyticsPeriod is a calendar period, Start and End are midnight in the analytics time zone
+type AnalyticsPeriod struct {
+	Kind  AnalyticsPeriodKind `json:"kind"`
+	Label string              `json:"label"`
+	Start time.Time           `json:"start"`
+	End   time.Time           `json:"end"` // Exclusive
+}
+
+// ParseAnalyticsPeriod parses a week, month, quarter or year label in loc
+func ParseAnalyticsPeriod(kind AnalyticsPeriodKind, value string, loc *time.Location) (AnalyticsPeriod, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var start time.Time
+	switch kind {
+	case PeriodWeek:
+		yearPart, weekPart, ok := strings.Cut(value, "-W")
+		year, yearErr := strconv.Atoi(yearPart)
+		week, weekErr := strconv.Atoi(weekPart)
+		if !ok || yearErr != nil || weekErr != nil || week < 1 || week > 53 {
+			return AnalyticsPeriod{}, ErrInvalidPeriod
+		}
+		start = isoWeekStart(year, week, loc)
+		if y, w := start.ISOWeek(); y != year || w != week {
+			return AnalyticsPeriod{}, ErrInvalidPeriod
+		}
+	case PeriodMonth:
+		parsed, err := time.ParseInLocation("2006-01", value, loc)
+		if err != nil {
+			return AnalyticsPeriod{}, ErrInvalidPeriod
+		}
+		start = parsed
+	case PeriodQuarter:
+		yearPart, quarterPart, ok := strings.Cut(value, "-Q")
+		year, yearErr := strconv.Atoi(yearPart)
+		quarter, quarterErr := strconv.Atoi(quarterPart)
+		if !ok || yearErr != nil || quarterErr != nil || quarter < 1 || quarter > 4 {
+			return AnalyticsPeriod{}, ErrInvalidPeriod
+		}
+		start = time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, loc)
+	case PeriodYear:
+		parsed, err := time.ParseInLocation("2006", value, loc)
+		if err != nil {
+			return AnalyticsPeriod{}, ErrInvalidPeriod
+		}
+		start = parsed
+	default:
+		return AnalyticsPeriod{}, ErrInvalidPeriod
+	}
+
+	return newCalendarPeriod(kind, start), nil
+}
+
+// AnalyticsPeriodAt returns the week, month, quarter or year containing t, in t's location
+func AnalyticsPeriodAt(kind AnalyticsPeriodKind, t time.Time) (AnalyticsPeriod, error) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch kind {
+	case PeriodWeek:
+		return newCalendarPeriod(kind, day.AddDate(0, 0, -((int(day.Weekday())+6)%7))), nil
+	case PeriodMonth:
+		return newCalendarPeriod(kind, day.AddDate(0, 0, 1-day.Day())), nil
+	case PeriodQuarter:
+		month := time.Month((int(t.Month())-1)/3*3 + 1)
+		return newCalendarPeriod(kind, time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())), nil
+	case PeriodYear:
+		return newCalendarPeriod(kind, time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())), nil
+	}
+	return AnalyticsPeriod{}, ErrInvalidPeriod
+}
+
+// NewRangePeriod creates a period from startDate to endDate (YYYY-MM-DD), both inclusive
+func NewRangePeriod(startDate, endDate string, loc *time.Location) (AnalyticsPeriod, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", startDate, loc)
+	if err != nil {
+		return AnalyticsPeriod{}, ErrInvalidPeriod
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, loc)
+	if err != nil || end.Before(start) {
+		return AnalyticsPeriod{}, ErrInvalidPeriod
+	}
+
+	return newRangePeriod(start, end.AddDate(0, 0, 1)), nil
+}
+
+func newCalendarPeriod(kind AnalyticsPeriodKind, start time.Time) AnalyticsPeriod {
+	period := AnalyticsPeriod{Kind: kind, Start: start}
+
+	switch kind {
+	case PeriodWeek:
+		year, week := start.ISOWeek()
+		period.Label = fmt.Sprintf("%d-W%02d", year, week)
+		period.End = start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		period.Label = start.Format("2006-01")
+		period.End = start.AddDate(0, 1, 0)
+	case PeriodQuarter:
+		period.Label = fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+		period.End = start.AddDate(0, 3, 0)
+	case PeriodYear:
+		period.Label = start.Format("2006")
+		period.End = start.AddDate(1, 0, 0)
+	}
+	return period
+}
+
+func newRangePeriod(start, end time.Time) AnalyticsPeriod {
+	return AnalyticsPeriod{
+		Kind:  PeriodRange,
+		Label: start.Format("2006-01-02") + ".." + end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Start: start,
+		End:   end,
+	}
+}
+
+// Previous returns the period of the same kind and length that ends where p starts
+func (p AnalyticsPeriod) Previous() AnalyticsPeriod {
+	switch p.Kind {
+	case PeriodWeek:
+		return newCalendarPeriod(p.Kind, p.Start.AddDate(0, 0, -7))
+	case PeriodMonth:
+		return newCalendarPeriod(p.Kind, p.Start.AddDate(0, -1, 0))
+	case PeriodQuarter:
+		return newCalendarPeriod(p.Kind, p.Start.AddDate(0, -3, 0))
+	case PeriodYear:
+		return newCalendarPeriod(p.Kind, p.Start.AddDate(-1, 0, 0))
+	default:
+		days := p.Days()
+		return newRangePeriod(p.Start.AddDate(0, 0, -days), p.Start)
+	}
+}
+
+// Days returns the number of calendar days in the period
+func (p AnalyticsPeriod) Days() int {
+	days := 0
+	for day := p.Start; day.Before(p.End); day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// DefaultInterval returns the bucket size used when none is requested
+func (p AnalyticsPeriod) DefaultInterval() AnalyticsInterval {
+	switch {
+	case p.Kind == PeriodYear || p.Days() > 120:
+		return IntervalMonth
+	case p.Kind == PeriodQuarter || p.Days() > 45:
+		return IntervalWeek
+	default:
+		return IntervalDay
+	}
+}
+
+// Buckets returns the empty buckets of the period's time series. The first and last bucket
+// are cut off at the period boundaries.
+func (p AnalyticsPeriod) Buckets(interval AnalyticsInterval) []PeriodBucket {
+	var buckets []PeriodBucket
+	for start := p.Start; start.Before(p.End); {
+		next := nextBucketStart(start, interval)
+		buckets = append(buckets, PeriodBucket{Label: BucketLabel(start, interval), Start: start})
+		start = next
+	}
+	return buckets
+}
+
+// BucketLabel returns the label of the bucket containing t, in t's location
+func BucketLabel(t time.Time, interval AnalyticsInterval) string {
+	switch interval {
+	case IntervalWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case IntervalMonth:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+func nextBucketStart(t time.Time, interval AnalyticsInterval) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch interval {
+	case IntervalWeek:
+		// Minggu ISO dimulai hari Senin
+		return day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day.AddDate(0, 0, 1)
+	}
+}
+
+// isoWeekStart returns the Monday of the ISO week; week 1 contains January 4th
+func isoWeekStart(year, week int, loc *time.Location) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	return jan4.AddDate(0, 0, -offset+(week-1)*7)
+}
+
+// PeriodBucket is one point of a period's time series
+type PeriodBucket struct {
+	Label   string            `json:"label"`
+	Start   time.Time         `json:"start"`
+	Summary AttendanceSummary `json:"summary"`
+}
+
+// PeriodSummary is the attendance summary of a period with its time series
+type PeriodSummary struct {
+	Period     AnalyticsPeriod   `json:"period"`
+	Interval   AnalyticsInterval `json:"interval"`
+	Summary    AttendanceSummary `json:"summary"`
+	Series     []PeriodBucket    `json:"series"`
+	Comparison *PeriodComparison `json:"comparison,omitempty"`
+}
+
+// PeriodComparison compares a period with the previous equivalent period
+type PeriodComparison struct {
+	Previous AnalyticsPeriod   `json:"previous"`
+	Summary  AttendanceSummary `json:"summary"`
+	Delta    SummaryDelta      `json:"delta"`
+}
+
+// SummaryDelta is the change from the previous period; PercentageHadir is in percentage points
+type SummaryDelta struct {
+	TotalRecords    int     `json:"total_records"`
+	TotalHadir      int     `json:"total_hadir"`
+	TotalTerlambat  int     `json:"total_terlambat"`
+	TotalIzin       int     `json:"total_izin"`
+	TotalSakit      int     `json:"total_sakit"`
+	TotalAlpha      int     `json:"total_alpha"`
+	PercentageHadir float64 `json:"percentage_hadir"`
+}
+
+// NewSummaryDelta returns current minus previous
+func NewSummaryDelta(current, previous AttendanceSummary) SummaryDelta {
+	return SummaryDelta{
+		TotalRecords:    current.TotalRecords - previous.TotalRecords,
+		TotalHadir:      current.TotalHadir - previous.TotalHadir,
+		TotalTerlambat:  current.TotalTerlambat - previous.TotalTerlambat,
+		TotalIzin:       current.TotalIzin - previous.TotalIzin,
+		TotalSakit:      current.TotalSakit - previous.TotalSakit,
+		TotalAlpha:      current.TotalAlpha - previous.TotalAlpha,
+		PercentageHadir: current.PercentageHadir - previous.PercentageHadir,
+	}
+}
+
+// Add adds the counts of other to s, the percentage must be recalculated afterwards
+func (s *AttendanceSummary) Add(other AttendanceSummary) {
+	s.TotalRecords += other.TotalRecords
+	s.TotalHadir += other.TotalHadir
+	s.TotalTerlambat += other.TotalTerlambat
+	s.TotalIzin += other.TotalIzin
+	s.TotalSakit += other.TotalSakit
+	s.TotalAlpha += other.TotalAlpha
+}
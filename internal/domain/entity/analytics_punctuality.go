@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import "errors"
+
+// ArrivalBucketMinutes is the width of the check-in time histogram buckets
+const ArrivalBucketMinutes = 15
+
+var ErrInvalidGroupBy = errors.New("group analytics harus user, team atau department")
+
+// AnalyticsGroupBy selects what per-group analytics are aggregated by
+type AnalyticsGroupBy string
+
+const (
+	GroupByUser       AnalyticsGroupBy = "user"
+	GroupByTeam       AnalyticsGroupBy = "team"
+	GroupByDepartment AnalyticsGroupBy = "department"
+)
+
+// IsValid checks whether the grouping is supported
+func (g AnalyticsGroupBy) IsValid() bool {
+	switch g {
+	case GroupByUser, GroupByTeam, GroupByDepartment:
+		return true
+	}
+	return false
+}
+
+// PunctualityRanking selects the order of a punctuality top list
+type PunctualityRanking string
+
+const (
+	RankMostLate     PunctualityRanking = "most_late"     // Most late check-ins, then most late minutes
+	RankMostPunctual PunctualityRanking = "most_punctual" // Highest on-time rate, then most check-ins
+)
+
+// PunctualityStats summarizes the check-ins of a user, team or department. A check-in is
+// late when it is after the schedule's start time on that day.
+type PunctualityStats struct {
+	Key                string  `json:"key"`            // User, team or department ID, empty for users without a team or department
+	Name               string  `json:"name,omitempty"` // User name, only for users
+	CheckIns           int     `json:"check_ins"`
+	OnTime             int     `json:"on_time"`
+	Late               int     `json:"late"`
+	TotalLateMinutes   float64 `json:"total_late_minutes"`
+	AverageLateMinutes float64 `json:"average_late_minutes"` // Per late check-in
+	OnTimeRate         float64 `json:"on_time_rate"`         // Percentage of check-ins
+}
+
+// Calculate sets the on-time count, average and rate from the counts
+func (s *PunctualityStats) Calculate() {
+	s.OnTime = s.CheckIns - s.Late
+	s.AverageLateMinutes = 0
+	if s.Late > 0 {
+		s.AverageLateMinutes = s.TotalLateMinutes / float64(s.Late)
+	}
+	s.OnTimeRate = 0
+	if s.CheckIns > 0 {
+		s.OnTimeRate = float64(s.OnTime) / float64(s.CheckIns) * 100
+	}
+}
+
+// Add adds the counts of other to s, Calculate must be called afterwards
+func (s *PunctualityStats) Add(other PunctualityStats) {
+	s.CheckIns += other.CheckIns
+	s.Late += other.Late
+	s.TotalLateMinutes += other.TotalLateMinutes
+}
+
+// ArrivalBucket counts the check-ins from Time until ArrivalBucketMinutes later
+type ArrivalBucket struct {
+	Time  string `json:"time"` // "HH:MM"
+	Count int    `json:"count"`
+}
+
+// OnTimeStreak is the number of consecutive on-time attendance days of a user. A late
+// check-in or an absence (alpha) ends a streak, leave (izin, sakit) does not.
+type OnTimeStreak struct {
+	UserID   string `json:"user_id"`
+	UserName string `json:"user_name"`
+	Current  int    `json:"current"` // Streak up to the user's last attendance day in the period
+	Longest  int    `json:"longest"`
+}
+
+// PunctualityReport is the punctuality analytics of a period
+type PunctualityReport struct {
+	Period       AnalyticsPeriod    `json:"period"`
+	WorkStart    string             `json:"work_start"` // "HH:MM"
+	Overall      PunctualityStats   `json:"overall"`
+	Users        []PunctualityStats `json:"users"`
+	Teams        []PunctualityStats `json:"teams"`
+	Histogram    []ArrivalBucket    `json:"histogram"`
+	Streaks      []OnTimeStreak     `json:"streaks"`
+	MostLate     []PunctualityStats `json:"most_late"`
+	MostPunctual []PunctualityStats `json:"most_punctual"`
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidAPIKeyName  = errors.New("nama API key tidak boleh kosong")
+	ErrInvalidAPIKeyScope = errors.New("scope API key tidak valid")
+	ErrInvalidAPIKey      = errors.New("API key tidak valid")
+	ErrAPIKeyExpired      = errors.New("API key sudah expired")
+	ErrAPIKeyRevoked      = errors.New("API key sudah dicabut")
+	ErrInvalidExpiry      = errors.New("waktu expired harus di masa depan")
+)
+
+// APIKeyScope represents a permission granted to an API key
+type APIKeyScope string
+
+const (
+	ScopePresensiRead  APIKeyScope = "presensi:read"
+	ScopeAnalyticsRead APIKeyScope = "analytics:read"
+)
+
+// apiKeyPrefix identifies keys issued by this service, e.g. in secret scanners
+const apiKeyPrefix = "psk"
+
+func (s APIKeyScope) IsValid() bool {
+	switch s {
+	case ScopePresensiRead, ScopeAnalyticsRead:
+		return true
+	}
+	return false
+}
+
+// APIKey represents a credential for machine-to-machine integrations.
+// Only the SHA-256 hash of the key is stored, the plaintext key is shown once on creation.
+type APIKey struct {
+	ID         string
+	Name       string
+	Prefix     string // Public lookup part of the key
+	KeyHash    string
+	Scopes     []APIKeyScope
+	CreatedBy  string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// NewAPIKey creates a new API key and returns it together with the plaintext key
+func NewAPIKey(name string, scopes []APIKeyScope, createdBy string, expiresAt *time.Time) (*APIKey, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", ErrInvalidAPIKeyName
+	}
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidAPIKeyScope
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return nil, "", ErrInvalidAPIKeyScope
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidExpiry
+	}
+
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return nil, "", err
+	}
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, "", err
+	}
+
+	prefix := hex.EncodeToString(prefixBytes)
+	rawKey := apiKeyPrefix + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	return &APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(rawKey),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, rawKey, nil
+}
+
+// ParseAPIKeyPrefix extracts the lookup prefix from a plaintext key
+func ParseAPIKeyPrefix(rawKey string) (string, error) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", ErrInvalidAPIKey
+	}
+	return parts[1], nil
+}
+
+// Verify checks the plaintext key against the stored hash and the key's validity
+func (k *APIKey) Verify(rawKey string, now time.Time) error {
+	if subtle.ConstantTimeCompare([]byte(k.KeyHash), []byte(hashAPIKey(rawKey))) != 1 {
+		return ErrInvalidAPIKey
+	}
+	if k.RevokedAt != nil {
+		return ErrAPIKeyRevoked
+	}
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return ErrAPIKeyExpired
+	}
+	return nil
+}
+
+// HasScope returns true if the key was granted the given scope
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoke revokes the key, revoked keys can no longer be used
+func (k *APIKey) Revoke() error {
+	if k.RevokedAt != nil {
+		return ErrAPIKeyRevoked
+	}
+	now := time.Now()
+	k.RevokedAt = &now
+	return nil
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []APIKeyScope
+		expiresAt *time.Time
+		want      error
+	}{
+		{"valid", "payroll", []APIKeyScope{ScopePresensiRead}, nil, nil},
+		{"valid with expiry", "payroll", []APIKeyScope{ScopePresensiRead, ScopeAnalyticsRead}, &future, nil},
+		{"blank name", "  ", []APIKeyScope{ScopePresensiRead}, nil, ErrInvalidAPIKeyName},
+		{"no scopes", "payroll", nil, nil, ErrInvalidAPIKeyScope},
+		{"unknown scope", "payroll", []APIKeyScope{ScopePresensiRead, "presensi:write"}, nil, ErrInvalidAPIKeyScope},
+		{"expiry in the past", "payroll", []APIKeyScope{ScopePresensiRead}, &past, ErrInvalidExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKey, rawKey, err := NewAPIKey(tt.keyName, tt.scopes, "admin-1", tt.expiresAt)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("NewAPIKey = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if apiKey != nil || rawKey != "" {
+					t.Errorf("NewAPIKey returned a key with error %v", err)
+				}
+				return
+			}
+
+			// Hanya hash yang disimpan, key plaintext tidak muncul di entity
+			if apiKey.KeyHash == "" || apiKey.KeyHash == rawKey {
+				t.Errorf("KeyHash = %q, want the SHA-256 hash of the key", apiKey.KeyHash)
+			}
+			if apiKey.KeyHash != hashAPIKey(rawKey) {
+				t.Errorf("KeyHash does not match the returned key")
+			}
+			if !strings.HasPrefix(rawKey, "psk_"+apiKey.Prefix+"_") {
+				t.Errorf("key %q does not start with psk_%s_", rawKey, apiKey.Prefix)
+			}
+		})
+	}
+}
+
+func TestNewAPIKeyIsRandom(t *testing.T) {
+	first, firstKey, _ := NewAPIKey("a", []APIKeyScope{ScopePresensiRead}, "admin-1", nil)
+	second, secondKey, _ := NewAPIKey("a", []APIKeyScope{ScopePresensiRead}, "admin-1", nil)
+	if firstKey == secondKey || first.Prefix == second.Prefix || first.KeyHash == second.KeyHash {
+		t.Error("two keys share their key, prefix or hash")
+	}
+}
+
+func TestParseAPIKeyPrefix(t *testing.T) {
+	tests := []struct {
+		rawKey string
+		want   string
+		ok     bool
+	}{
+		{"psk_0a1b2c3d_c2VjcmV0", "0a1b2c3d", true},
+		{"psk_0a1b2c3d_sec_ret", "0a1b2c3d", true}, // base64url boleh berisi underscore
+		{"psk__c2VjcmV0", "", false},
+		{"psk_0a1b2c3d_", "", false},
+		{"psk_0a1b2c3d", "", false},
+		{"xyz_0a1b2c3d_c2VjcmV0", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		prefix, err := ParseAPIKeyPrefix(tt.rawKey)
+		if tt.ok != (err == nil) || prefix != tt.want {
+			t.Errorf("ParseAPIKeyPrefix(%q) = %q, %v, want %q", tt.rawKey, prefix, err, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("ParseAPIKeyPrefix(%q) err = %v, want %v", tt.rawKey, err, ErrInvalidAPIKey)
+		}
+	}
+}
+
+func TestAPIKeyVerify(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	apiKey, rawKey, err := NewAPIKey("payroll", []APIKeyScope{ScopePresensiRead}, "admin-1", &expiresAt)
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+
+	if err := apiKey.Verify(rawKey, now); err != nil {
+		t.Errorf("Verify(valid) = %v", err)
+	}
+	if err := apiKey.Verify(rawKey+"x", now); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Verify(wrong key) = %v, want %v", err, ErrInvalidAPIKey)
+	}
+	if err := apiKey.Verify(apiKey.KeyHash, now); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Verify(stored hash) = %v, want %v", err, ErrInvalidAPIKey)
+	}
+	if err := apiKey.Verify(rawKey, expiresAt); !errors.Is(err, ErrAPIKeyExpired) {
+		t.Errorf("Verify at expiry = %v, want %v", err, ErrAPIKeyExpired)
+	}
+
+	if err := apiKey.Revoke(); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := apiKey.Verify(rawKey, now); !errors.Is(err, ErrAPIKeyRevoked) {
+		t.Errorf("Verify(revoked) = %v, want %v", err, ErrAPIKeyRevoked)
+	}
+	// Key yang salah tetap ditolak sebagai tidak valid, status revoked tidak dibocorkan
+	if err := apiKey.Verify(rawKey+"x", now); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Verify(wrong key, revoked) = %v, want %v", err, ErrInvalidAPIKey)
+	}
+	if err := apiKey.Revoke(); !errors.Is(err, ErrAPIKeyRevoked) {
+		t.Errorf("second Revoke = %v, want %v", err, ErrAPIKeyRevoked)
+	}
+}
+
+func TestAPIKeyHasScope(t *testing.T) {
+	apiKey := &APIKey{Scopes: []APIKeyScope{ScopePresensiRead}}
+	if !apiKey.HasScope(ScopePresensiRead) {
+		t.Error("HasScope(presensi:read) = false")
+	}
+	if apiKey.HasScope(ScopeAnalyticsRead) {
+		t.Error("HasScope(analytics:read) = true for a key without it")
+	}
+	if (&APIKey{}).HasScope(ScopePresensiRead) {
+		t.Error("HasScope on a key without scopes = true")
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type auditActorKey struct{}
+
+// AuditActor describes who performs the current request. It is created by the audit middleware,
+// filled in by the auth middleware and read by the audited repositories.
+type AuditActor struct {
+	UserID    string
+	UserName  string
+	UserRole  string
+	IPAddress string
+	UserAgent string
+	RequestID string
+
+	recorded atomic.Bool
+}
+
+// ContextWithAuditActor returns a copy of ctx carrying actor
+func ContextWithAuditActor(ctx context.Context, actor *AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor of the request, or nil outside an audited request
+func AuditActorFromContext(ctx context.Context) *AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(*AuditActor)
+	return actor
+}
+
+// MarkRecorded notes that an entity-level audit entry was written for the request
+func (a *AuditActor) MarkRecorded() {
+	a.recorded.Store(true)
+}
+
+// Recorded reports whether an entity-level audit entry was written for the request
+func (a *AuditActor) Recorded() bool {
+	return a.recorded.Load()
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"time"
+)
+
+// AuditCheckpoint is a signed snapshot of the head of the audit hash chain. Checkpoints make
+// deletion of the newest entries, or of the whole chain, detectable.
+type AuditCheckpoint struct {
+	ID        string    `json:"id"`
+	Sequence  int64     `json:"sequence"`
+	Hash      string    `json:"hash"`
+	Signature string    `json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditChainIssueType describes what is wrong at a point of the chain
+type AuditChainIssueType string
+
+const (
+	AuditChainGap                AuditChainIssueType = "gap"                 // Entries are missing
+	AuditChainHashMismatch       AuditChainIssueType = "hash_mismatch"       // Entry content was modified
+	AuditChainLinkMismatch       AuditChainIssueType = "link_mismatch"       // Entry does not link to the previous entry
+	AuditChainCheckpointMismatch AuditChainIssueType = "checkpoint_mismatch" // Entry differs from the signed checkpoint
+	AuditChainInvalidSignature   AuditChainIssueType = "invalid_signature"   // Checkpoint was forged or modified
+)
+
+// AuditChainIssue is a single problem found while verifying the chain
+type AuditChainIssue struct {
+	Sequence int64               `json:"sequence"`
+	Type     AuditChainIssueType `json:"type"`
+	Message  string              `json:"message"`
+}
+
+// AuditChainReport is the result of walking the audit hash chain
+type AuditChainReport struct {
+	Valid              bool              `json:"valid"`
+	FirstSequence      int64             `json:"first_sequence"`
+	LastSequence       int64             `json:"last_sequence"`
+	EntriesChecked     int64             `json:"entries_checked"`
+	CheckpointsChecked int               `json:"checkpoints_checked"`
+	IssueCount         int               `json:"issue_count"`
+	Issues             []AuditChainIssue `json:"issues"`
+	VerifiedAt         time.Time         `json:"verified_at"`
+}
+
+// maxReportedIssues bounds the report size when a large part of the chain is broken
+const maxReportedIssues = 100
+
+// AddIssue records an issue and marks the chain as invalid
+func (r *AuditChainReport) AddIssue(sequence int64, issueType AuditChainIssueType, message string) {
+	r.Valid = false
+	r.IssueCount++
+	if len(r.Issues) < maxReportedIssues {
+		r.Issues = append(r.Issues, AuditChainIssue{Sequence: sequence, Type: issueType, Message: message})
+	}
+}
@@ -9,6 +9,9 @@
 package entity
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"time"
 )
 
@@ -16,11 +19,18 @@ import (
 type AuditAction string
 
 const (
-	AuditActionCreate AuditAction = "create"
-	AuditActionUpdate AuditAction = "update"
-	AuditActionDelete AuditAction = "delete"
-	AuditActionLogin  AuditAction = "login"
-	AuditActionLogout AuditAction = "logout"
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionLogin   AuditAction = "login"
+	AuditActionLogout  AuditAction = "logout"
+	AuditActionLockout AuditAction = "lockout"
+	AuditActionUnlock  AuditAction = "unlock"
+
+	// Authentication events, recorded with entity type "auth"
+	AuditActionLoginFailed  AuditAction = "login_failed"
+	AuditActionMFAChallenge AuditAction = "mfa_challenge"
+	AuditActionTokenRefresh AuditAction = "token_refresh"
 )
 
 // AuditLog represents an audit trail entry for tracking all data changes
@@ -42,6 +52,16 @@ type AuditLog struct {
 	ErrorMsg    string      `json:"error_msg"`    // Error message if failed
 	Duration    int64       `json:"duration_ms"`  // Request duration in ms
 	CreatedAt   time.Time   `json:"created_at"`
+
+	// Hash chain: every entry commits to the hash of the entry before it
+	Sequence int64  `json:"sequence"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+
+	// Retention: archived entries keep only their place in the hash chain, the full
+	// entry is stored in the named archive
+	Archived    bool   `json:"archived,omitempty"`
+	ArchiveName string `json:"archive_name,omitempty"`
 }
 
 // NewAuditLog creates a new audit log entry
@@ -90,3 +110,62 @@ func (a *AuditLog) SetError(errMsg string) {
 func (a *AuditLog) IsSuccessful() bool {
 	return a.ErrorMsg == "" && a.StatusCode >= 200 && a.StatusCode < 300
 }
+
+// Chain links the entry to prev, the last entry of the chain (nil for the first entry),
+// and computes its hash. CreatedAt is truncated to the millisecond precision of the store.
+func (a *AuditLog) Chain(prev *AuditLog) {
+	a.Sequence = 1
+	a.PrevHash = ""
+	if prev != nil {
+		a.Sequence = prev.Sequence + 1
+		a.PrevHash = prev.Hash
+	}
+	a.CreatedAt = a.CreatedAt.UTC().Truncate(time.Millisecond)
+	a.Hash = a.ComputeHash()
+}
+
+// ComputeHash returns the SHA-256 hash of the entry content, sequence and previous hash
+func (a *AuditLog) ComputeHash() string {
+	content, _ := json.Marshal(struct {
+		Sequence   int64  `json:"sequence"`
+		PrevHash   string `json:"prev_hash"`
+		EntityType string `json:"entity_type"`
+		EntityID   string `json:"entity_id"`
+		Action     string `json:"action"`
+		OldValue   string `json:"old_value"`
+		NewValue   string `json:"new_value"`
+		Changes    string `json:"changes"`
+		UserID     string `json:"user_id"`
+		UserName   string `json:"user_name"`
+		UserRole   string `json:"user_role"`
+		IPAddress  string `json:"ip_address"`
+		UserAgent  string `json:"user_agent"`
+		RequestID  string `json:"request_id"`
+		StatusCode int    `json:"status_code"`
+		ErrorMsg   string `json:"error_msg"`
+		Duration   int64  `json:"duration_ms"`
+		CreatedAt  string `json:"created_at"`
+	}{
+		Sequence:   a.Sequence,
+		PrevHash:   a.PrevHash,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Action:     string(a.Action),
+		OldValue:   a.OldValue,
+		NewValue:   a.NewValue,
+		Changes:    a.Changes,
+		UserID:     a.UserID,
+		UserName:   a.UserName,
+		UserRole:   a.UserRole,
+		IPAddress:  a.IPAddress,
+		UserAgent:  a.UserAgent,
+		RequestID:  a.RequestID,
+		StatusCode: a.StatusCode,
+		ErrorMsg:   a.ErrorMsg,
+		Duration:   a.Duration,
+		CreatedAt:  a.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditRetentionPolicy defines how long audit entries are kept before they are archived.
+// A zero duration keeps entries forever.
+type AuditRetentionPolicy struct {
+	Default      time.Duration
+	ByEntityType map[string]time.Duration // Overrides the default for specific entity types
+}
+
+// For returns the retention period of an entity type
+func (p AuditRetentionPolicy) For(entityType string) time.Duration {
+	if d, ok := p.ByEntityType[entityType]; ok {
+		return d
+	}
+	return p.Default
+}
+
+// AuditArchiveResult summarizes a retention run
+type AuditArchiveResult struct {
+	Archives []string `json:"archives"` // Archive files written
+	Archived int64    `json:"archived"` // Entries moved out of the database
+}
+
+// AuditImportResult summarizes the re-import of an archive
+type AuditImportResult struct {
+	Restored int `json:"restored"` // Entries whose content was restored
+	Skipped  int `json:"skipped"`  // Entries that are not archived in the database
+	Rejected int `json:"rejected"` // Entries whose content does not match their chain hash
+}
+
+// ParseAuditRetentionPolicy builds a policy from a default in days and "type=days,type=days" overrides
+func ParseAuditRetentionPolicy(defaultDays int, overrides string) (AuditRetentionPolicy, error) {
+	policy := AuditRetentionPolicy{
+		Default:      days(defaultDays),
+		ByEntityType: make(map[string]time.Duration),
+	}
+
+	for _, pair := range strings.Split(overrides, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		entityType, value, ok := strings.Cut(pair, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || strings.TrimSpace(entityType) == "" || err != nil || n < 0 {
+			return policy, fmt.Errorf("retention audit tidak valid: %q", pair)
+		}
+		policy.ByEntityType[strings.TrimSpace(entityType)] = days(n)
+	}
+
+	return policy, nil
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
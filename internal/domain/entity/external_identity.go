@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrEmailNotVerified = errors.New("email dari identity provider belum terverifikasi")
+)
+
+// AuthProviderLocal is the provider of users that log in with a password
+const AuthProviderLocal = "local"
+
+// ExternalIdentity represents a user identity asserted by an ID token from an identity provider
+type ExternalIdentity struct {
+	Provider      string // Issuer URL
+	Subject       string // "sub" claim, stable per provider
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+	Nonce         string
+}
+
+// NewSSOUser creates a user provisioned just-in-time from an identity provider.
+// SSO users have no local password and can only log in through the provider.
+func NewSSOUser(identity *ExternalIdentity, role UserRole) (*User, error) {
+	if identity.Email == "" {
+		return nil, ErrInvalidEmail
+	}
+	if !identity.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	nama := identity.Name
+	if nama == "" {
+		nama = identity.Email
+	}
+	if !role.IsValid() {
+		role = RoleEmployee
+	}
+
+	now := time.Now()
+	return &User{
+		Email:        identity.Email,
+		Nama:         nama,
+		Role:         role,
+		IsActive:     true,
+		AuthProvider: identity.Provider,
+		ExternalID:   identity.Subject,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// LinkExternalIdentity links an existing user to an identity provider subject
+func (u *User) LinkExternalIdentity(identity *ExternalIdentity) {
+	u.AuthProvider = identity.Provider
+	u.ExternalID = identity.Subject
+	u.UpdatedAt = time.Now()
+}
+
+// ChangeRole changes the role of the user
+func (u *User) ChangeRole(role UserRole) {
+	u.Role = role
+	u.UpdatedAt = time.Now()
+}
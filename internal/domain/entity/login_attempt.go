@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAccountLocked = errors.New("akun dikunci sementara karena terlalu banyak percobaan login gagal")
+)
+
+// LockoutPolicy controls when an account gets locked after failed logins
+type LockoutPolicy struct {
+	MaxAttempts  int           // Failed attempts allowed before the first lock
+	BaseDuration time.Duration // Duration of the first lock, doubled on every further failure
+	MaxDuration  time.Duration // Upper bound of a single lock
+	ResetAfter   time.Duration // Failures older than this no longer count
+}
+
+// LoginAttempt tracks failed logins for one email address, whether or not the account exists
+type LoginAttempt struct {
+	Email        string
+	FailedCount  int
+	LastFailedAt time.Time
+	LockedUntil  time.Time
+}
+
+// IsLocked returns true if the account is locked at the given time
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// LockDuration returns how long the account must be locked after the current failure,
+// zero if the failure count is still below the threshold
+func (p LockoutPolicy) LockDuration(failedCount int) time.Duration {
+	if p.MaxAttempts <= 0 || failedCount < p.MaxAttempts {
+		return 0
+	}
+
+	duration := p.BaseDuration
+	for i := p.MaxAttempts; i < failedCount; i++ {
+		duration *= 2
+		if p.MaxDuration > 0 && duration >= p.MaxDuration {
+			return p.MaxDuration
+		}
+	}
+
+	if p.MaxDuration > 0 && duration > p.MaxDuration {
+		return p.MaxDuration
+	}
+	return duration
+}
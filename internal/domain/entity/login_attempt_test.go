@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutPolicyLockDuration(t *testing.T) {
+	policy := LockoutPolicy{MaxAttempts: 5, BaseDuration: time.Minute, MaxDuration: 10 * time.Minute}
+
+	tests := []struct {
+		name        string
+		policy      LockoutPolicy
+		failedCount int
+		want        time.Duration
+	}{
+		{"no failures", policy, 0, 0},
+		{"below threshold", policy, 4, 0},
+		{"first lock", policy, 5, time.Minute},
+		{"doubled", policy, 6, 2 * time.Minute},
+		{"doubled twice", policy, 7, 4 * time.Minute},
+		{"doubled three times", policy, 8, 8 * time.Minute},
+		{"capped", policy, 9, 10 * time.Minute},
+		{"far beyond the cap", policy, 500, 10 * time.Minute},
+		{"base above the cap", LockoutPolicy{MaxAttempts: 1, BaseDuration: time.Hour, MaxDuration: time.Minute}, 1, time.Minute},
+		{"no cap", LockoutPolicy{MaxAttempts: 1, BaseDuration: time.Minute}, 4, 8 * time.Minute},
+		{"lockout disabled", LockoutPolicy{BaseDuration: time.Minute}, 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.LockDuration(tt.failedCount); got != tt.want {
+				t.Errorf("LockDuration(%d) = %v, want %v", tt.failedCount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginAttemptIsLocked(t *testing.T) {
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	attempt := &LoginAttempt{LockedUntil: now.Add(time.Minute)}
+
+	if !attempt.IsLocked(now) {
+		t.Error("IsLocked before LockedUntil = false")
+	}
+	if attempt.IsLocked(now.Add(time.Minute)) {
+		t.Error("IsLocked at LockedUntil = true")
+	}
+	if (&LoginAttempt{}).IsLocked(now) {
+		t.Error("IsLocked without a lock = true")
+	}
+}
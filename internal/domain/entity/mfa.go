@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("MFA sudah aktif")
+	ErrMFANotEnabled     = errors.New("MFA belum aktif")
+	ErrMFANotEnrolled    = errors.New("MFA belum didaftarkan")
+	ErrInvalidMFACode    = errors.New("kode MFA tidak valid")
+)
+
+// RecoveryCodeCount is the number of recovery codes issued on activation
+const RecoveryCodeCount = 10
+
+// SetPendingMFASecret stores a new TOTP secret that becomes active after the first valid code
+func (u *User) SetPendingMFASecret(secret string) error {
+	if u.MFAEnabled {
+		return ErrMFAAlreadyEnabled
+	}
+	u.MFASecret = secret
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// HasPendingMFA returns true if a secret was generated but not yet confirmed
+func (u *User) HasPendingMFA() bool {
+	return !u.MFAEnabled && u.MFASecret != ""
+}
+
+// ActivateMFA enables MFA and replaces the recovery codes, returning the plaintext codes
+func (u *User) ActivateMFA(step int64) ([]string, error) {
+	if u.MFASecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	codes, err := u.RegenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	u.MFAEnabled = true
+	u.MFALastUsedStep = step
+	return codes, nil
+}
+
+// DisableMFA removes the TOTP secret and all recovery codes
+func (u *User) DisableMFA() {
+	u.MFAEnabled = false
+	u.MFASecret = ""
+	u.MFARecoveryCodes = nil
+	u.MFALastUsedStep = 0
+	u.UpdatedAt = time.Now()
+}
+
+// AcceptTOTPStep records a used time step, rejecting codes that were already used
+func (u *User) AcceptTOTPStep(step int64) error {
+	if step <= u.MFALastUsedStep {
+		return ErrInvalidMFACode
+	}
+	u.MFALastUsedStep = step
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, only their hashes are stored
+func (u *User) RegenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+
+	for i := range codes {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+		codes[i] = code[:5] + "-" + code[5:10]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	u.MFARecoveryCodes = hashes
+	u.UpdatedAt = time.Now()
+	return codes, nil
+}
+
+// UseRecoveryCode consumes a recovery code, each code is valid only once
+func (u *User) UseRecoveryCode(code string) bool {
+	hash := hashRecoveryCode(code)
+	for i, stored := range u.MFARecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			u.MFARecoveryCodes = append(u.MFARecoveryCodes[:i], u.MFARecoveryCodes[i+1:]...)
+			u.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidDepartmentName  = errors.New("nama department tidak boleh kosong")
+	ErrInvalidTeamName        = errors.New("nama team tidak boleh kosong")
+	ErrTeamDepartmentRequired = errors.New("team harus berada di dalam department")
+	ErrTeamDepartmentMismatch = errors.New("team tidak berada di department yang dipilih")
+	ErrDepartmentCycle        = errors.New("parent department membentuk siklus")
+	ErrSelfManager            = errors.New("user tidak dapat menjadi manager dirinya sendiri")
+	ErrManagerCycle           = errors.New("manager membentuk siklus pada hierarki pelaporan")
+)
+
+// Department is an organizational unit, departments can be nested through ParentID
+type Department struct {
+	ID        string
+	Name      string
+	ParentID  string
+	ManagerID string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Team is a group of users inside a department
+type Team struct {
+	ID           string
+	Name         string
+	DepartmentID string
+	ManagerID    string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func NewDepartment(name, parentID, managerID string) (*Department, error) {
+	now := time.Now()
+	d := &Department{CreatedAt: now}
+	if err := d.Update(name, parentID, managerID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Department) Update(name, parentID, managerID string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidDepartmentName
+	}
+	if d.ID != "" && parentID == d.ID {
+		return ErrDepartmentCycle
+	}
+
+	d.Name = name
+	d.ParentID = parentID
+	d.ManagerID = managerID
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+func NewTeam(name, departmentID, managerID string) (*Team, error) {
+	now := time.Now()
+	t := &Team{CreatedAt: now}
+	if err := t.Update(name, departmentID, managerID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Team) Update(name, departmentID, managerID string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidTeamName
+	}
+	if departmentID == "" {
+		return ErrTeamDepartmentRequired
+	}
+
+	t.Name = name
+	t.DepartmentID = departmentID
+	t.ManagerID = managerID
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// AssignOrganization sets the department, team and direct manager of the user
+func (u *User) AssignOrganization(departmentID, teamID, managerID string) error {
+	if managerID != "" && managerID == u.ID {
+		return ErrSelfManager
+	}
+
+	u.DepartmentID = departmentID
+	u.TeamID = teamID
+	u.ManagerID = managerID
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// OrgTreeMember is a user shown in the organization tree
+type OrgTreeMember struct {
+	ID    string `json:"id"`
+	Nama  string `json:"nama"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// OrgTreeTeam is a team node in the organization tree
+type OrgTreeTeam struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	ManagerID string          `json:"manager_id,omitempty"`
+	Members   []OrgTreeMember `json:"members"`
+}
+
+// OrgTreeDepartment is a department node in the organization tree
+type OrgTreeDepartment struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	ManagerID   string              `json:"manager_id,omitempty"`
+	Teams       []OrgTreeTeam       `json:"teams"`
+	Members     []OrgTreeMember     `json:"members"` // Members of the department that are not in a team
+	Departments []OrgTreeDepartment `json:"departments"`
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPasswordTooShort      = errors.New("password terlalu pendek")
+	ErrPasswordMissingUpper  = errors.New("password harus mengandung huruf besar")
+	ErrPasswordMissingLower  = errors.New("password harus mengandung huruf kecil")
+	ErrPasswordMissingDigit  = errors.New("password harus mengandung angka")
+	ErrPasswordMissingSymbol = errors.New("password harus mengandung simbol")
+	ErrPasswordPersonalInfo  = errors.New("password tidak boleh mengandung email atau nama")
+	ErrPasswordReused        = errors.New("password sudah pernah digunakan sebelumnya")
+	ErrPasswordBreached      = errors.New("password termasuk dalam daftar password yang bocor")
+)
+
+// minPersonalInfoLength is the shortest email/name fragment checked against the password,
+// shorter fragments match too many passwords by accident
+const minPersonalInfoLength = 3
+
+// BreachedPasswordChecker reports whether a password appears in a list of known breached passwords
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// PasswordPolicy describes the rules a password must satisfy
+type PasswordPolicy struct {
+	MinLength            int
+	RequireUpper         bool
+	RequireLower         bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	DisallowPersonalInfo bool
+	HistorySize          int // Number of previous password hashes that may not be reused
+	BreachedChecker      BreachedPasswordChecker
+}
+
+// Validate checks the password against the policy, email and nama are used for the personal info rule
+func (p PasswordPolicy) Validate(password, email, nama string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("%w: minimal %d karakter", ErrPasswordTooShort, p.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		return ErrPasswordMissingUpper
+	}
+	if p.RequireLower && !hasLower {
+		return ErrPasswordMissingLower
+	}
+	if p.RequireDigit && !hasDigit {
+		return ErrPasswordMissingDigit
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return ErrPasswordMissingSymbol
+	}
+
+	if p.DisallowPersonalInfo && containsPersonalInfo(password, email, nama) {
+		return ErrPasswordPersonalInfo
+	}
+
+	if p.BreachedChecker != nil {
+		breached, err := p.BreachedChecker.IsBreached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			return ErrPasswordBreached
+		}
+	}
+
+	return nil
+}
+
+// IsReused checks the password against the current hash and the last HistorySize hashes
+func (p PasswordPolicy) IsReused(password, currentHash string, history []string) bool {
+	if currentHash != "" && bcrypt.CompareHashAndPassword([]byte(currentHash), []byte(password)) == nil {
+		return true
+	}
+
+	for i, hash := range history {
+		if i >= p.HistorySize {
+			break
+		}
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsPersonalInfo(password, email, nama string) bool {
+	lowered := strings.ToLower(password)
+
+	fragments := strings.FieldsFunc(strings.ToLower(nama), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if local, _, found := strings.Cut(strings.ToLower(email), "@"); found {
+		fragments = append(fragments, local)
+	}
+
+	for _, fragment := range fragments {
+		if len([]rune(fragment)) >= minPersonalInfoLength && strings.Contains(lowered, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
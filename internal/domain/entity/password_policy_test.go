@@ -0,0 +1,165 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeBreachedChecker menganggap password di breached bocor dan mengembalikan err jika diisi
+type fakeBreachedChecker struct {
+	breached map[string]bool
+	err      error
+	checked  []string
+}
+
+func (c *fakeBreachedChecker) IsBreached(password string) (bool, error) {
+	c.checked = append(c.checked, password)
+	if c.err != nil {
+		return false, c.err
+	}
+	return c.breached[password], nil
+}
+
+func strictPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:            10,
+		RequireUpper:         true,
+		RequireLower:         true,
+		RequireDigit:         true,
+		RequireSymbol:        true,
+		DisallowPersonalInfo: true,
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		want     error
+	}{
+		{"valid", strictPolicy(), "Kuda-Lari-42", nil},
+		{"too short", strictPolicy(), "Kd-42a", ErrPasswordTooShort},
+		{"length counts runes", PasswordPolicy{MinLength: 4}, "ééé", ErrPasswordTooShort},
+		{"multibyte at minimum length", PasswordPolicy{MinLength: 4}, "éééé", nil},
+		{"missing upper", strictPolicy(), "kuda-lari-42", ErrPasswordMissingUpper},
+		{"missing lower", strictPolicy(), "KUDA-LARI-42", ErrPasswordMissingLower},
+		{"missing digit", strictPolicy(), "Kuda-Lari-Xy", ErrPasswordMissingDigit},
+		{"missing symbol", strictPolicy(), "KudaLari4242", ErrPasswordMissingSymbol},
+		{"space counts as symbol", strictPolicy(), "Kuda Lari 42", nil},
+		{"rules disabled", PasswordPolicy{MinLength: 4}, "kuda", nil},
+		{"contains first name", strictPolicy(), "Budi-Lari-42", ErrPasswordPersonalInfo},
+		{"contains last name in another case", strictPolicy(), "xSANTOSOx-42", ErrPasswordPersonalInfo},
+		{"contains email local part", strictPolicy(), "Xbimbim.dev-42", ErrPasswordPersonalInfo},
+		{"short name fragment ignored", strictPolicy(), "Kuda-Al-Lari-42", nil},
+		{"email domain ignored", strictPolicy(), "Example-Lari-42", nil},
+		{"personal info allowed", PasswordPolicy{MinLength: 4}, "budi-santoso", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password, "bimbim.dev@example.com", "Budi Al Santoso")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.password, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyValidateTooShortMessage(t *testing.T) {
+	err := PasswordPolicy{MinLength: 12}.Validate("pendek", "", "")
+	if err == nil || err.Error() != "password terlalu pendek: minimal 12 karakter" {
+		t.Errorf("Validate = %v, want the minimum length in the message", err)
+	}
+}
+
+func TestPasswordPolicyValidateBreached(t *testing.T) {
+	checker := &fakeBreachedChecker{breached: map[string]bool{"Password-123": true}}
+	policy := strictPolicy()
+	policy.BreachedChecker = checker
+
+	if err := policy.Validate("Password-123", "budi@example.com", "Budi"); !errors.Is(err, ErrPasswordBreached) {
+		t.Errorf("Validate(breached) = %v, want %v", err, ErrPasswordBreached)
+	}
+	if err := policy.Validate("Kuda-Lari-42", "budi@example.com", "Budi"); err != nil {
+		t.Errorf("Validate(not breached) = %v", err)
+	}
+
+	// Password yang sudah gagal aturan lain tidak dikirim ke checker
+	checker.checked = nil
+	policy.Validate("pendek", "budi@example.com", "Budi")
+	if len(checker.checked) != 0 {
+		t.Errorf("checker called with %v for a password that failed the local rules", checker.checked)
+	}
+
+	checker.err = errors.New("hash file unreadable")
+	if err := policy.Validate("Kuda-Lari-42", "budi@example.com", "Budi"); err != checker.err {
+		t.Errorf("Validate with checker error = %v, want %v", err, checker.err)
+	}
+}
+
+func TestPasswordPolicyIsReused(t *testing.T) {
+	hash := func(password string) string {
+		h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatalf("bcrypt: %v", err)
+		}
+		return string(h)
+	}
+	current := hash("sekarang")
+	history := []string{hash("lama-1"), hash("lama-2"), hash("lama-3")}
+	policy := PasswordPolicy{HistorySize: 2}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"sekarang", true},
+		{"lama-1", true},
+		{"lama-2", true},
+		{"lama-3", false}, // di luar HistorySize
+		{"baru", false},
+	}
+
+	for _, tt := range tests {
+		if got := policy.IsReused(tt.password, current, history); got != tt.want {
+			t.Errorf("IsReused(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+
+	if (PasswordPolicy{}).IsReused("lama-1", "", history) {
+		t.Error("IsReused checked the history with HistorySize 0")
+	}
+}
+
+func TestUserUpdatePasswordKeepsHistory(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 6, HistorySize: 2}
+	user, err := NewUser("budi@example.com", "pertama", "Budi", RoleEmployee, policy)
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+
+	for _, password := range []string{"kedua-1", "ketiga", "keempat"} {
+		if err := user.UpdatePassword(password, policy); err != nil {
+			t.Fatalf("UpdatePassword(%q): %v", password, err)
+		}
+	}
+	if len(user.PasswordHistory) != policy.HistorySize {
+		t.Fatalf("history = %d hashes, want %d", len(user.PasswordHistory), policy.HistorySize)
+	}
+
+	// Password sekarang dan dua sebelumnya ditolak, yang lebih lama sudah keluar dari history
+	for _, password := range []string{"keempat", "ketiga", "kedua-1"} {
+		if err := user.UpdatePassword(password, policy); !errors.Is(err, ErrPasswordReused) {
+			t.Errorf("UpdatePassword(%q) = %v, want %v", password, err, ErrPasswordReused)
+		}
+	}
+	if err := user.UpdatePassword("pertama", policy); err != nil {
+		t.Errorf("UpdatePassword(outside history) = %v", err)
+	}
+	if !user.ComparePassword("pertama") {
+		t.Error("password was not updated")
+	}
+}
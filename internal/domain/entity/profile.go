@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidEmploymentType = errors.New("tipe kepegawaian tidak valid")
+)
+
+// EmploymentType is the contract type of an employee
+type EmploymentType string
+
+const (
+	EmploymentFullTime EmploymentType = "full_time"
+	EmploymentPartTime EmploymentType = "part_time"
+	EmploymentContract EmploymentType = "contract"
+	EmploymentIntern   EmploymentType = "intern"
+)
+
+// IsValid reports whether t is a known employment type, empty means not set
+func (t EmploymentType) IsValid() bool {
+	switch t {
+	case "", EmploymentFullTime, EmploymentPartTime, EmploymentContract, EmploymentIntern:
+		return true
+	}
+	return false
+}
+
+// UpdateProfile changes the fields an employee may edit themselves
+func (u *User) UpdateProfile(nama, phone, photoRef string) error {
+	if nama == "" {
+		return ErrInvalidName
+	}
+
+	u.Nama = nama
+	u.Phone = phone
+	u.PhotoRef = photoRef
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateWorkDays changes the user's working days, empty follows the company schedule
+func (u *User) UpdateWorkDays(workDays []time.Weekday) {
+	u.WorkDays = workDays
+	u.UpdatedAt = time.Now()
+}
+
+// UpdateEmployment changes the HR fields that only administrators may edit
+func (u *User) UpdateEmployment(employeeNumber, jobTitle string, hireDate *time.Time, employmentType EmploymentType) error {
+	if !employmentType.IsValid() {
+		return ErrInvalidEmploymentType
+	}
+
+	u.EmployeeNumber = employeeNumber
+	u.JobTitle = jobTitle
+	u.HireDate = hireDate
+	u.EmploymentType = employmentType
+	u.UpdatedAt = time.Now()
+	return nil
+}
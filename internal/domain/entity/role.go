@@ -0,0 +1,178 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrInvalidRoleName   = errors.New("nama role hanya boleh huruf kecil, angka, '-' atau '_' (2-50 karakter)")
+	ErrInvalidPermission = errors.New("permission tidak dikenal")
+	ErrBuiltInRole       = errors.New("role bawaan tidak dapat diubah atau dihapus")
+	ErrInvalidDataScope  = errors.New("data scope tidak valid")
+)
+
+// Permission is a single action a role is allowed to perform
+type Permission string
+
+const (
+	PermissionPresensiApprove Permission = "presensi.approve" // Change the status of attendance records
+	PermissionPresensiDelete  Permission = "presensi.delete"
+	PermissionLocationManage  Permission = "location.manage"
+	PermissionAuditRead       Permission = "audit.read"
+	PermissionUserManage      Permission = "user.manage" // Unlock accounts and assign roles
+	PermissionRoleManage      Permission = "role.manage"
+	PermissionAPIKeyManage    Permission = "apikey.manage"
+	PermissionOrgManage       Permission = "org.manage" // Departments, teams and reporting lines
+)
+
+// AllPermissions lists every permission known to the application
+var AllPermissions = []Permission{
+	PermissionPresensiApprove,
+	PermissionPresensiDelete,
+	PermissionLocationManage,
+	PermissionAuditRead,
+	PermissionUserManage,
+	PermissionRoleManage,
+	PermissionAPIKeyManage,
+	PermissionOrgManage,
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// DataScope determines whose records a role may see
+type DataScope string
+
+const (
+	DataScopeOwn  DataScope = "own"  // Only the user's own records
+	DataScopeTeam DataScope = "team" // The user's own records and those of their reports
+	DataScopeAll  DataScope = "all"
+)
+
+func (s DataScope) IsValid() bool {
+	return s == DataScopeOwn || s == DataScopeTeam || s == DataScopeAll
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z0-9_-]{2,50}$`)
+
+// Role maps a role name, as stored on the user and in the JWT, to its permissions
+type Role struct {
+	ID          string
+	Name        string
+	Description string
+	Permissions []Permission
+	DataScope   DataScope
+	BuiltIn     bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// builtInRoles are always available and can't be changed through the API
+var builtInRoles = map[UserRole]Role{
+	RoleAdmin: {
+		Name:        string(RoleAdmin),
+		Description: "Akses penuh",
+		Permissions: AllPermissions,
+		DataScope:   DataScopeAll,
+		BuiltIn:     true,
+	},
+	RoleManager: {
+		Name:        string(RoleManager),
+		Description: "Mengelola presensi anggota tim",
+		Permissions: []Permission{PermissionPresensiApprove},
+		DataScope:   DataScopeTeam,
+		BuiltIn:     true,
+	},
+	RoleEmployee: {
+		Name:        string(RoleEmployee),
+		Description: "Presensi milik sendiri",
+		Permissions: []Permission{},
+		DataScope:   DataScopeOwn,
+		BuiltIn:     true,
+	},
+}
+
+// BuiltInRole returns the built-in role with the given name
+func BuiltInRole(name string) (*Role, bool) {
+	role, ok := builtInRoles[UserRole(name)]
+	if !ok {
+		return nil, false
+	}
+	return &role, true
+}
+
+// BuiltInRoles returns all built-in roles
+func BuiltInRoles() []Role {
+	return []Role{builtInRoles[RoleAdmin], builtInRoles[RoleManager], builtInRoles[RoleEmployee]}
+}
+
+// NewRole creates a custom role
+func NewRole(name, description string, permissions []Permission, scope DataScope) (*Role, error) {
+	if !roleNamePattern.MatchString(name) {
+		return nil, ErrInvalidRoleName
+	}
+	if _, ok := BuiltInRole(name); ok {
+		return nil, ErrBuiltInRole
+	}
+
+	now := time.Now()
+	role := &Role{
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := role.Update(description, permissions, scope); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// Update replaces the description, permissions and data scope of a custom role
+func (r *Role) Update(description string, permissions []Permission, scope DataScope) error {
+	if r.BuiltIn {
+		return ErrBuiltInRole
+	}
+	if scope == "" {
+		scope = DataScopeOwn
+	}
+	if !scope.IsValid() {
+		return ErrInvalidDataScope
+	}
+
+	unique := make([]Permission, 0, len(permissions))
+	seen := make(map[Permission]bool, len(permissions))
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return ErrInvalidPermission
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			unique = append(unique, permission)
+		}
+	}
+
+	r.Description = description
+	r.Permissions = unique
+	r.DataScope = scope
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// HasPermission checks whether the role grants the permission
+func (r *Role) HasPermission(permission Permission) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -9,7 +9,7 @@ import (
 
 var (
 	ErrInvalidEmail    = errors.New("email tidak valid")
-	ErrInvalidPassword = errors.New("password minimal 6 karakter")
+	ErrInvalidPassword = errors.New("password tidak boleh kosong")
 	ErrInvalidName     = errors.New("nama tidak boleh kosong")
 )
 
@@ -17,34 +17,68 @@ type UserRole string
 
 const (
 	RoleAdmin    UserRole = "admin"
+	RoleManager  UserRole = "manager"
 	RoleEmployee UserRole = "employee"
 )
 
+// IsValid reports whether r is a built-in role. Custom roles are validated against the role repository.
 func (r UserRole) IsValid() bool {
-	return r == RoleAdmin || r == RoleEmployee
+	return r == RoleAdmin || r == RoleManager || r == RoleEmployee
 }
 
 type User struct {
-	ID        string
-	Email     string
-	Password  string
-	Nama      string
-	Role      UserRole
-	IsActive  bool
+	ID              string
+	Email           string
+	Password        string
+	PasswordHistory []string // Previous password hashes, most recent first
+	Nama            string
+	Role            UserRole
+	IsActive        bool
+
+	// Set for imported users that must choose their password through an invitation link
+	InvitationPending bool
+
+	// Employee profile
+	EmployeeNumber string // NIK, unique when set
+	Phone          string
+	JobTitle       string
+	HireDate       *time.Time
+	EmploymentType EmploymentType
+	PhotoRef       string         // URL or storage key of the profile photo
+	WorkDays       []time.Weekday // Own working days, empty follows the company schedule
+
+	// Two-factor authentication (TOTP)
+	MFAEnabled       bool
+	MFASecret        string
+	MFARecoveryCodes []string // SHA-256 hashes of unused recovery codes
+	MFALastUsedStep  int64    // Last accepted TOTP time step, prevents code replay
+
+	// Single sign-on; empty for users that log in with a local password
+	AuthProvider string // Issuer URL of the identity provider
+	ExternalID   string // Subject at the identity provider
+
+	// Organization
+	DepartmentID string
+	TeamID       string
+	ManagerID    string // Direct manager in the reporting hierarchy
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
-func NewUser(email, password, nama string, role UserRole) (*User, error) {
+func NewUser(email, password, nama string, role UserRole, policy PasswordPolicy) (*User, error) {
 	if email == "" {
 		return nil, ErrInvalidEmail
 	}
-	if len(password) < 6 {
+	if password == "" {
 		return nil, ErrInvalidPassword
 	}
 	if nama == "" {
 		return nil, ErrInvalidName
 	}
+	if err := policy.Validate(password, email, nama); err != nil {
+		return nil, err
+	}
 	if !role.IsValid() {
 		role = RoleEmployee
 	}
@@ -71,21 +105,48 @@ func (u *User) ComparePassword(password string) bool {
 	return err == nil
 }
 
-func (u *User) UpdatePassword(newPassword string) error {
-	if len(newPassword) < 6 {
+func (u *User) UpdatePassword(newPassword string, policy PasswordPolicy) error {
+	if newPassword == "" {
 		return ErrInvalidPassword
 	}
+	if err := policy.Validate(newPassword, u.Email, u.Nama); err != nil {
+		return err
+	}
+	if policy.IsReused(newPassword, u.Password, u.PasswordHistory) {
+		return ErrPasswordReused
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 
+	if u.Password != "" && policy.HistorySize > 0 {
+		u.PasswordHistory = append([]string{u.Password}, u.PasswordHistory...)
+		if len(u.PasswordHistory) > policy.HistorySize {
+			u.PasswordHistory = u.PasswordHistory[:policy.HistorySize]
+		}
+	}
 	u.Password = string(hashedPassword)
 	u.UpdatedAt = time.Now()
 	return nil
 }
 
+// MarkInvited discards the current password, the user sets a new one by accepting the invitation
+func (u *User) MarkInvited() {
+	u.InvitationPending = true
+	u.UpdatedAt = time.Now()
+}
+
+// AcceptInvitation sets the password chosen by an invited user
+func (u *User) AcceptInvitation(password string, policy PasswordPolicy) error {
+	if err := u.UpdatePassword(password, policy); err != nil {
+		return err
+	}
+	u.InvitationPending = false
+	return nil
+}
+
 func (u *User) Deactivate() {
 	u.IsActive = false
 	u.UpdatedAt = time.Now()
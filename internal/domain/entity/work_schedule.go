@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidClock   = errors.New("jam harus dalam format HH:MM")
+	ErrInvalidWeekday = errors.New("hari kerja harus mon, tue, wed, thu, fri, sat atau sun")
+)
+
+// weekdayNames are the short names used in configuration and the API
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// WorkSchedule is the company working schedule attendance is compared with
+type WorkSchedule struct {
+	StartTime time.Duration   // Since midnight, check-ins after it are late
+	WorkHours time.Duration   // Per day, time worked beyond it is overtime
+	WorkDays  []time.Weekday  // Days of the week that are worked
+	Holidays  map[string]bool // "YYYY-MM-DD", days off for everyone
+}
+
+// ForUser returns the schedule with the user's own working days, when they have any
+func (s WorkSchedule) ForUser(user *User) WorkSchedule {
+	if len(user.WorkDays) > 0 {
+		s.WorkDays = user.WorkDays
+	}
+	return s
+}
+
+// IsWorkDay reports whether day is a working day and not a holiday
+func (s WorkSchedule) IsWorkDay(day time.Time) bool {
+	if s.Holidays[day.Format("2006-01-02")] {
+		return false
+	}
+	for _, weekday := range s.WorkDays {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectedDays counts the working days from start until end (exclusive)
+func (s WorkSchedule) ExpectedDays(start, end time.Time) int {
+	days := 0
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if s.IsWorkDay(day) {
+			days++
+		}
+	}
+	return days
+}
+
+// ParseWeekdays parses short weekday names such as "mon", duplicates are ignored
+func ParseWeekdays(names []string) ([]time.Weekday, error) {
+	var weekdays []time.Weekday
+	seen := make(map[time.Weekday]bool)
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for i, weekdayName := range weekdayNames {
+			if name == weekdayName {
+				if !seen[time.Weekday(i)] {
+					seen[time.Weekday(i)] = true
+					weekdays = append(weekdays, time.Weekday(i))
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrInvalidWeekday
+		}
+	}
+	return weekdays, nil
+}
+
+// FormatWeekdays returns the short names of the weekdays
+func FormatWeekdays(weekdays []time.Weekday) []string {
+	names := make([]string, 0, len(weekdays))
+	for _, weekday := range weekdays {
+		names = append(names, weekdayNames[weekday])
+	}
+	return names
+}
+
+// ParseHolidays parses a list of "YYYY-MM-DD" dates
+func ParseHolidays(dates []string) (map[string]bool, error) {
+	holidays := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		date = strings.TrimSpace(date)
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("tanggal libur %q tidak valid (format: YYYY-MM-DD)", date)
+		}
+		holidays[date] = true
+	}
+	return holidays, nil
+}
+
+// ParseClock parses "HH:MM" into the duration since midnight
+func ParseClock(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, ErrInvalidClock
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// FormatClock formats a duration since midnight as "HH:MM"
+func FormatClock(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
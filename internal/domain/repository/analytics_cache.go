@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// AnalyticsCache adalah port untuk cache hasil analytics yang sudah di-encode.
+// Setiap entry mencatat rentang waktu presensi [start, end) yang dibacanya, waktu nol berarti tanpa batas,
+// agar perubahan presensi hanya menghapus entry yang terpengaruh.
+type AnalyticsCache interface {
+	// Get mengembalikan false jika key tidak ada atau sudah kedaluwarsa
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, start, end time.Time, ttl time.Duration) error
+
+	// Invalidate menghapus semua entry yang rentangnya memuat t
+	Invalidate(ctx context.Context, t time.Time) error
+}
@@ -11,15 +11,36 @@ type AnalyticsRepository interface {
 	// GetSummary returns overall attendance summary with optional date filter
 	GetSummary(ctx context.Context, filter entity.AnalyticsFilter) (*entity.AttendanceSummary, error)
 
-	// GetDailySummary returns attendance summary for a specific date
-	GetDailySummary(ctx context.Context, date string) (*entity.DailySummary, error)
+	// GetDailySummary returns attendance summary for a specific date, the filter's dates are ignored
+	GetDailySummary(ctx context.Context, date string, filter entity.AnalyticsFilter) (*entity.DailySummary, error)
 
-	// GetMonthlySummary returns attendance summary for a month
-	GetMonthlySummary(ctx context.Context, month string) (*entity.MonthlySummary, error)
+	// GetMonthlySummary returns attendance summary for a month, the filter's dates are ignored
+	GetMonthlySummary(ctx context.Context, month string, filter entity.AnalyticsFilter) (*entity.MonthlySummary, error)
 
 	// GetUserSummary returns attendance summary for a specific user
 	GetUserSummary(ctx context.Context, userID string, filter entity.AnalyticsFilter) (*entity.UserSummary, error)
 
 	// GetStatusBreakdown returns count per status
 	GetStatusBreakdown(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.StatusBreakdown, error)
+
+	// GetPeriodSummary returns the summary of a period with one bucket per interval, the filter's dates are ignored
+	GetPeriodSummary(ctx context.Context, period entity.AnalyticsPeriod, interval entity.AnalyticsInterval, filter entity.AnalyticsFilter) (*entity.PeriodSummary, error)
+
+	// GetPunctuality returns check-in punctuality per user, team or department, most late minutes first
+	GetPunctuality(ctx context.Context, period entity.AnalyticsPeriod, schedule entity.WorkSchedule, groupBy entity.AnalyticsGroupBy, filter entity.AnalyticsFilter) ([]entity.PunctualityStats, error)
+
+	// GetPunctualityRanking returns the limit users ranked by punctuality, users without check-ins are left out
+	GetPunctualityRanking(ctx context.Context, period entity.AnalyticsPeriod, schedule entity.WorkSchedule, ranking entity.PunctualityRanking, limit int, filter entity.AnalyticsFilter) ([]entity.PunctualityStats, error)
+
+	// GetArrivalHistogram returns the number of check-ins per ArrivalBucketMinutes of the check-in time
+	GetArrivalHistogram(ctx context.Context, period entity.AnalyticsPeriod, filter entity.AnalyticsFilter) ([]entity.ArrivalBucket, error)
+
+	// GetOnTimeStreaks returns the current and longest on-time streak per user, longest current streak first
+	GetOnTimeStreaks(ctx context.Context, period entity.AnalyticsPeriod, schedule entity.WorkSchedule, filter entity.AnalyticsFilter) ([]entity.OnTimeStreak, error)
+
+	// GetWorkingHours returns hours worked per user, team or department, most hours first
+	GetWorkingHours(ctx context.Context, period entity.AnalyticsPeriod, schedule entity.WorkSchedule, groupBy entity.AnalyticsGroupBy, filter entity.AnalyticsFilter) ([]entity.WorkingHoursStats, error)
+
+	// GetWorkingHoursSeries returns hours worked with one bucket per interval
+	GetWorkingHoursSeries(ctx context.Context, period entity.AnalyticsPeriod, interval entity.AnalyticsInterval, schedule entity.WorkSchedule, filter entity.AnalyticsFilter) ([]entity.WorkingHoursBucket, error)
 }
@@ -0,0 +1,28 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// APIKeyRepository adalah port untuk akses data API key
+type APIKeyRepository interface {
+	Create(ctx context.Context, apiKey *entity.APIKey) error
+	GetByID(ctx context.Context, id string) (*entity.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	GetAll(ctx context.Context) ([]entity.APIKey, error)
+	Update(ctx context.Context, apiKey *entity.APIKey) error
+
+	// UpdateLastUsed mencatat waktu terakhir API key dipakai
+	UpdateLastUsed(ctx context.Context, id string, at time.Time) error
+}
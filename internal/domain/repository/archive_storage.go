@@ -0,0 +1,29 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrArchiveNotFound = errors.New("arsip tidak ditemukan")
+
+// ArchiveStorage adalah port untuk menyimpan file arsip, di disk lokal atau object storage
+type ArchiveStorage interface {
+	// Put menyimpan isi r dengan nama name, nama yang sudah ada tidak boleh ditimpa
+	Put(ctx context.Context, name string, r io.Reader) error
+
+	// Open membuka arsip, ErrArchiveNotFound jika tidak ada
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// List mengambil nama semua arsip dengan prefix tertentu, urut berdasarkan nama
+	List(ctx context.Context, prefix string) ([]string, error)
+}
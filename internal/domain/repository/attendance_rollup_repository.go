@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRollupRebuildRunning dikembalikan Rebuild saat rebuild lain sedang berjalan
+var ErrRollupRebuildRunning = errors.New("rebuild rekap presensi sedang berjalan")
+
+// AttendanceRollupRepository adalah port untuk rekap presensi harian per hari, user dan department.
+// Analytics membaca rekap untuk hari-hari sebelum hari ini agar tidak mengagregasi seluruh presensi.
+type AttendanceRollupRepository interface {
+	// Refresh menghitung ulang rekap user pada hari kalender yang memuat day dari data presensi
+	Refresh(ctx context.Context, userID string, day time.Time) error
+
+	// Rebuild menghitung ulang semua rekap hari kalender dalam rentang [start, end), waktu nol berarti
+	// tanpa batas. Rebuild tanpa batas sama sekali menandai rekap sebagai terkini. Rekap lama tetap
+	// terbaca sampai diganti, dan hanya satu rebuild yang berjalan di semua instance.
+	// Mengembalikan jumlah rekap yang ditulis.
+	Rebuild(ctx context.Context, start, end time.Time) (int64, error)
+
+	// IsCurrent mengecek apakah rekap sudah di-rebuild penuh di zona waktu repository dan
+	// belum ditandai usang sejak itu
+	IsCurrent(ctx context.Context) (bool, error)
+
+	// MarkStale menandai rekap usang, dipanggil saat presensi bisa berubah tanpa rekap diperbarui
+	MarkStale(ctx context.Context) error
+}
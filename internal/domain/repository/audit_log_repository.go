@@ -10,6 +10,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/okinn/service-presensi/internal/domain/entity"
@@ -24,18 +25,73 @@ type AuditLogFilter struct {
 	StartDate  time.Time
 	EndDate    time.Time
 	IPAddress  string
+	RequestID  string
+
+	// Search mencari teks pada pesan error dan nilai yang berubah
+	Search string
+
+	// Rentang status code dan durasi request, nol berarti tanpa batas
+	StatusCodeMin int
+	StatusCodeMax int
+	MinDuration   int64 // ms
+	MaxDuration   int64 // ms
+
+	// ExcludeEntityTypes mengecualikan entity type tertentu jika EntityType kosong
+	ExcludeEntityTypes []string
+}
+
+// AuditLogSortField adalah field yang dapat dipakai untuk mengurutkan audit logs
+type AuditLogSortField string
+
+const (
+	AuditLogSortCreatedAt  AuditLogSortField = "created_at"
+	AuditLogSortDuration   AuditLogSortField = "duration_ms"
+	AuditLogSortStatusCode AuditLogSortField = "status_code"
+)
+
+// IsValid mengecek apakah field sort didukung
+func (f AuditLogSortField) IsValid() bool {
+	switch f {
+	case AuditLogSortCreatedAt, AuditLogSortDuration, AuditLogSortStatusCode:
+		return true
+	}
+	return false
+}
+
+// AuditLogSort menentukan urutan audit logs, default created_at terbaru lebih dulu
+type AuditLogSort struct {
+	Field     AuditLogSortField
+	Ascending bool
+}
+
+// AuditLogPage adalah satu halaman hasil pagination cursor
+type AuditLogPage struct {
+	Items []entity.AuditLog
+
+	// NextCursor kosong jika tidak ada halaman berikutnya
+	NextCursor string
 }
 
+var ErrInvalidAuditCursor = errors.New("cursor audit log tidak valid")
+
 // AuditLogRepository adalah port untuk akses data audit log
 type AuditLogRepository interface {
-	// Create menyimpan audit log baru
+	// Create menyimpan audit log baru di ujung hash chain, mengisi Sequence, PrevHash dan Hash
 	Create(ctx context.Context, auditLog *entity.AuditLog) error
 
+	// CreateMany menyimpan beberapa audit log sekaligus di ujung hash chain sesuai urutan. Entri
+	// yang sudah memiliki ID dan sudah tersimpan dilewati, sehingga aman diulang setelah gagal.
+	CreateMany(ctx context.Context, auditLogs []*entity.AuditLog) error
+
 	// GetByID mengambil audit log berdasarkan ID
 	GetByID(ctx context.Context, id string) (*entity.AuditLog, error)
 
-	// GetAll mengambil semua audit logs dengan filter dan pagination
-	GetAll(ctx context.Context, filter AuditLogFilter, page, limit int) ([]entity.AuditLog, int64, error)
+	// GetAll mengambil semua audit logs dengan filter, urutan dan pagination halaman
+	GetAll(ctx context.Context, filter AuditLogFilter, sort AuditLogSort, page, limit int) ([]entity.AuditLog, int64, error)
+
+	// GetAllByCursor mengambil audit logs setelah cursor, cursor kosong untuk halaman pertama.
+	// ErrInvalidAuditCursor jika cursor tidak valid atau dibuat untuk urutan lain.
+	GetAllByCursor(ctx context.Context, filter AuditLogFilter, sort AuditLogSort, cursor string, limit int) (*AuditLogPage, error)
 
 	// GetByEntityID mengambil audit logs untuk entity tertentu
 	GetByEntityID(ctx context.Context, entityType, entityID string) ([]entity.AuditLog, error)
@@ -43,6 +99,30 @@ type AuditLogRepository interface {
 	// GetByUserID mengambil audit logs untuk user tertentu
 	GetByUserID(ctx context.Context, userID string, page, limit int) ([]entity.AuditLog, int64, error)
 
-	// DeleteOldLogs menghapus audit logs yang lebih lama dari retention period
-	DeleteOldLogs(ctx context.Context, olderThan time.Time) (int64, error)
+	// GetLast mengambil entri terakhir hash chain, nil jika chain masih kosong
+	GetLast(ctx context.Context) (*entity.AuditLog, error)
+
+	// WalkChain memanggil fn untuk setiap entri chain mulai dari fromSequence, urut berdasarkan sequence
+	WalkChain(ctx context.Context, fromSequence int64, fn func(*entity.AuditLog) error) error
+
+	// WalkUnarchived memanggil fn untuk setiap entri yang belum diarsip sesuai filter, urut berdasarkan created_at
+	WalkUnarchived(ctx context.Context, filter AuditLogFilter, fn func(*entity.AuditLog) error) error
+
+	// MarkArchived menghapus isi entri dan hanya menyisakan posisinya di hash chain beserta nama arsipnya
+	MarkArchived(ctx context.Context, ids []string, archiveName string) (int64, error)
+
+	// Restore mengembalikan isi entri yang sudah diarsip, false jika entri tidak ada atau belum diarsip
+	Restore(ctx context.Context, auditLog *entity.AuditLog) (bool, error)
+}
+
+// AuditCheckpointRepository adalah port untuk checkpoint hash chain audit log yang ditandatangani
+type AuditCheckpointRepository interface {
+	// Create menyimpan checkpoint baru
+	Create(ctx context.Context, checkpoint *entity.AuditCheckpoint) error
+
+	// GetAll mengambil semua checkpoint, urut berdasarkan sequence
+	GetAll(ctx context.Context) ([]entity.AuditCheckpoint, error)
+
+	// GetLatest mengambil checkpoint terakhir, nil jika belum ada
+	GetLatest(ctx context.Context) (*entity.AuditCheckpoint, error)
 }
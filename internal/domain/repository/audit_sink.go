@@ -0,0 +1,27 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import (
+	"context"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// AuditSink adalah port untuk meneruskan audit log ke sistem eksternal seperti SIEM
+type AuditSink interface {
+	// Name mengidentifikasi sink di log
+	Name() string
+
+	// Send mengirim satu entri audit log yang sudah tersimpan
+	Send(ctx context.Context, auditLog *entity.AuditLog) error
+
+	// Close melepaskan koneksi atau file yang dipakai sink
+	Close() error
+}
@@ -0,0 +1,24 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import (
+	"context"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// IdentityProvider adalah port untuk OpenID Connect provider (single sign-on)
+type IdentityProvider interface {
+	// AuthCodeURL membuat URL authorization-code flow dengan PKCE (S256)
+	AuthCodeURL(state, nonce, codeChallenge string) string
+
+	// Exchange menukar authorization code dan mengembalikan identitas dari ID token yang sudah diverifikasi
+	Exchange(ctx context.Context, code, codeVerifier string) (*entity.ExternalIdentity, error)
+}
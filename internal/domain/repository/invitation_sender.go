@@ -0,0 +1,17 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import "context"
+
+// InvitationSender adalah port untuk mengirim email undangan ke user baru
+type InvitationSender interface {
+	// SendInvitation mengirim link untuk menentukan password pertama kali
+	SendInvitation(ctx context.Context, email, nama, acceptURL string) error
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// LoginAttemptRepository adalah port untuk menyimpan percobaan login gagal per email
+type LoginAttemptRepository interface {
+	// Get mengambil data percobaan login, mengembalikan attempt kosong jika belum ada
+	Get(ctx context.Context, email string) (*entity.LoginAttempt, error)
+
+	// RecordFailure menambah jumlah kegagalan secara atomik, kegagalan sebelum windowStart tidak dihitung
+	RecordFailure(ctx context.Context, email string, at, windowStart time.Time) (*entity.LoginAttempt, error)
+
+	// Lock mengunci akun sampai waktu tertentu
+	Lock(ctx context.Context, email string, until time.Time) error
+
+	// Reset menghapus data percobaan login (setelah login sukses atau unlock oleh admin)
+	Reset(ctx context.Context, email string) error
+}
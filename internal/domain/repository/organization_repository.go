@@ -0,0 +1,33 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import (
+	"context"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// DepartmentRepository adalah port untuk akses data department
+type DepartmentRepository interface {
+	Create(ctx context.Context, department *entity.Department) error
+	GetByID(ctx context.Context, id string) (*entity.Department, error)
+	GetAll(ctx context.Context) ([]entity.Department, error)
+	Update(ctx context.Context, department *entity.Department) error
+	Delete(ctx context.Context, id string) error
+}
+
+// TeamRepository adalah port untuk akses data team
+type TeamRepository interface {
+	Create(ctx context.Context, team *entity.Team) error
+	GetByID(ctx context.Context, id string) (*entity.Team, error)
+	GetAll(ctx context.Context) ([]entity.Team, error)
+	Update(ctx context.Context, team *entity.Team) error
+	Delete(ctx context.Context, id string) error
+}
@@ -14,6 +14,14 @@ type PresensiFilter struct {
 	Status    valueobject.StatusPresensi
 	StartDate time.Time
 	EndDate   time.Time
+
+	// DepartmentID, TeamID dan ManagerID di-resolve oleh use case menjadi UserIDs
+	DepartmentID string
+	TeamID       string
+	ManagerID    string
+
+	// UserIDs membatasi hasil ke user tertentu; nil berarti tanpa batasan, slice kosong berarti tidak ada hasil
+	UserIDs []string
 }
 
 // PresensiRepository adalah port untuk akses data presensi
@@ -0,0 +1,22 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// RefreshTokenRepository adalah port untuk mencatat refresh token yang sudah dicabut.
+// Refresh token dicabut saat ditukar (rotation) dan saat logout, sehingga hanya bisa dipakai sekali.
+type RefreshTokenRepository interface {
+	// Revoke mencabut refresh token dengan ID (jti) tertentu sampai token itu expired.
+	// Mengembalikan false jika token sudah dicabut sebelumnya.
+	Revoke(ctx context.Context, tokenID, userID string, expiresAt time.Time) (bool, error)
+}
@@ -0,0 +1,27 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package repository
+
+import (
+	"context"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// RoleRepository adalah port untuk akses data custom role
+type RoleRepository interface {
+	Create(ctx context.Context, role *entity.Role) error
+
+	// GetByName mengembalikan nil tanpa error jika role tidak ada
+	GetByName(ctx context.Context, name string) (*entity.Role, error)
+
+	GetAll(ctx context.Context) ([]entity.Role, error)
+	Update(ctx context.Context, role *entity.Role) error
+	Delete(ctx context.Context, name string) error
+}
@@ -6,11 +6,31 @@ import (
 	"github.com/okinn/service-presensi/internal/domain/entity"
 )
 
+// UserFilter untuk mencari user berdasarkan organisasi.
+// Kriteria yang diisi digabung dengan OR, filter kosong mengembalikan semua user.
+type UserFilter struct {
+	DepartmentIDs []string
+	TeamIDs       []string
+	ManagerIDs    []string
+}
+
 // UserRepository adalah port untuk akses data user
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
 	GetByID(ctx context.Context, id string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetByEmployeeNumber(ctx context.Context, employeeNumber string) (*entity.User, error)
+
+	// GetByExternalID mencari user SSO berdasarkan issuer dan subject identity provider
+	GetByExternalID(ctx context.Context, provider, externalID string) (*entity.User, error)
+
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id string) error
+
+	// CountByRole menghitung user dengan role tertentu
+	CountByRole(ctx context.Context, role string) (int64, error)
+
+	// Find dan FindIDs mencari user berdasarkan department, team, atau manager langsung
+	Find(ctx context.Context, filter UserFilter) ([]entity.User, error)
+	FindIDs(ctx context.Context, filter UserFilter) ([]string, error)
 }
@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// AuditChainService verifies the audit hash chain and writes signed checkpoints of its head
+type AuditChainService struct {
+	auditRepo      repository.AuditLogRepository
+	checkpointRepo repository.AuditCheckpointRepository
+	signingKey     []byte
+}
+
+// NewAuditChainService creates a new audit chain service. signingKey is the HMAC key for checkpoints.
+func NewAuditChainService(auditRepo repository.AuditLogRepository, checkpointRepo repository.AuditCheckpointRepository, signingKey string) *AuditChainService {
+	return &AuditChainService{
+		auditRepo:      auditRepo,
+		checkpointRepo: checkpointRepo,
+		signingKey:     []byte(signingKey),
+	}
+}
+
+// Checkpoint signs the current head of the chain. It returns nil when the chain is empty
+// or nothing was appended since the latest checkpoint.
+func (s *AuditChainService) Checkpoint(ctx context.Context) (*entity.AuditCheckpoint, error) {
+	last, err := s.auditRepo.GetLast(ctx)
+	if err != nil || last == nil {
+		return nil, err
+	}
+
+	latest, err := s.checkpointRepo.GetLatest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.Sequence == last.Sequence {
+		return nil, nil
+	}
+
+	checkpoint := &entity.AuditCheckpoint{
+		Sequence:  last.Sequence,
+		Hash:      last.Hash,
+		CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	checkpoint.Signature = s.sign(checkpoint)
+
+	if err := s.checkpointRepo.Create(ctx, checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// Verify walks the chain from fromSequence (1 for the whole chain) and reports modified
+// entries, broken links, missing entries and entries that disagree with a signed checkpoint.
+// Missing entries before a checkpointed sequence are accepted as purged.
+func (s *AuditChainService) Verify(ctx context.Context, fromSequence int64) (*entity.AuditChainReport, error) {
+	if fromSequence < 1 {
+		fromSequence = 1
+	}
+
+	report := &entity.AuditChainReport{
+		Valid:      true,
+		Issues:     []entity.AuditChainIssue{},
+		VerifiedAt: time.Now(),
+	}
+
+	checkpoints, err := s.checkpointRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Checkpoint tepat sebelum fromSequence dipakai sebagai jangkar entri pertama
+	signed := make(map[int64]entity.AuditCheckpoint)
+	var head *entity.AuditCheckpoint
+	for i := range checkpoints {
+		checkpoint := checkpoints[i]
+		if checkpoint.Sequence < fromSequence-1 {
+			continue
+		}
+
+		report.CheckpointsChecked++
+		if !hmac.Equal([]byte(checkpoint.Signature), []byte(s.sign(&checkpoint))) {
+			report.AddIssue(checkpoint.Sequence, entity.AuditChainInvalidSignature, "tanda tangan checkpoint tidak valid")
+			continue
+		}
+		signed[checkpoint.Sequence] = checkpoint
+		if head == nil || checkpoint.Sequence > head.Sequence {
+			head = &checkpoint
+		}
+	}
+
+	var prev *entity.AuditLog
+	err = s.auditRepo.WalkChain(ctx, fromSequence, func(entry *entity.AuditLog) error {
+		report.EntriesChecked++
+
+		switch {
+		case prev == nil:
+			report.FirstSequence = entry.Sequence
+			s.verifyStart(report, entry, fromSequence, signed)
+		case entry.Sequence != prev.Sequence+1:
+			report.AddIssue(entry.Sequence, entity.AuditChainGap, missingMessage(prev.Sequence+1, entry.Sequence-1))
+		case entry.PrevHash != prev.Hash:
+			report.AddIssue(entry.Sequence, entity.AuditChainLinkMismatch, "prev_hash tidak sama dengan hash entri sebelumnya")
+		}
+
+		// Isi entri yang sudah diarsip ada di file arsip, hanya link-nya yang bisa diperiksa di sini
+		if !entry.Archived && entry.ComputeHash() != entry.Hash {
+			report.AddIssue(entry.Sequence, entity.AuditChainHashMismatch, "isi entri tidak sesuai dengan hash")
+		}
+		if checkpoint, ok := signed[entry.Sequence]; ok && checkpoint.Hash != entry.Hash {
+			report.AddIssue(entry.Sequence, entity.AuditChainCheckpointMismatch, "hash entri berbeda dengan checkpoint")
+		}
+
+		report.LastSequence = entry.Sequence
+		prev = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Entri terbaru yang dihapus hanya terdeteksi melalui checkpoint
+	if head != nil && head.Sequence > report.LastSequence && head.Sequence >= fromSequence {
+		report.AddIssue(head.Sequence, entity.AuditChainGap, missingMessage(max(report.LastSequence+1, fromSequence), head.Sequence))
+	}
+
+	return report, nil
+}
+
+// verifyStart checks the first visited entry, which has no previous entry to link to
+func (s *AuditChainService) verifyStart(report *entity.AuditChainReport, first *entity.AuditLog, fromSequence int64, signed map[int64]entity.AuditCheckpoint) {
+	if first.Sequence == 1 {
+		if first.PrevHash != "" {
+			report.AddIssue(first.Sequence, entity.AuditChainLinkMismatch, "entri pertama tidak boleh memiliki prev_hash")
+		}
+		return
+	}
+
+	if anchor, ok := signed[first.Sequence-1]; ok {
+		if anchor.Hash != first.PrevHash {
+			report.AddIssue(first.Sequence, entity.AuditChainLinkMismatch, "prev_hash tidak sama dengan checkpoint")
+		}
+		return
+	}
+
+	if first.Sequence > fromSequence {
+		report.AddIssue(first.Sequence, entity.AuditChainGap, missingMessage(fromSequence, first.Sequence-1))
+	}
+}
+
+func (s *AuditChainService) sign(checkpoint *entity.AuditCheckpoint) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(strconv.FormatInt(checkpoint.Sequence, 10)))
+	mac.Write([]byte("|" + checkpoint.Hash + "|"))
+	mac.Write([]byte(checkpoint.CreatedAt.UTC().Format(time.RFC3339Nano)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func missingMessage(from, to int64) string {
+	if from == to {
+		return fmt.Sprintf("entri %d hilang", from)
+	}
+	return fmt.Sprintf("entri %d sampai %d hilang", from, to)
+}
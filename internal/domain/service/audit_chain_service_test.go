@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// memoryAuditChain menyimpan hash chain di memory, hanya method yang dipakai AuditChainService
+type memoryAuditChain struct {
+	repository.AuditLogRepository
+	entries []*entity.AuditLog
+}
+
+func (r *memoryAuditChain) Create(ctx context.Context, auditLog *entity.AuditLog) error {
+	last, _ := r.GetLast(ctx)
+	auditLog.Chain(last)
+	r.entries = append(r.entries, auditLog)
+	return nil
+}
+
+func (r *memoryAuditChain) GetLast(ctx context.Context) (*entity.AuditLog, error) {
+	if len(r.entries) == 0 {
+		return nil, nil
+	}
+	return r.entries[len(r.entries)-1], nil
+}
+
+func (r *memoryAuditChain) WalkChain(ctx context.Context, fromSequence int64, fn func(*entity.AuditLog) error) error {
+	for _, entry := range r.entries {
+		if entry.Sequence < fromSequence {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove menghapus entri dengan sequence tertentu, seperti penghapusan langsung di database
+func (r *memoryAuditChain) remove(sequences ...int64) {
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		if containsSequence(sequences, entry.Sequence) {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	r.entries = kept
+}
+
+// archive mengosongkan isi entri seperti MarkArchived, posisi dan hash tetap disimpan
+func (r *memoryAuditChain) archive(sequences ...int64) {
+	for _, entry := range r.entries {
+		if containsSequence(sequences, entry.Sequence) {
+			*entry = entity.AuditLog{
+				ID:          entry.ID,
+				EntityType:  entry.EntityType,
+				Action:      entry.Action,
+				Sequence:    entry.Sequence,
+				PrevHash:    entry.PrevHash,
+				Hash:        entry.Hash,
+				CreatedAt:   entry.CreatedAt,
+				Archived:    true,
+				ArchiveName: "audit-2024-01.jsonl.gz",
+			}
+		}
+	}
+}
+
+func containsSequence(sequences []int64, sequence int64) bool {
+	for _, s := range sequences {
+		if s == sequence {
+			return true
+		}
+	}
+	return false
+}
+
+type memoryCheckpoints struct {
+	checkpoints []entity.AuditCheckpoint
+}
+
+func (r *memoryCheckpoints) Create(ctx context.Context, checkpoint *entity.AuditCheckpoint) error {
+	r.checkpoints = append(r.checkpoints, *checkpoint)
+	return nil
+}
+
+func (r *memoryCheckpoints) GetAll(ctx context.Context) ([]entity.AuditCheckpoint, error) {
+	return r.checkpoints, nil
+}
+
+func (r *memoryCheckpoints) GetLatest(ctx context.Context) (*entity.AuditCheckpoint, error) {
+	if len(r.checkpoints) == 0 {
+		return nil, nil
+	}
+	latest := r.checkpoints[len(r.checkpoints)-1]
+	return &latest, nil
+}
+
+// newAuditChain membuat chain dengan count entri, checkpoint dibuat setelah entri di checkpointAt
+func newAuditChain(t *testing.T, count int64, checkpointAt ...int64) (*AuditChainService, *memoryAuditChain, *memoryCheckpoints) {
+	t.Helper()
+	ctx := context.Background()
+	chain := &memoryAuditChain{}
+	checkpoints := &memoryCheckpoints{}
+	service := NewAuditChainService(chain, checkpoints, "checkpoint-key")
+
+	created := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	for i := int64(1); i <= count; i++ {
+		entry := entity.NewAuditLog("presensi", "presensi-1", entity.AuditActionUpdate, "user-1", "budi@example.com", "admin", "10.0.0.1")
+		entry.CreatedAt = created.Add(time.Duration(i) * time.Minute)
+		entry.SetChanges(`{"status":"izin"}`, `{"status":"hadir"}`, "")
+		if err := chain.Create(ctx, entry); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if containsSequence(checkpointAt, i) {
+			if _, err := service.Checkpoint(ctx); err != nil {
+				t.Fatalf("Checkpoint: %v", err)
+			}
+		}
+	}
+	return service, chain, checkpoints
+}
+
+func TestAuditChainVerify(t *testing.T) {
+	type issue struct {
+		Sequence int64
+		Type     entity.AuditChainIssueType
+	}
+
+	tests := []struct {
+		name         string
+		checkpointAt []int64
+		from         int64
+		tamper       func(chain *memoryAuditChain, checkpoints *memoryCheckpoints)
+		want         []issue
+	}{
+		{
+			name:         "intact chain",
+			checkpointAt: []int64{3, 5},
+		},
+		{
+			name: "modified content",
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.entries[2].NewValue = `{"status":"sakit"}`
+			},
+			want: []issue{{3, entity.AuditChainHashMismatch}},
+		},
+		{
+			name: "modified content with recomputed hash",
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.entries[2].NewValue = `{"status":"sakit"}`
+				chain.entries[2].Hash = chain.entries[2].ComputeHash()
+			},
+			want: []issue{{4, entity.AuditChainLinkMismatch}},
+		},
+		{
+			name:         "rewritten chain after a checkpoint",
+			checkpointAt: []int64{3},
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				// Seluruh chain dihitung ulang, hanya checkpoint yang membuktikan perubahan
+				chain.entries[1].NewValue = `{"status":"sakit"}`
+				for i, entry := range chain.entries[1:] {
+					entry.Chain(chain.entries[i])
+				}
+			},
+			want: []issue{{3, entity.AuditChainCheckpointMismatch}},
+		},
+		{
+			name: "removed middle entry",
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.remove(3)
+			},
+			want: []issue{{4, entity.AuditChainGap}},
+		},
+		{
+			name:         "removed newest entries",
+			checkpointAt: []int64{5},
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.remove(4, 5)
+			},
+			want: []issue{{5, entity.AuditChainGap}},
+		},
+		{
+			name:         "purged entries before a checkpoint",
+			checkpointAt: []int64{2},
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.remove(1, 2)
+			},
+		},
+		{
+			name: "removed first entries without a checkpoint",
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.remove(1, 2)
+			},
+			want: []issue{{3, entity.AuditChainGap}},
+		},
+		{
+			name: "archived stubs",
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.archive(1, 2, 3)
+			},
+		},
+		{
+			name: "archived stub with modified hash",
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.archive(2)
+				chain.entries[1].Hash = "0000"
+			},
+			want: []issue{{3, entity.AuditChainLinkMismatch}},
+		},
+		{
+			name:         "checkpoint signature mismatch",
+			checkpointAt: []int64{3},
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				checkpoints.checkpoints[0].Hash = chain.entries[1].Hash
+			},
+			want: []issue{{3, entity.AuditChainInvalidSignature}},
+		},
+		{
+			name:         "checkpoint signed with another key",
+			checkpointAt: []int64{3},
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				forged := NewAuditChainService(chain, checkpoints, "other-key")
+				checkpoints.checkpoints[0].Signature = forged.sign(&checkpoints.checkpoints[0])
+			},
+			want: []issue{{3, entity.AuditChainInvalidSignature}},
+		},
+		{
+			name:         "verify from a checkpoint",
+			checkpointAt: []int64{2},
+			from:         3,
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				// Entri sebelum fromSequence tidak diperiksa
+				chain.entries[0].NewValue = `{"status":"sakit"}`
+			},
+		},
+		{
+			name:         "verify from a checkpoint that does not match",
+			checkpointAt: []int64{2},
+			from:         3,
+			tamper: func(chain *memoryAuditChain, checkpoints *memoryCheckpoints) {
+				chain.entries[2].PrevHash = "0000"
+				chain.entries[2].Hash = chain.entries[2].ComputeHash()
+				chain.entries[3].Chain(chain.entries[2])
+				chain.entries[4].Chain(chain.entries[3])
+			},
+			want: []issue{{3, entity.AuditChainLinkMismatch}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, chain, checkpoints := newAuditChain(t, 5, tt.checkpointAt...)
+			if tt.tamper != nil {
+				tt.tamper(chain, checkpoints)
+			}
+
+			report, err := service.Verify(context.Background(), tt.from)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+
+			var got []issue
+			for _, i := range report.Issues {
+				got = append(got, issue{i.Sequence, i.Type})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+			if report.Valid != (len(tt.want) == 0) || report.IssueCount != len(tt.want) {
+				t.Errorf("valid = %v, issue count = %d, want %d issues", report.Valid, report.IssueCount, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestAuditChainCheckpointSkipsUnchangedHead(t *testing.T) {
+	ctx := context.Background()
+	service, chain, checkpoints := newAuditChain(t, 0)
+
+	if checkpoint, err := service.Checkpoint(ctx); err != nil || checkpoint != nil {
+		t.Fatalf("Checkpoint of an empty chain = %v, %v, want nil", checkpoint, err)
+	}
+
+	chain.Create(ctx, entity.NewAuditLog("presensi", "presensi-1", entity.AuditActionCreate, "user-1", "budi@example.com", "admin", "10.0.0.1"))
+	first, err := service.Checkpoint(ctx)
+	if err != nil || first == nil || first.Sequence != 1 || first.Hash != chain.entries[0].Hash {
+		t.Fatalf("Checkpoint = %+v, %v, want the head at sequence 1", first, err)
+	}
+	if again, err := service.Checkpoint(ctx); err != nil || again != nil {
+		t.Errorf("Checkpoint without new entries = %+v, %v, want nil", again, err)
+	}
+	if len(checkpoints.checkpoints) != 1 {
+		t.Errorf("checkpoints = %d, want 1", len(checkpoints.checkpoints))
+	}
+}
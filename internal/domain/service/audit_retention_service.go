@@ -0,0 +1,213 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+const (
+	auditArchivePrefix    = "audit-"
+	auditArchiveBatchSize = 5000 // Entries per archive file
+)
+
+var unsafeArchiveLabel = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// AuditRetentionService moves audit entries past their retention period to gzip-compressed
+// NDJSON archives. Archived entries keep their place in the hash chain without their content,
+// and an archive can be imported again to restore the content for an investigation.
+type AuditRetentionService struct {
+	auditRepo repository.AuditLogRepository
+	storage   repository.ArchiveStorage
+	policy    entity.AuditRetentionPolicy
+}
+
+// NewAuditRetentionService creates a new audit retention service
+func NewAuditRetentionService(auditRepo repository.AuditLogRepository, storage repository.ArchiveStorage, policy entity.AuditRetentionPolicy) *AuditRetentionService {
+	return &AuditRetentionService{
+		auditRepo: auditRepo,
+		storage:   storage,
+		policy:    policy,
+	}
+}
+
+// Archive archives every entry that is older than the retention period of its entity type
+func (s *AuditRetentionService) Archive(ctx context.Context) (*entity.AuditArchiveResult, error) {
+	now := time.Now()
+	result := &entity.AuditArchiveResult{Archives: []string{}}
+
+	overridden := make([]string, 0, len(s.policy.ByEntityType))
+	for entityType := range s.policy.ByEntityType {
+		overridden = append(overridden, entityType)
+	}
+	sort.Strings(overridden)
+
+	for _, entityType := range overridden {
+		retention := s.policy.ByEntityType[entityType]
+		if retention <= 0 {
+			continue
+		}
+		filter := repository.AuditLogFilter{EntityType: entityType, EndDate: now.Add(-retention)}
+		if err := s.archive(ctx, filter, entityType, now, result); err != nil {
+			return result, err
+		}
+	}
+
+	if s.policy.Default > 0 {
+		filter := repository.AuditLogFilter{ExcludeEntityTypes: overridden, EndDate: now.Add(-s.policy.Default)}
+		if err := s.archive(ctx, filter, "default", now, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s *AuditRetentionService) archive(ctx context.Context, filter repository.AuditLogFilter, label string, now time.Time, result *entity.AuditArchiveResult) error {
+	label = unsafeArchiveLabel.ReplaceAllString(label, "_")
+	part := 0
+	batch := make([]*entity.AuditLog, 0, auditArchiveBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		part++
+		name := fmt.Sprintf("%s%s-%s-%03d.ndjson.gz", auditArchivePrefix, label, now.UTC().Format("20060102T150405Z"), part)
+		written, err := s.archiveBatch(ctx, name, batch)
+		if err != nil {
+			return err
+		}
+		if written {
+			result.Archives = append(result.Archives, name)
+		}
+		result.Archived += int64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	err := s.auditRepo.WalkUnarchived(ctx, filter, func(entry *entity.AuditLog) error {
+		batch = append(batch, entry)
+		if len(batch) == auditArchiveBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// archiveBatch writes the batch to the archive name and strips the entries from the database.
+// Entries restored from an earlier archive are only stripped again. It reports whether a new
+// archive was written.
+func (s *AuditRetentionService) archiveBatch(ctx context.Context, name string, batch []*entity.AuditLog) (bool, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+
+	var newIDs []string
+	restoredIDs := make(map[string][]string)
+	for _, entry := range batch {
+		if entry.ArchiveName != "" {
+			restoredIDs[entry.ArchiveName] = append(restoredIDs[entry.ArchiveName], entry.ID)
+			continue
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return false, err
+		}
+		newIDs = append(newIDs, entry.ID)
+	}
+	if err := gz.Close(); err != nil {
+		return false, err
+	}
+
+	// Arsip harus tersimpan sebelum isi entri dihapus dari database
+	if len(newIDs) > 0 {
+		if err := s.storage.Put(ctx, name, &buf); err != nil {
+			return false, err
+		}
+		if _, err := s.auditRepo.MarkArchived(ctx, newIDs, name); err != nil {
+			return false, err
+		}
+	}
+	for archiveName, ids := range restoredIDs {
+		if _, err := s.auditRepo.MarkArchived(ctx, ids, archiveName); err != nil {
+			return false, err
+		}
+	}
+
+	return len(newIDs) > 0, nil
+}
+
+// ListArchives returns the names of all audit archives
+func (s *AuditRetentionService) ListArchives(ctx context.Context) ([]string, error) {
+	return s.storage.List(ctx, auditArchivePrefix)
+}
+
+// Import restores the content of archived entries from an archive. Entries whose content no
+// longer matches their hash in the chain are rejected, so a modified archive cannot be imported.
+// Restored entries are archived again by the next retention run.
+func (s *AuditRetentionService) Import(ctx context.Context, name string) (*entity.AuditImportResult, error) {
+	file, err := s.storage.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	result := &entity.AuditImportResult{}
+	decoder := json.NewDecoder(gz)
+	for {
+		var entry entity.AuditLog
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+
+		// Entri lama sebelum hash chain tidak memiliki hash untuk diperiksa
+		if entry.Sequence > 0 && entry.ComputeHash() != entry.Hash {
+			result.Rejected++
+			continue
+		}
+
+		restored, err := s.auditRepo.Restore(ctx, &entry)
+		if err != nil {
+			return result, err
+		}
+		if restored {
+			result.Restored++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
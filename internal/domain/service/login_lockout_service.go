@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// LoginLockoutService handles per-account lockout after repeated failed logins
+type LoginLockoutService struct {
+	attemptRepo repository.LoginAttemptRepository
+	auditRepo   repository.AuditLogRepository
+	policy      entity.LockoutPolicy
+}
+
+// NewLoginLockoutService creates a new lockout service, auditRepo may be nil
+func NewLoginLockoutService(attemptRepo repository.LoginAttemptRepository, auditRepo repository.AuditLogRepository, policy entity.LockoutPolicy) *LoginLockoutService {
+	return &LoginLockoutService{
+		attemptRepo: attemptRepo,
+		auditRepo:   auditRepo,
+		policy:      policy,
+	}
+}
+
+// CheckLocked returns entity.ErrAccountLocked if the email is currently locked
+func (s *LoginLockoutService) CheckLocked(ctx context.Context, email string) error {
+	attempt, err := s.attemptRepo.Get(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if attempt.IsLocked(time.Now()) {
+		return entity.ErrAccountLocked
+	}
+	return nil
+}
+
+// RegisterFailure records a failed login and locks the email once the policy threshold is reached
+func (s *LoginLockoutService) RegisterFailure(ctx context.Context, email, ipAddress, userAgent string) error {
+	now := time.Now()
+	attempt, err := s.attemptRepo.RecordFailure(ctx, email, now, now.Add(-s.policy.ResetAfter))
+	if err != nil {
+		return err
+	}
+
+	duration := s.policy.LockDuration(attempt.FailedCount)
+	if duration == 0 {
+		return nil
+	}
+
+	lockedUntil := now.Add(duration)
+	if err := s.attemptRepo.Lock(ctx, email, lockedUntil); err != nil {
+		return err
+	}
+
+	auditLog := entity.NewAuditLog("auth", attempt.Email, entity.AuditActionLockout, "", "", "", ipAddress)
+	auditLog.UserAgent = userAgent
+	changes, _ := json.Marshal(map[string]interface{}{
+		"failed_count": attempt.FailedCount,
+		"locked_until": lockedUntil,
+	})
+	auditLog.SetChanges("", "", string(changes))
+	s.record(ctx, auditLog)
+
+	return nil
+}
+
+// RegisterSuccess clears the failed attempts after a successful login
+func (s *LoginLockoutService) RegisterSuccess(ctx context.Context, email string) error {
+	return s.attemptRepo.Reset(ctx, email)
+}
+
+// Unlock clears the lock for an email, performed by an admin
+func (s *LoginLockoutService) Unlock(ctx context.Context, email, adminID, adminRole, ipAddress string) error {
+	if err := s.attemptRepo.Reset(ctx, email); err != nil {
+		return err
+	}
+
+	s.record(ctx, entity.NewAuditLog("auth", email, entity.AuditActionUnlock, adminID, "", adminRole, ipAddress))
+	return nil
+}
+
+func (s *LoginLockoutService) record(ctx context.Context, auditLog *entity.AuditLog) {
+	if s.auditRepo == nil {
+		return
+	}
+	// Kegagalan audit tidak boleh menggagalkan proses login
+	_ = s.auditRepo.Create(ctx, auditLog)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+)
+
+// memoryLoginAttempts menyimpan percobaan login di memory dan mencatat windowStart terakhir
+type memoryLoginAttempts struct {
+	attempts    map[string]*entity.LoginAttempt
+	windowStart time.Time
+}
+
+func newMemoryLoginAttempts() *memoryLoginAttempts {
+	return &memoryLoginAttempts{attempts: map[string]*entity.LoginAttempt{}}
+}
+
+func (r *memoryLoginAttempts) Get(ctx context.Context, email string) (*entity.LoginAttempt, error) {
+	if attempt, ok := r.attempts[email]; ok {
+		copied := *attempt
+		return &copied, nil
+	}
+	return &entity.LoginAttempt{Email: email}, nil
+}
+
+func (r *memoryLoginAttempts) RecordFailure(ctx context.Context, email string, at, windowStart time.Time) (*entity.LoginAttempt, error) {
+	r.windowStart = windowStart
+	attempt, ok := r.attempts[email]
+	if !ok {
+		attempt = &entity.LoginAttempt{Email: email}
+		r.attempts[email] = attempt
+	}
+	if attempt.LastFailedAt.Before(windowStart) {
+		attempt.FailedCount = 0
+	}
+	attempt.FailedCount++
+	attempt.LastFailedAt = at
+	copied := *attempt
+	return &copied, nil
+}
+
+func (r *memoryLoginAttempts) Lock(ctx context.Context, email string, until time.Time) error {
+	r.attempts[email].LockedUntil = until
+	return nil
+}
+
+func (r *memoryLoginAttempts) Reset(ctx context.Context, email string) error {
+	delete(r.attempts, email)
+	return nil
+}
+
+func newLockoutTest() (*LoginLockoutService, *memoryLoginAttempts, *memoryAuditChain) {
+	attempts := newMemoryLoginAttempts()
+	audit := &memoryAuditChain{}
+	policy := entity.LockoutPolicy{
+		MaxAttempts:  3,
+		BaseDuration: time.Minute,
+		MaxDuration:  5 * time.Minute,
+		ResetAfter:   15 * time.Minute,
+	}
+	return NewLoginLockoutService(attempts, audit, policy), attempts, audit
+}
+
+func TestLoginLockoutServiceLocksAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	service, attempts, audit := newLockoutTest()
+	email := "budi@example.com"
+
+	for i := 1; i < 3; i++ {
+		before := time.Now()
+		if err := service.RegisterFailure(ctx, email, "10.0.0.1", "curl"); err != nil {
+			t.Fatalf("RegisterFailure: %v", err)
+		}
+		// Kegagalan lebih lama dari ResetAfter tidak dihitung
+		if window := before.Add(-15 * time.Minute); attempts.windowStart.Before(window) {
+			t.Errorf("windowStart = %v, want at least %v", attempts.windowStart, window)
+		}
+		if err := service.CheckLocked(ctx, email); err != nil {
+			t.Fatalf("CheckLocked after %d failures = %v, want unlocked", i, err)
+		}
+	}
+	if len(audit.entries) != 0 {
+		t.Fatalf("audit entries = %d before the lock, want 0", len(audit.entries))
+	}
+
+	before := time.Now()
+	if err := service.RegisterFailure(ctx, email, "10.0.0.1", "curl"); err != nil {
+		t.Fatalf("RegisterFailure: %v", err)
+	}
+	if err := service.CheckLocked(ctx, email); !errors.Is(err, entity.ErrAccountLocked) {
+		t.Fatalf("CheckLocked = %v, want %v", err, entity.ErrAccountLocked)
+	}
+	lockedUntil := attempts.attempts[email].LockedUntil
+	if lockedUntil.Before(before.Add(time.Minute)) || lockedUntil.After(time.Now().Add(time.Minute)) {
+		t.Errorf("locked until %v, want one minute after the third failure", lockedUntil)
+	}
+
+	if len(audit.entries) != 1 {
+		t.Fatalf("audit entries = %d, want the lockout", len(audit.entries))
+	}
+	entry := audit.entries[0]
+	if entry.Action != entity.AuditActionLockout || entry.EntityID != email || entry.IPAddress != "10.0.0.1" || entry.UserAgent != "curl" {
+		t.Errorf("audit entry = %+v, want the lockout of %s", entry, email)
+	}
+	var changes struct {
+		FailedCount int `json:"failed_count"`
+	}
+	if err := json.Unmarshal([]byte(entry.Changes), &changes); err != nil || changes.FailedCount != 3 {
+		t.Errorf("audit changes = %s, want failed_count 3", entry.Changes)
+	}
+
+	// Kegagalan berikutnya menggandakan durasi kunci
+	before = time.Now()
+	service.RegisterFailure(ctx, email, "10.0.0.1", "curl")
+	if lockedUntil := attempts.attempts[email].LockedUntil; lockedUntil.Before(before.Add(2 * time.Minute)) {
+		t.Errorf("locked until %v after the fourth failure, want two minutes", lockedUntil)
+	}
+}
+
+func TestLoginLockoutServiceRegisterSuccessResets(t *testing.T) {
+	ctx := context.Background()
+	service, attempts, _ := newLockoutTest()
+	email := "budi@example.com"
+
+	service.RegisterFailure(ctx, email, "", "")
+	service.RegisterFailure(ctx, email, "", "")
+	if err := service.RegisterSuccess(ctx, email); err != nil {
+		t.Fatalf("RegisterSuccess: %v", err)
+	}
+	if _, ok := attempts.attempts[email]; ok {
+		t.Fatal("RegisterSuccess kept the failed attempts")
+	}
+
+	// Hitungan mulai lagi dari nol
+	service.RegisterFailure(ctx, email, "", "")
+	service.RegisterFailure(ctx, email, "", "")
+	if err := service.CheckLocked(ctx, email); err != nil {
+		t.Errorf("CheckLocked = %v, want unlocked after a successful login", err)
+	}
+}
+
+func TestLoginLockoutServiceExpiredLock(t *testing.T) {
+	ctx := context.Background()
+	service, attempts, _ := newLockoutTest()
+	email := "budi@example.com"
+
+	attempts.attempts[email] = &entity.LoginAttempt{Email: email, FailedCount: 3, LockedUntil: time.Now().Add(-time.Second)}
+	if err := service.CheckLocked(ctx, email); err != nil {
+		t.Errorf("CheckLocked after the lock expired = %v", err)
+	}
+}
+
+func TestLoginLockoutServiceUnlock(t *testing.T) {
+	ctx := context.Background()
+	service, attempts, audit := newLockoutTest()
+	email := "budi@example.com"
+
+	for i := 0; i < 3; i++ {
+		service.RegisterFailure(ctx, email, "", "")
+	}
+	if err := service.Unlock(ctx, email, "admin-1", "admin", "10.0.0.2"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := service.CheckLocked(ctx, email); err != nil {
+		t.Errorf("CheckLocked after Unlock = %v", err)
+	}
+	if _, ok := attempts.attempts[email]; ok {
+		t.Error("Unlock kept the failed attempts")
+	}
+
+	last := audit.entries[len(audit.entries)-1]
+	if last.Action != entity.AuditActionUnlock || last.UserID != "admin-1" || last.EntityID != email {
+		t.Errorf("audit entry = %+v, want the unlock by admin-1", last)
+	}
+}
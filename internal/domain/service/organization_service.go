@@ -0,0 +1,349 @@
+/*
+ * Copyright (c) 2024 Bima Kharisma Wicaksana
+ * GitHub: https://github.com/bimakw
+ *
+ * Licensed under MIT License with Attribution Requirement.
+ * See LICENSE file for details.
+ */
+
+package service
+
+import (
+	"context"
+
+	"github.com/okinn/service-presensi/internal/domain/entity"
+	"github.com/okinn/service-presensi/internal/domain/repository"
+)
+
+// maxHierarchyDepth bounds walks through the department and reporting hierarchy
+const maxHierarchyDepth = 50
+
+// OrganizationService resolves departments, teams and the reporting hierarchy into users
+type OrganizationService struct {
+	departmentRepo repository.DepartmentRepository
+	teamRepo       repository.TeamRepository
+	userRepo       repository.UserRepository
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(departmentRepo repository.DepartmentRepository, teamRepo repository.TeamRepository, userRepo repository.UserRepository) *OrganizationService {
+	return &OrganizationService{
+		departmentRepo: departmentRepo,
+		teamRepo:       teamRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// ResolveMembers returns the IDs of users that belong to the department (including
+// sub-departments), the team and report to the manager. Criteria that are set are
+// combined with AND. Returns nil when no criteria are set, meaning "no restriction".
+func (s *OrganizationService) ResolveMembers(ctx context.Context, departmentID, teamID, managerID string) ([]string, error) {
+	var sets [][]string
+
+	if departmentID != "" {
+		departments, err := s.departmentRepo.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := s.userRepo.FindIDs(ctx, repository.UserFilter{
+			DepartmentIDs: subtreeIDs(departments, []string{departmentID}),
+		})
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, ids)
+	}
+
+	if teamID != "" {
+		ids, err := s.userRepo.FindIDs(ctx, repository.UserFilter{TeamIDs: []string{teamID}})
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, ids)
+	}
+
+	if managerID != "" {
+		ids, err := s.ReportIDs(ctx, managerID)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, ids)
+	}
+
+	if len(sets) == 0 {
+		return nil, nil
+	}
+
+	result := sets[0]
+	for _, set := range sets[1:] {
+		result = intersect(result, set)
+	}
+	if result == nil {
+		// Non-nil: ada kriteria, tapi tidak ada user yang cocok
+		result = []string{}
+	}
+	return result, nil
+}
+
+// ReportIDs returns the manager and everyone reporting to them, directly or indirectly:
+// users whose manager is in the chain, and members of teams and departments the chain manages.
+func (s *OrganizationService) ReportIDs(ctx context.Context, managerID string) ([]string, error) {
+	departments, err := s.departmentRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.teamRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{managerID: true}
+	result := []string{managerID}
+	frontier := []string{managerID}
+
+	for depth := 0; len(frontier) > 0 && depth < maxHierarchyDepth; depth++ {
+		managers := make(map[string]bool, len(frontier))
+		for _, id := range frontier {
+			managers[id] = true
+		}
+
+		var managedDepartments, managedTeams []string
+		for _, d := range departments {
+			if managers[d.ManagerID] {
+				managedDepartments = append(managedDepartments, d.ID)
+			}
+		}
+		for _, t := range teams {
+			if managers[t.ManagerID] {
+				managedTeams = append(managedTeams, t.ID)
+			}
+		}
+
+		ids, err := s.userRepo.FindIDs(ctx, repository.UserFilter{
+			ManagerIDs:    frontier,
+			DepartmentIDs: subtreeIDs(departments, managedDepartments),
+			TeamIDs:       managedTeams,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		frontier = frontier[:0]
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				result = append(result, id)
+				frontier = append(frontier, id)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// VisibleUserIDs returns the users whose records a user with the given data scope may see.
+// Returns nil for DataScopeAll, meaning "no restriction".
+func (s *OrganizationService) VisibleUserIDs(ctx context.Context, userID string, scope entity.DataScope) ([]string, error) {
+	switch scope {
+	case entity.DataScopeAll:
+		return nil, nil
+	case entity.DataScopeTeam:
+		return s.ReportIDs(ctx, userID)
+	default:
+		return []string{userID}, nil
+	}
+}
+
+// RestrictUserIDs combines two user ID restrictions, where nil means "no restriction"
+func RestrictUserIDs(a, b []string) []string {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	result := intersect(a, b)
+	if result == nil {
+		result = []string{}
+	}
+	return result
+}
+
+// ValidateManager ensures that making managerID the manager of userID doesn't create a cycle
+func (s *OrganizationService) ValidateManager(ctx context.Context, userID, managerID string) error {
+	if managerID == "" {
+		return nil
+	}
+	if managerID == userID {
+		return entity.ErrSelfManager
+	}
+
+	current := managerID
+	for depth := 0; current != "" && depth < maxHierarchyDepth; depth++ {
+		manager, err := s.userRepo.GetByID(ctx, current)
+		if err != nil {
+			return err
+		}
+		if manager.ManagerID == userID {
+			return entity.ErrManagerCycle
+		}
+		current = manager.ManagerID
+	}
+
+	return nil
+}
+
+// ValidateParent ensures that making parentID the parent of departmentID doesn't create a cycle
+func (s *OrganizationService) ValidateParent(ctx context.Context, departmentID, parentID string) error {
+	if parentID == "" || departmentID == "" {
+		return nil
+	}
+
+	departments, err := s.departmentRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range subtreeIDs(departments, []string{departmentID}) {
+		if id == parentID {
+			return entity.ErrDepartmentCycle
+		}
+	}
+
+	return nil
+}
+
+// BuildTree returns the department hierarchy with its teams and members
+func (s *OrganizationService) BuildTree(ctx context.Context) ([]entity.OrgTreeDepartment, error) {
+	departments, err := s.departmentRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.teamRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	departmentIDs := make([]string, len(departments))
+	for i, d := range departments {
+		departmentIDs[i] = d.ID
+	}
+
+	var users []entity.User
+	if len(departmentIDs) > 0 {
+		users, err = s.userRepo.Find(ctx, repository.UserFilter{DepartmentIDs: departmentIDs})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	teamMembers := make(map[string][]entity.OrgTreeMember)
+	departmentMembers := make(map[string][]entity.OrgTreeMember)
+	for _, u := range users {
+		member := entity.OrgTreeMember{ID: u.ID, Nama: u.Nama, Email: u.Email, Role: string(u.Role)}
+		if u.TeamID != "" {
+			teamMembers[u.TeamID] = append(teamMembers[u.TeamID], member)
+		} else {
+			departmentMembers[u.DepartmentID] = append(departmentMembers[u.DepartmentID], member)
+		}
+	}
+
+	departmentTeams := make(map[string][]entity.OrgTreeTeam)
+	for _, t := range teams {
+		members := teamMembers[t.ID]
+		if members == nil {
+			members = []entity.OrgTreeMember{}
+		}
+		departmentTeams[t.DepartmentID] = append(departmentTeams[t.DepartmentID], entity.OrgTreeTeam{
+			ID:        t.ID,
+			Name:      t.Name,
+			ManagerID: t.ManagerID,
+			Members:   members,
+		})
+	}
+
+	children := make(map[string][]entity.Department)
+	known := make(map[string]bool, len(departments))
+	for _, d := range departments {
+		known[d.ID] = true
+	}
+	for _, d := range departments {
+		parentID := d.ParentID
+		if !known[parentID] {
+			parentID = "" // Parent yang sudah dihapus dianggap root
+		}
+		children[parentID] = append(children[parentID], d)
+	}
+
+	var build func(parentID string, depth int) []entity.OrgTreeDepartment
+	build = func(parentID string, depth int) []entity.OrgTreeDepartment {
+		nodes := make([]entity.OrgTreeDepartment, 0, len(children[parentID]))
+		if depth >= maxHierarchyDepth {
+			return nodes
+		}
+		for _, d := range children[parentID] {
+			node := entity.OrgTreeDepartment{
+				ID:          d.ID,
+				Name:        d.Name,
+				ManagerID:   d.ManagerID,
+				Teams:       departmentTeams[d.ID],
+				Members:     departmentMembers[d.ID],
+				Departments: build(d.ID, depth+1),
+			}
+			if node.Teams == nil {
+				node.Teams = []entity.OrgTreeTeam{}
+			}
+			if node.Members == nil {
+				node.Members = []entity.OrgTreeMember{}
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build("", 0), nil
+}
+
+// subtreeIDs returns the given departments and all of their descendants
+func subtreeIDs(departments []entity.Department, rootIDs []string) []string {
+	if len(rootIDs) == 0 {
+		return nil
+	}
+
+	children := make(map[string][]string)
+	for _, d := range departments {
+		if d.ParentID != "" {
+			children[d.ParentID] = append(children[d.ParentID], d.ID)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	queue := append([]string(nil), rootIDs...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+		queue = append(queue, children[id]...)
+	}
+
+	return result
+}
+
+func intersect(a, b []string) []string {
+	set := make(map[string]bool, len(b))
+	for _, id := range b {
+		set[id] = true
+	}
+
+	var result []string
+	for _, id := range a {
+		if set[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
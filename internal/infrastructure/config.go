@@ -18,6 +18,91 @@ type Config struct {
 	JWTExpireMinutes    int
 	GeofenceEnabled     bool
 	DefaultRadiusMeters float64
+	AuditEnabled        bool
+
+	// Audit hash chain checkpoints
+	AuditSigningKey        string
+	AuditCheckpointMinutes int // 0 disables periodic checkpoints
+
+	// Audit retention and archival
+	AuditRetentionDays          int    // 0 keeps entries forever
+	AuditRetentionByType        string // "entity_type=days,entity_type=days"
+	AuditArchiveDir             string
+	AuditRetentionIntervalHours int // 0 disables the periodic retention run
+
+	// Audit sinks, each one is disabled when its target is empty
+	AuditSyslogAddr         string // "udp://host:514" or "tcp://host:601"
+	AuditSinkFile           string
+	AuditWebhookURL         string
+	AuditWebhookSecret      string
+	AuditWebhookMaxAttempts int
+	AuditSinkBufferSize     int
+
+	// Audit write queue
+	AuditQueueSize        int
+	AuditQueueWorkers     int
+	AuditBatchSize        int
+	AuditFlushIntervalMs  int
+	AuditWriteMaxAttempts int
+	AuditSpillFile        string // empty drops entries that cannot be written
+
+	// Analytics
+	AnalyticsTimezone string  // IANA name, day, week, month and year boundaries are taken in this zone
+	WorkStartTime     string  // "HH:MM" in AnalyticsTimezone, later check-ins are late
+	WorkHoursPerDay   float64 // time worked beyond it is overtime
+	WorkDays          string  // "mon,tue,wed,thu,fri", users can have their own
+	Holidays          string  // "YYYY-MM-DD,YYYY-MM-DD", days off for everyone
+	AnalyticsRollups  bool    // read days before today from the daily rollups, rebuilt on startup when not current
+
+	// Analytics cache
+	AnalyticsCache               string // "memory", "redis" or empty to disable
+	AnalyticsCacheSize           int    // entries kept by the memory cache
+	AnalyticsCacheRedisURL       string // redis://[:password@]host:port[/db]
+	AnalyticsCacheTTLSeconds     int    // results that include today or later
+	AnalyticsCachePastTTLSeconds int    // results that end before today
+
+	// Password policy
+	PasswordMinLength            int
+	PasswordRequireUpper         bool
+	PasswordRequireLower         bool
+	PasswordRequireDigit         bool
+	PasswordRequireSymbol        bool
+	PasswordDisallowPersonalInfo bool
+	PasswordHistorySize          int
+	BreachedPasswordsFile        string
+
+	// Account lockout
+	LoginLockoutEnabled      bool
+	LoginLockoutMaxAttempts  int
+	LoginLockoutBaseMinutes  int
+	LoginLockoutMaxMinutes   int
+	LoginLockoutResetMinutes int
+
+	// Two-factor authentication
+	MFAIssuer           string
+	MFARequiredForAdmin bool
+	MFAChallengeMinutes int
+
+	// OpenID Connect single sign-on
+	OIDCEnabled       bool
+	OIDCIssuerURL     string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCScopes        string
+	OIDCGroupsClaim   string
+	OIDCGroupRoles    string // "group=role,group=role", first match wins
+	OIDCAutoProvision bool
+	OIDCSyncRole      bool
+
+	// SMTP for invitation emails, empty host disables invitations
+	SMTPHost              string
+	SMTPPort              int
+	SMTPUsername          string
+	SMTPPassword          string
+	SMTPFrom              string
+	InvitationURL         string
+	InvitationExpireHours int
 }
 
 func LoadConfig() *Config {
@@ -29,6 +114,80 @@ func LoadConfig() *Config {
 		JWTExpireMinutes:    getEnvAsInt("JWT_EXPIRE_MINUTES", 60*24), // 24 hours default
 		GeofenceEnabled:     getEnvAsBool("GEOFENCE_ENABLED", false),
 		DefaultRadiusMeters: getEnvAsFloat("DEFAULT_RADIUS_METERS", 100), // 100 meters default
+		AuditEnabled:        getEnvAsBool("AUDIT_ENABLED", true),
+
+		AuditSigningKey:        getEnv("AUDIT_SIGNING_KEY", getEnv("JWT_SECRET", "your-secret-key-change-in-production")),
+		AuditCheckpointMinutes: getEnvAsInt("AUDIT_CHECKPOINT_MINUTES", 60),
+
+		AuditRetentionDays:          getEnvAsInt("AUDIT_RETENTION_DAYS", 90),
+		AuditRetentionByType:        getEnv("AUDIT_RETENTION_BY_TYPE", ""),
+		AuditArchiveDir:             getEnv("AUDIT_ARCHIVE_DIR", "./data/audit-archive"),
+		AuditRetentionIntervalHours: getEnvAsInt("AUDIT_RETENTION_INTERVAL_HOURS", 24),
+
+		AuditSyslogAddr:         getEnv("AUDIT_SYSLOG_ADDR", ""),
+		AuditSinkFile:           getEnv("AUDIT_SINK_FILE", ""),
+		AuditWebhookURL:         getEnv("AUDIT_WEBHOOK_URL", ""),
+		AuditWebhookSecret:      getEnv("AUDIT_WEBHOOK_SECRET", ""),
+		AuditWebhookMaxAttempts: getEnvAsInt("AUDIT_WEBHOOK_MAX_ATTEMPTS", 5),
+		AuditSinkBufferSize:     getEnvAsInt("AUDIT_SINK_BUFFER_SIZE", 1000),
+
+		AuditQueueSize:        getEnvAsInt("AUDIT_QUEUE_SIZE", 10000),
+		AuditQueueWorkers:     getEnvAsInt("AUDIT_QUEUE_WORKERS", 2),
+		AuditBatchSize:        getEnvAsInt("AUDIT_BATCH_SIZE", 100),
+		AuditFlushIntervalMs:  getEnvAsInt("AUDIT_FLUSH_INTERVAL_MS", 1000),
+		AuditWriteMaxAttempts: getEnvAsInt("AUDIT_WRITE_MAX_ATTEMPTS", 5),
+		AuditSpillFile:        getEnv("AUDIT_SPILL_FILE", "./data/audit-spill.ndjson"),
+
+		AnalyticsTimezone: getEnv("ANALYTICS_TIMEZONE", "UTC"),
+		WorkStartTime:     getEnv("WORK_START_TIME", "08:00"),
+		WorkHoursPerDay:   getEnvAsFloat("WORK_HOURS_PER_DAY", 8),
+		WorkDays:          getEnv("WORK_DAYS", "mon,tue,wed,thu,fri"),
+		Holidays:          getEnv("HOLIDAYS", ""),
+		AnalyticsRollups:  getEnvAsBool("ANALYTICS_ROLLUPS", true),
+
+		AnalyticsCache:               getEnv("ANALYTICS_CACHE", "memory"),
+		AnalyticsCacheSize:           getEnvAsInt("ANALYTICS_CACHE_SIZE", 1000),
+		AnalyticsCacheRedisURL:       getEnv("ANALYTICS_CACHE_REDIS_URL", "redis://localhost:6379/0"),
+		AnalyticsCacheTTLSeconds:     getEnvAsInt("ANALYTICS_CACHE_TTL_SECONDS", 60),
+		AnalyticsCachePastTTLSeconds: getEnvAsInt("ANALYTICS_CACHE_PAST_TTL_SECONDS", 3600),
+
+		PasswordMinLength:            getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:         getEnvAsBool("PASSWORD_REQUIRE_UPPER", false),
+		PasswordRequireLower:         getEnvAsBool("PASSWORD_REQUIRE_LOWER", false),
+		PasswordRequireDigit:         getEnvAsBool("PASSWORD_REQUIRE_DIGIT", false),
+		PasswordRequireSymbol:        getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordDisallowPersonalInfo: getEnvAsBool("PASSWORD_DISALLOW_PERSONAL_INFO", true),
+		PasswordHistorySize:          getEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+		BreachedPasswordsFile:        getEnv("BREACHED_PASSWORDS_FILE", ""), // empty disables the check
+
+		LoginLockoutEnabled:      getEnvAsBool("LOGIN_LOCKOUT_ENABLED", true),
+		LoginLockoutMaxAttempts:  getEnvAsInt("LOGIN_LOCKOUT_MAX_ATTEMPTS", 5),
+		LoginLockoutBaseMinutes:  getEnvAsInt("LOGIN_LOCKOUT_BASE_MINUTES", 1),
+		LoginLockoutMaxMinutes:   getEnvAsInt("LOGIN_LOCKOUT_MAX_MINUTES", 60),
+		LoginLockoutResetMinutes: getEnvAsInt("LOGIN_LOCKOUT_RESET_MINUTES", 60*24), // failures older than 24 hours are forgotten
+
+		MFAIssuer:           getEnv("MFA_ISSUER", "Service Presensi"),
+		MFARequiredForAdmin: getEnvAsBool("MFA_REQUIRED_FOR_ADMIN", false),
+		MFAChallengeMinutes: getEnvAsInt("MFA_CHALLENGE_MINUTES", 5),
+
+		OIDCEnabled:       getEnvAsBool("OIDC_ENABLED", false),
+		OIDCIssuerURL:     getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:      getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:   getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/auth/oidc/callback"),
+		OIDCScopes:        getEnv("OIDC_SCOPES", "openid email profile"),
+		OIDCGroupsClaim:   getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCGroupRoles:    getEnv("OIDC_GROUP_ROLES", ""),
+		OIDCAutoProvision: getEnvAsBool("OIDC_AUTO_PROVISION", true),
+		OIDCSyncRole:      getEnvAsBool("OIDC_SYNC_ROLE", true),
+
+		SMTPHost:              getEnv("SMTP_HOST", ""),
+		SMTPPort:              getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername:          getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:          getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:              getEnv("SMTP_FROM", "noreply@localhost"),
+		InvitationURL:         getEnv("INVITATION_URL", "http://localhost:3000/accept-invitation"),
+		InvitationExpireHours: getEnvAsInt("INVITATION_EXPIRE_HOURS", 72),
 	}
 }
 
@@ -1,8 +1,11 @@
 package httputil
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 type Response struct {
@@ -48,3 +51,44 @@ func Error(w http.ResponseWriter, status int, message string) {
 		Message: message,
 	})
 }
+
+// SuccessWithETag writes a 200 Success response with an ETag over its body, or 304 Not Modified
+// without a body when the request's If-None-Match already holds that ETag
+func SuccessWithETag(w http.ResponseWriter, r *http.Request, message string, data interface{}) {
+	body, err := json.Marshal(Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "Gagal membuat response")
+		return
+	}
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	// Klien harus selalu revalidasi, data bisa berubah kapan saja
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// etagMatches compares with the weak comparison RFC 9110 requires for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
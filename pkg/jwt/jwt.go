@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -15,8 +19,11 @@ var (
 type TokenType string
 
 const (
-	AccessToken  TokenType = "access"
-	RefreshToken TokenType = "refresh"
+	AccessToken       TokenType = "access"
+	RefreshToken      TokenType = "refresh"
+	MFAChallengeToken TokenType = "mfa_challenge"
+	OIDCStateToken    TokenType = "oidc_state"
+	InvitationToken   TokenType = "invitation"
 )
 
 type Claims struct {
@@ -50,12 +57,40 @@ func NewJWTManagerWithRefresh(secretKey string, accessTokenDuration, refreshToke
 }
 
 func (m *JWTManager) GenerateToken(userID, email, role string) (string, error) {
+	return m.generate(userID, email, role, AccessToken, m.accessTokenDuration)
+}
+
+// GenerateRefreshToken membuat token berumur panjang yang hanya bisa ditukar dengan access token baru
+func (m *JWTManager) GenerateRefreshToken(userID, email, role string) (string, error) {
+	return m.generate(userID, email, role, RefreshToken, m.refreshTokenDuration)
+}
+
+// GenerateMFAChallengeToken membuat token berumur pendek yang hanya bisa ditukar
+// dengan access token setelah verifikasi kode MFA
+func (m *JWTManager) GenerateMFAChallengeToken(userID, email, role string, duration time.Duration) (string, error) {
+	return m.generate(userID, email, role, MFAChallengeToken, duration)
+}
+
+// GenerateInvitationToken membuat token untuk link undangan user hasil import
+func (m *JWTManager) GenerateInvitationToken(userID, email string, duration time.Duration) (string, error) {
+	return m.generate(userID, email, "", InvitationToken, duration)
+}
+
+func (m *JWTManager) generate(userID, email, role string, tokenType TokenType, duration time.Duration) (string, error) {
+	// ID unik (jti) agar token bisa dicabut satu per satu, misalnya refresh token saat logout
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.accessTokenDuration)),
+			ID:        hex.EncodeToString(id),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
@@ -87,3 +122,77 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// ValidateTokenType memvalidasi token dan memastikan jenis token sesuai
+func (m *JWTManager) ValidateTokenType(tokenString string, tokenType TokenType) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != tokenType {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// OIDCStateClaims menyimpan state, nonce, dan PKCE verifier selama login OpenID Connect
+// sehingga callback tidak membutuhkan penyimpanan di server
+type OIDCStateClaims struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	TokenType    TokenType `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// oidcStateKey diturunkan dari secret key sehingga token state tidak pernah lolos ValidateToken
+// dan token lain tidak pernah lolos ValidateOIDCStateToken
+func (m *JWTManager) oidcStateKey() []byte {
+	mac := hmac.New(sha256.New, m.secretKey)
+	mac.Write([]byte(OIDCStateToken))
+	return mac.Sum(nil)
+}
+
+// GenerateOIDCStateToken membuat token bertanda tangan untuk cookie flow OpenID Connect
+func (m *JWTManager) GenerateOIDCStateToken(state, nonce, codeVerifier string, duration time.Duration) (string, error) {
+	claims := &OIDCStateClaims{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		TokenType:    OIDCStateToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   string(OIDCStateToken),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(m.oidcStateKey())
+}
+
+// ValidateOIDCStateToken memvalidasi token flow OpenID Connect
+func (m *JWTManager) ValidateOIDCStateToken(tokenString string) (*OIDCStateClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &OIDCStateClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return m.oidcStateKey(), nil
+	})
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*OIDCStateClaims)
+	if !ok || !token.Valid || claims.TokenType != OIDCStateToken || claims.Subject != string(OIDCStateToken) {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
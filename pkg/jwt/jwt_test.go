@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestOIDCStateToken(t *testing.T) {
+	m := NewJWTManager("secret", time.Hour)
+
+	stateToken, err := m.GenerateOIDCStateToken("state", "nonce", "verifier", time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateOIDCStateToken: %v", err)
+	}
+
+	claims, err := m.ValidateOIDCStateToken(stateToken)
+	if err != nil {
+		t.Fatalf("ValidateOIDCStateToken: %v", err)
+	}
+	if claims.State != "state" || claims.Nonce != "nonce" || claims.CodeVerifier != "verifier" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	// Token state tidak boleh dipakai sebagai token lain
+	if _, err := m.ValidateToken(stateToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken(state token) err = %v, want ErrInvalidToken", err)
+	}
+	for _, tokenType := range []TokenType{AccessToken, RefreshToken, MFAChallengeToken, InvitationToken} {
+		if _, err := m.ValidateTokenType(stateToken, tokenType); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("ValidateTokenType(state token, %s) err = %v, want ErrInvalidToken", tokenType, err)
+		}
+	}
+}
+
+func TestOIDCStateTokenRejectsOtherTokens(t *testing.T) {
+	m := NewJWTManager("secret", time.Hour)
+
+	accessToken, err := m.GenerateToken("user-1", "budi@example.com", "employee")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	// Klaim state yang ditandatangani dengan secret utama, bukan key state
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &OIDCStateClaims{
+		State:        "state",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+		TokenType:    OIDCStateToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   string(OIDCStateToken),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	forgedToken, err := forged.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign forged token: %v", err)
+	}
+
+	expired, err := m.GenerateOIDCStateToken("state", "nonce", "verifier", -time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateOIDCStateToken: %v", err)
+	}
+	other, err := NewJWTManager("other-secret", time.Hour).GenerateOIDCStateToken("state", "nonce", "verifier", time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateOIDCStateToken: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"access token", accessToken, ErrInvalidToken},
+		{"signed with the access secret", forgedToken, ErrInvalidToken},
+		{"signed with another secret", other, ErrInvalidToken},
+		{"expired", expired, ErrExpiredToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.ValidateOIDCStateToken(tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratedTokensHaveUniqueIDs(t *testing.T) {
+	m := NewJWTManager("secret", time.Hour)
+
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		token, err := m.GenerateRefreshToken("user-1", "budi@example.com", "employee")
+		if err != nil {
+			t.Fatalf("GenerateRefreshToken: %v", err)
+		}
+		claims, err := m.ValidateTokenType(token, RefreshToken)
+		if err != nil {
+			t.Fatalf("ValidateTokenType: %v", err)
+		}
+		if claims.ID == "" || seen[claims.ID] {
+			t.Fatalf("token ID = %q, want a new ID for every token", claims.ID)
+		}
+		seen[claims.ID] = true
+	}
+}
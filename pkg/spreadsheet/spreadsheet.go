@@ -0,0 +1,245 @@
+// Package spreadsheet reads tabular data from CSV and XLSX files as rows of strings.
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("format file tidak didukung, gunakan .csv atau .xlsx")
+	ErrInvalidXLSX       = errors.New("file xlsx tidak valid")
+	ErrTooManyRows       = errors.New("jumlah baris melebihi batas")
+)
+
+// maxXMLSize membatasi ukuran XML yang di-decompress dari file xlsx (zip bomb)
+const maxXMLSize = 64 << 20
+
+// Read reads the file based on its extension. For XLSX only the first worksheet is read.
+// maxRows limits the number of rows including the header, 0 means no limit.
+func Read(filename string, r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(io.NewSectionReader(r, 0, size), maxRows)
+	case ".xlsx":
+		return ReadXLSX(r, size, maxRows)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// ReadCSV reads comma or semicolon separated values, detected from the first line
+func ReadCSV(r io.Reader, maxRows int) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimPrefix(string(data), "\ufeff") // Excel menulis BOM di awal file CSV
+
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	firstLine, _, _ := strings.Cut(content, "\n")
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if maxRows > 0 && len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, record)
+	}
+
+	return rows, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	for _, run := range t.Runs {
+		sb.WriteString(run.Text)
+	}
+	return sb.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX reads the first worksheet of an Office Open XML workbook
+func ReadXLSX(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidXLSX
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetFile, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, ErrInvalidXLSX
+	}
+	var sheet xlsxWorksheet
+	if err := decodeXML(sheetFile, &sheet); err != nil {
+		return nil, err
+	}
+
+	if maxRows > 0 && len(sheet.Rows) > maxRows {
+		return nil, ErrTooManyRows
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var values []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				col = columnIndex(cell.Ref)
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+
+			var value string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(sharedStrings.Items) {
+					return nil, ErrInvalidXLSX
+				}
+				value = sharedStrings.Items[idx].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			default:
+				value = cell.Value
+			}
+			values = append(values, value)
+		}
+		rows = append(rows, values)
+	}
+
+	return rows, nil
+}
+
+// firstSheetPath resolves the first sheet of the workbook, falling back to the default location
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	wb, okWorkbook := files["xl/workbook.xml"]
+	rf, okRels := files["xl/_rels/workbook.xml.rels"]
+	if !okWorkbook || !okRels || decodeXML(wb, &workbook) != nil || decodeXML(rf, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return ErrInvalidXLSX
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXMLSize)).Decode(v); err != nil {
+		return ErrInvalidXLSX
+	}
+	return nil
+}
+
+// columnIndex converts a cell reference such as "C5" to a zero-based column index
+func columnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+	}
+	return col - 1
+}
+
+// ParseDate parses a YYYY-MM-DD date or an Excel serial date number, as XLSX stores dates as numbers
+func ParseDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil || serial < 1 {
+		return time.Time{}, errors.New("format tanggal harus YYYY-MM-DD")
+	}
+	// Excel menghitung hari sejak 1899-12-30 (termasuk bug tahun kabisat 1900)
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return base.AddDate(0, 0, int(serial)), nil
+}
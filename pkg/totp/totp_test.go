@@ -0,0 +1,88 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret adalah secret SHA1 dari RFC 6238 Appendix B, "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateCodeRFC6238(t *testing.T) {
+	// Kode 8 digit dari RFC, authenticator app memakai 6 digit terakhir
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		step := Step(time.Unix(tt.unix, 0))
+		got, err := GenerateCode(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("GenerateCode(%d): %v", tt.unix, err)
+		}
+		if want := tt.code[len(tt.code)-Digits:]; got != want {
+			t.Errorf("GenerateCode at %d = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestGenerateCodeNormalizesSecret(t *testing.T) {
+	want, _ := GenerateCode(rfcSecret, 1)
+	spaced := strings.ToLower(rfcSecret[:8] + " " + rfcSecret[8:])
+	if got, err := GenerateCode(spaced, 1); err != nil || got != want {
+		t.Errorf("GenerateCode(lowercase with spaces) = %s, %v, want %s", got, err, want)
+	}
+	if _, err := GenerateCode("not base32!", 1); err != ErrInvalidSecret {
+		t.Errorf("GenerateCode(invalid) err = %v, want %v", err, ErrInvalidSecret)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	code := func(step int64) string {
+		c, err := GenerateCode(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("GenerateCode: %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", code(current), current, true},
+		{"previous step within skew", code(current - 1), current - 1, true},
+		{"next step within skew", code(current + 1), current + 1, true},
+		{"outside skew", code(current - 2), 0, false},
+		{"surrounding spaces", " " + code(current) + " ", current, true},
+		{"too short", code(current)[:Digits-1], 0, false},
+		{"wrong code", "000000", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, 1)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := Validate("not base32!", "123456", now, 1); ok {
+		t.Error("Validate accepted an invalid secret")
+	}
+}
@@ -3,13 +3,20 @@ package validator
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
 var validate *validator.Validate
 
+var (
+	phonePattern          = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+	employeeNumberPattern = regexp.MustCompile(`^[A-Za-z0-9./-]{3,30}$`)
+)
+
 func init() {
 	validate = validator.New()
 
@@ -24,7 +31,13 @@ func init() {
 
 	// Register custom validations
 	validate.RegisterValidation("status_presensi", validateStatusPresensi)
-	validate.RegisterValidation("role", validateRole)
+	validate.RegisterValidation("api_key_scope", validateAPIKeyScope)
+	validate.RegisterValidation("permission", validatePermission)
+	validate.RegisterValidation("data_scope", validateDataScope)
+	validate.RegisterValidation("phone", validatePhone)
+	validate.RegisterValidation("employee_number", validateEmployeeNumber)
+	validate.RegisterValidation("employment_type", validateEmploymentType)
+	validate.RegisterValidation("date", validateDate)
 }
 
 // Custom validation untuk status presensi
@@ -39,21 +52,82 @@ func validateStatusPresensi(fl validator.FieldLevel) bool {
 	return false
 }
 
-// Custom validation untuk role
-func validateRole(fl validator.FieldLevel) bool {
-	role := fl.Field().String()
-	if role == "" {
-		return true // empty role is allowed (will default to employee)
+// Custom validation untuk scope API key
+func validateAPIKeyScope(fl validator.FieldLevel) bool {
+	scope := fl.Field().String()
+	validScopes := []string{"presensi:read", "analytics:read"}
+	for _, s := range validScopes {
+		if scope == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Custom validation untuk permission role
+func validatePermission(fl validator.FieldLevel) bool {
+	permission := fl.Field().String()
+	validPermissions := []string{"presensi.approve", "presensi.delete", "location.manage", "audit.read", "user.manage", "role.manage", "apikey.manage", "org.manage"}
+	for _, p := range validPermissions {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Custom validation untuk data scope role
+func validateDataScope(fl validator.FieldLevel) bool {
+	scope := fl.Field().String()
+	if scope == "" {
+		return true // empty scope is allowed (will default to own)
 	}
-	validRoles := []string{"admin", "employee"}
-	for _, r := range validRoles {
-		if role == r {
+	validScopes := []string{"own", "team", "all"}
+	for _, s := range validScopes {
+		if scope == s {
 			return true
 		}
 	}
 	return false
 }
 
+// Custom validation untuk nomor telepon, kosong berarti menghapus nomor
+func validatePhone(fl validator.FieldLevel) bool {
+	phone := fl.Field().String()
+	return phone == "" || phonePattern.MatchString(phone)
+}
+
+// Custom validation untuk NIK (nomor induk karyawan), kosong berarti menghapus NIK
+func validateEmployeeNumber(fl validator.FieldLevel) bool {
+	number := fl.Field().String()
+	return number == "" || employeeNumberPattern.MatchString(number)
+}
+
+// Custom validation untuk tipe kepegawaian
+func validateEmploymentType(fl validator.FieldLevel) bool {
+	employmentType := fl.Field().String()
+	if employmentType == "" {
+		return true
+	}
+	validTypes := []string{"full_time", "part_time", "contract", "intern"}
+	for _, t := range validTypes {
+		if employmentType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Custom validation untuk tanggal dengan format YYYY-MM-DD
+func validateDate(fl validator.FieldLevel) bool {
+	date := fl.Field().String()
+	if date == "" {
+		return true
+	}
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
 // ValidationError represents a single validation error
 type ValidationError struct {
 	Field   string `json:"field"`
@@ -93,7 +167,21 @@ func Validate(s interface{}) error {
 		case "status_presensi":
 			message = "status harus salah satu dari: hadir, terlambat, izin, sakit, alpha"
 		case "role":
-			message = "role harus salah satu dari: admin, employee"
+			message = "role harus salah satu dari: admin, manager, employee"
+		case "api_key_scope":
+			message = "scope harus salah satu dari: presensi:read, analytics:read"
+		case "permission":
+			message = "permission harus salah satu dari: presensi.approve, presensi.delete, location.manage, audit.read, user.manage, role.manage, apikey.manage, org.manage"
+		case "data_scope":
+			message = "data scope harus salah satu dari: own, team, all"
+		case "phone":
+			message = "nomor telepon harus 8-15 digit, boleh diawali +"
+		case "employee_number":
+			message = "NIK hanya boleh huruf, angka, '.', '/' atau '-' (3-30 karakter)"
+		case "employment_type":
+			message = "tipe kepegawaian harus salah satu dari: full_time, part_time, contract, intern"
+		case "date":
+			message = "format tanggal harus YYYY-MM-DD"
 		case "latitude":
 			message = "latitude harus antara -90 dan 90"
 		case "longitude":